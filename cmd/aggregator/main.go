@@ -0,0 +1,138 @@
+package main
+
+import (
+	"banners-rotator/internal/aggregator"
+	"banners-rotator/internal/config"
+	"banners-rotator/internal/logger"
+	"banners-rotator/internal/rmq"
+	sqlstorage "banners-rotator/internal/storage/sql"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+var configFile string
+
+func init() {
+	flag.StringVar(&configFile, "config", "configs/config.dev.yaml", "Path to configuration file")
+}
+
+func main() {
+	flag.Parse()
+
+	cfg, err := config.NewAppConfig(configFile)
+	if err != nil {
+		fmt.Printf("Critical app error: %v", err)
+		os.Exit(1)
+	}
+
+	logg, err := logger.NewLogger(cfg.Logger.Level, []string{"stdout"})
+	if err != nil {
+		fmt.Printf("Critical app error: %v", err)
+		os.Exit(1)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(),
+		syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+
+	logg.Info("getting storage...")
+	s, err := getStorage(ctx, cfg)
+	if err != nil {
+		logg.Error(err.Error())
+		cancel()
+		os.Exit(1)
+	}
+	defer s.Close()
+
+	a := aggregator.NewAggregator(s, logg)
+	go runPurge(ctx, cfg, a, logg)
+
+	logg.Info("aggregator is running...")
+	runConsumer(ctx, cfg, a, logg)
+
+	defer cancel()
+}
+
+// runConsumer consumes the events until the context is done. A failed
+// consumer is restarted on a new connection after aggregator.reconnectDelay,
+// the broker redelivers the messages it has not acknowledged.
+func runConsumer(ctx context.Context, cfg *config.AppConfig, a *aggregator.Aggregator, logg *logger.Logger) {
+	for {
+		err := consume(ctx, cfg, a)
+		if err == nil {
+			return
+		}
+		logg.Error("failed to consume events: " + err.Error())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(cfg.Aggregator.ReconnectDelay):
+		}
+	}
+}
+
+// consume handles the events until the context is done or the connection
+// fails.
+func consume(ctx context.Context, cfg *config.AppConfig, a *aggregator.Aggregator) error {
+	conn, err := amqp.Dial(cfg.Rmq.Uri)
+	if err != nil {
+		return fmt.Errorf("consume -> %w", err)
+	}
+	defer conn.Close()
+
+	c := rmq.NewRMQConsumer(cfg.Rmq.Name, cfg.Rmq.Prefetch, conn)
+	if err = c.Connect(); err != nil {
+		return fmt.Errorf("consume -> %w", err)
+	}
+	defer c.Close()
+
+	if err = c.Consume(ctx, a.Handle); err != nil {
+		return fmt.Errorf("consume -> %w", err)
+	}
+
+	return nil
+}
+
+// runPurge deletes the IDs of the messages processed before
+// aggregator.processedRetention every aggregator.purgeInterval.
+func runPurge(ctx context.Context, cfg *config.AppConfig, a *aggregator.Aggregator, logg *logger.Logger) {
+	ticker := time.NewTicker(cfg.Aggregator.PurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := a.Purge(time.Now().Add(-cfg.Aggregator.ProcessedRetention)); err != nil {
+				logg.Error(err.Error())
+			}
+		}
+	}
+}
+
+func getStorage(ctx context.Context, cfg *config.AppConfig) (*sqlstorage.Storage, error) {
+	storage, err := sqlstorage.NewStorage(ctx, cfg.Storage.ConnectionString, sqlstorage.WithPool(sqlstorage.PoolConfig{
+		MaxOpenConns:    cfg.Storage.Pool.MaxOpenConns,
+		MaxIdleConns:    cfg.Storage.Pool.MaxIdleConns,
+		ConnMaxLifetime: cfg.Storage.Pool.ConnMaxLifetime,
+		ConnMaxIdleTime: cfg.Storage.Pool.ConnMaxIdleTime,
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("get storage -> %w", err)
+	}
+
+	err = storage.Connect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get storage -> %w", err)
+	}
+
+	return storage, nil
+}
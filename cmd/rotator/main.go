@@ -2,26 +2,60 @@ package main
 
 import (
 	"banners-rotator/internal/bandit"
+	"banners-rotator/internal/bandit/linucb"
 	"banners-rotator/internal/config"
 	"banners-rotator/internal/logger"
+	"banners-rotator/internal/metrics"
+	"banners-rotator/internal/publisher"
 	"banners-rotator/internal/rmq"
 	"banners-rotator/internal/rotator"
 	internalgrpc "banners-rotator/internal/server/grpc"
+	memorystorage "banners-rotator/internal/storage/memory"
 	sqlstorage "banners-rotator/internal/storage/sql"
 	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/streadway/amqp"
+	"google.golang.org/grpc"
 )
 
-var configFile string
+const (
+	metricsReadHeaderTimeout = 5 * time.Second
+	metricsShutdownTimeout   = 5 * time.Second
+)
+
+var (
+	configFile string
+
+	ErrUnknownStorage   = errors.New("unknown storage type")
+	ErrUnknownPublisher = errors.New("unknown publisher type")
+)
+
+type appStorage interface {
+	rotator.Storage
+	io.Closer
+}
+
+type eventPublisher interface {
+	rotator.EventPublisher
+	io.Closer
+}
 
 func init() {
 	flag.StringVar(&configFile, "config", "configs/config.dev.yaml", "Path to configuration file")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [migrate up|down|status | report [report flags] | replay [replay flags]]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
 }
 
 func main() {
@@ -42,32 +76,106 @@ func main() {
 	ctx, cancel := signal.NotifyContext(context.Background(),
 		syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 
-	logg.Info("getting storage...")
-	s, err := getStorage(ctx, cfg)
-	if err != nil {
-		logg.Error(err.Error())
+	if flag.Arg(0) == "migrate" {
+		err = runMigrate(ctx, cfg, flag.Args()[1:])
 		cancel()
-		os.Exit(1)
+		if err != nil {
+			fmt.Printf("Critical app error: %v\n", err)
+			os.Exit(1)
+		}
+
+		return
 	}
 
-	conn, err := amqp.Dial(cfg.Rmq.Uri)
+	if flag.Arg(0) == "report" {
+		err = runReport(ctx, cfg, flag.Args()[1:])
+		cancel()
+		if err != nil {
+			fmt.Printf("Critical app error: %v\n", err)
+			os.Exit(1)
+		}
+
+		return
+	}
+
+	if flag.Arg(0) == "replay" {
+		err = runReplay(ctx, cfg, flag.Args()[1:])
+		cancel()
+		if err != nil {
+			fmt.Printf("Critical app error: %v\n", err)
+			os.Exit(1)
+		}
+
+		return
+	}
+
+	var m *metrics.Metrics
+	if cfg.Metrics.Enabled {
+		m = metrics.NewMetrics(metrics.WithSlotLabels(cfg.Metrics.Slots))
+	}
+
+	logg.Info("getting storage...", "type", cfg.Storage.Type)
+	s, err := getStorage(ctx, cfg, logg, m)
 	if err != nil {
 		logg.Error(err.Error())
 		cancel()
 		os.Exit(1)
 	}
+	defer func() {
+		if err := s.Close(); err != nil {
+			logg.Error(err.Error())
+		}
+	}()
+	if sqlStorage, ok := s.(*sqlstorage.Storage); ok && m != nil {
+		m.ObserveDroppedEvents(sqlStorage.DroppedEvents)
+	}
 
-	p := rmq.NewRMQProducer(cfg.Rmq.Name, conn)
-	err = p.Connect()
+	logg.Info("getting publisher...", "type", cfg.Publisher.Type)
+	p, err := getPublisher(cfg, m)
 	if err != nil {
 		logg.Error(err.Error())
 		cancel()
 		os.Exit(1)
 	}
+	defer p.Close()
+
+	var events rotator.EventPublisher = p
+	if cfg.Publisher.Batch.Enabled {
+		bp, spill, err := getBatchPublisher(cfg, p, logg)
+		if err != nil {
+			logg.Error(err.Error())
+			cancel()
+			os.Exit(1)
+		}
+		if spill != nil {
+			defer spill.Close()
+		}
+		defer shutdownBatchPublisher(cfg, bp, logg)
+		if m != nil {
+			m.ObserveBatchPublisher(bp)
+		}
+
+		events = bp
+	}
 
-	b := bandit.NewBandit()
-	app := rotator.NewApp(s, p, b)
-	srv := internalgrpc.NewRPCServer(logg, app, cfg.Api.Host, cfg.Api.Port)
+	b := getBandit(cfg)
+	app := rotator.NewApp(s, events, b, getAppOptions(cfg, m, logg)...)
+
+	var interceptors []grpc.UnaryServerInterceptor
+	if m != nil {
+		interceptors = append(interceptors, m.UnaryServerInterceptor())
+		go runMetrics(ctx, cfg, m, logg)
+	}
+	srv := internalgrpc.NewRPCServer(logg, app, cfg.Api.Host, cfg.Api.Port, interceptors...)
+
+	if cfg.Pruning.Interval > 0 {
+		go runPruning(ctx, cfg, app, logg)
+	}
+
+	if cfg.Bandit.Contextual.Enabled {
+		go runModelUpdates(ctx, cfg, app, logg)
+		defer flushModels(app, logg)
+	}
 
 	go func() {
 		<-ctx.Done()
@@ -85,8 +193,78 @@ func main() {
 	defer cancel()
 }
 
-func getStorage(ctx context.Context, cfg *config.AppConfig) (rotator.Storage, error) {
-	storage, err := sqlstorage.NewStorage(ctx, cfg.Storage.ConnectionString)
+// getBandit seeds the bandit with bandit.seed, zero seed makes every run
+// to choose differently.
+func getBandit(cfg *config.AppConfig) rotator.Bandit {
+	if cfg.Bandit.Seed != 0 {
+		return bandit.NewBandit(bandit.WithSeed(cfg.Bandit.Seed))
+	}
+
+	return bandit.NewBandit()
+}
+
+// getAppOptions sets the views a child group borrows the parent statistics
+// for, counts the selected banners in the metrics and enables the contextual
+// bandit, it shares bandit.seed with the main bandit.
+func getAppOptions(cfg *config.AppConfig, m *metrics.Metrics, logg rotator.Logger) []rotator.Option {
+	appOpts := []rotator.Option{rotator.WithGroupMinViews(cfg.Bandit.GroupMinViews)}
+	if m != nil {
+		appOpts = append(appOpts, rotator.WithSelectionObserver(m))
+	}
+	if !cfg.Bandit.Contextual.Enabled {
+		return appOpts
+	}
+
+	opts := []linucb.Option{
+		linucb.WithAlpha(cfg.Bandit.Contextual.Alpha),
+		linucb.WithDimension(cfg.Bandit.Contextual.Dimension),
+		linucb.WithLogger(logg),
+	}
+	if cfg.Bandit.Seed != 0 {
+		opts = append(opts, linucb.WithSeed(cfg.Bandit.Seed))
+	}
+
+	return append(appOpts, rotator.WithContextualBandit(linucb.NewBandit(opts...)))
+}
+
+func getStorage(ctx context.Context, cfg *config.AppConfig, logg rotator.Logger, m *metrics.Metrics) (appStorage, error) {
+	switch cfg.Storage.Type {
+	case "sql":
+		return getSQLStorage(ctx, cfg, logg, m)
+	case "sqlite":
+		return getSQLiteStorage(ctx, cfg, logg, m)
+	case "memory":
+		return memorystorage.NewStorage(), nil
+	default:
+		return nil, fmt.Errorf("get storage -> %w (%s)", ErrUnknownStorage, cfg.Storage.Type)
+	}
+}
+
+func getSQLStorage(
+	ctx context.Context,
+	cfg *config.AppConfig,
+	logg rotator.Logger,
+	m *metrics.Metrics,
+) (*sqlstorage.Storage, error) {
+	if cfg.Storage.Migrate {
+		logg.Info("applying migrations...")
+		if err := migrateOnStart(ctx, cfg, logg); err != nil {
+			return nil, fmt.Errorf("get storage -> %w", err)
+		}
+	}
+
+	opts := append(getSQLStorageOptions(cfg, m), sqlstorage.WithPool(sqlstorage.PoolConfig{
+		MaxOpenConns:    cfg.Storage.Pool.MaxOpenConns,
+		MaxIdleConns:    cfg.Storage.Pool.MaxIdleConns,
+		ConnMaxLifetime: cfg.Storage.Pool.ConnMaxLifetime,
+		ConnMaxIdleTime: cfg.Storage.Pool.ConnMaxIdleTime,
+	}))
+	if cfg.Storage.Replica.ConnectionString != "" {
+		logg.Info("routing reads to replica...")
+		opts = append(opts, sqlstorage.WithReplica(cfg.Storage.Replica.ConnectionString, cfg.Storage.Replica.CheckInterval))
+	}
+
+	storage, err := sqlstorage.NewStorage(ctx, cfg.Storage.ConnectionString, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("get storage -> %w", err)
 	}
@@ -96,5 +274,282 @@ func getStorage(ctx context.Context, cfg *config.AppConfig) (rotator.Storage, er
 		return nil, fmt.Errorf("get storage -> %w", err)
 	}
 
+	if cfg.Storage.Partitions.Enabled {
+		logg.Info("maintaining partitions...")
+		if err = maintainPartitions(ctx, cfg, storage); err != nil {
+			_ = storage.Close()
+			return nil, fmt.Errorf("get storage -> %w", err)
+		}
+
+		go runPartitionsMaintenance(ctx, cfg, storage, logg)
+	}
+
 	return storage, nil
 }
+
+// getSQLiteStorage opens the embedded SQLite storage. Partitions are not
+// supported by SQLite, so the partitions settings are ignored.
+func getSQLiteStorage(
+	ctx context.Context,
+	cfg *config.AppConfig,
+	logg rotator.Logger,
+	m *metrics.Metrics,
+) (*sqlstorage.Storage, error) {
+	if cfg.Storage.Migrate {
+		logg.Info("applying migrations...")
+		if err := migrateOnStart(ctx, cfg, logg); err != nil {
+			return nil, fmt.Errorf("get storage -> %w", err)
+		}
+	}
+
+	storage, err := sqlstorage.NewSQLiteStorage(ctx, cfg.Storage.Path, getSQLStorageOptions(cfg, m)...)
+	if err != nil {
+		return nil, fmt.Errorf("get storage -> %w", err)
+	}
+
+	return storage, nil
+}
+
+func getSQLStorageOptions(cfg *config.AppConfig, m *metrics.Metrics) []sqlstorage.Option {
+	var opts []sqlstorage.Option
+	if cfg.Storage.Batch.Size > 0 {
+		opts = append(opts, sqlstorage.WithBatch(cfg.Storage.Batch.Size, cfg.Storage.Batch.FlushInterval))
+	}
+	if m != nil {
+		opts = append(opts, sqlstorage.WithQueryObserver(m))
+	}
+
+	return opts
+}
+
+func maintainPartitions(ctx context.Context, cfg *config.AppConfig, s *sqlstorage.Storage) error {
+	return s.MaintainPartitions(ctx, time.Now(), sqlstorage.PartitionPolicy{
+		Interval:  cfg.Storage.Partitions.Interval,
+		Ahead:     cfg.Storage.Partitions.Ahead,
+		Retention: cfg.Storage.Partitions.Retention,
+	})
+}
+
+func runPartitionsMaintenance(
+	ctx context.Context,
+	cfg *config.AppConfig,
+	s *sqlstorage.Storage,
+	logg rotator.Logger,
+) {
+	ticker := time.NewTicker(cfg.Storage.Partitions.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := maintainPartitions(ctx, cfg, s); err != nil {
+				logg.Error(err.Error())
+			}
+		}
+	}
+}
+
+// runPruning prunes the slots with an enabled pruning policy every
+// pruning.interval. The instances may prune concurrently, a rotation is
+// paused and reported once.
+func runPruning(ctx context.Context, cfg *config.AppConfig, app rotator.App, logg rotator.Logger) {
+	ticker := time.NewTicker(cfg.Pruning.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			prunings, err := app.PruneSlots()
+			if err != nil {
+				logg.Error(err.Error())
+			}
+			for _, p := range prunings {
+				logg.Info("banner pruned",
+					"slot", p.SlotID,
+					"banner", p.BannerID,
+					"leader", p.LeaderID,
+					"probability", p.Probability,
+				)
+			}
+		}
+	}
+}
+
+// runModelUpdates saves the queued contextual model updates every
+// bandit.contextual.flushInterval.
+func runModelUpdates(ctx context.Context, cfg *config.AppConfig, app rotator.App, logg rotator.Logger) {
+	ticker := time.NewTicker(cfg.Bandit.Contextual.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			flushModels(app, logg)
+		}
+	}
+}
+
+func flushModels(app rotator.App, logg rotator.Logger) {
+	if err := app.FlushModels(); err != nil {
+		logg.Error(err.Error())
+	}
+}
+
+// runMetrics serves the metrics on metrics.host:metrics.port/metrics until
+// the context is done.
+func runMetrics(ctx context.Context, cfg *config.AppConfig, m *metrics.Metrics, logg rotator.Logger) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m.Handler())
+	srv := &http.Server{
+		Addr:              net.JoinHostPort(cfg.Metrics.Host, cfg.Metrics.Port),
+		Handler:           mux,
+		ReadHeaderTimeout: metricsReadHeaderTimeout,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), metricsShutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			logg.Error("failed to stop metrics server: " + err.Error())
+		}
+	}()
+
+	logg.Info("serving metrics...", "addr", srv.Addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logg.Error("failed to start metrics server: " + err.Error())
+	}
+}
+
+func getPublisher(cfg *config.AppConfig, m *metrics.Metrics) (eventPublisher, error) {
+	switch cfg.Publisher.Type {
+	case "rmq":
+		return getRMQPublisher(cfg, m)
+	case "file":
+		p, err := publisher.NewFilePublisher(cfg.Publisher.Path, getInstance(cfg))
+		if err != nil {
+			return nil, fmt.Errorf("get publisher -> %w", err)
+		}
+
+		return p, nil
+	case "nop":
+		return publisher.NewNopPublisher(), nil
+	default:
+		return nil, fmt.Errorf("get publisher -> %w (%s)", ErrUnknownPublisher, cfg.Publisher.Type)
+	}
+}
+
+func getBatchPublisher(
+	cfg *config.AppConfig,
+	next rotator.EventPublisher,
+	logg rotator.Logger,
+) (*publisher.BatchPublisher, eventPublisher, error) {
+	batchCfg := publisher.BatchConfig{
+		Workers:       cfg.Publisher.Batch.Workers,
+		QueueSize:     cfg.Publisher.Batch.QueueSize,
+		Size:          cfg.Publisher.Batch.Size,
+		FlushInterval: cfg.Publisher.Batch.FlushInterval,
+		Policy:        cfg.Publisher.Batch.Policy,
+	}
+
+	if batchCfg.Policy != publisher.PolicySpill {
+		bp, err := publisher.NewBatchPublisher(next, nil, logg, batchCfg)
+		if err != nil {
+			return nil, nil, fmt.Errorf("get batch publisher -> %w", err)
+		}
+
+		return bp, nil, nil
+	}
+
+	spill, err := publisher.NewFilePublisher(cfg.Publisher.Batch.SpillPath, getInstance(cfg))
+	if err != nil {
+		return nil, nil, fmt.Errorf("get batch publisher -> %w", err)
+	}
+
+	bp, err := publisher.NewBatchPublisher(next, spill, logg, batchCfg)
+	if err != nil {
+		_ = spill.Close()
+		return nil, nil, fmt.Errorf("get batch publisher -> %w", err)
+	}
+
+	return bp, spill, nil
+}
+
+func shutdownBatchPublisher(cfg *config.AppConfig, bp *publisher.BatchPublisher, logg rotator.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Publisher.Batch.ShutdownTimeout)
+	defer cancel()
+
+	logg.Info("flushing events...", "queueDepth", bp.Stats().QueueDepth)
+	if err := bp.Shutdown(ctx); err != nil {
+		logg.Error(err.Error())
+	}
+
+	stats := bp.Stats()
+	logg.Info("publisher stopped",
+		"published", stats.Published,
+		"dropped", stats.Dropped,
+		"spilled", stats.Spilled,
+		"failed", stats.Failed,
+		"queueDepth", stats.QueueDepth,
+	)
+}
+
+// rmqPublisher closes the connection of the producer with the producer.
+type rmqPublisher struct {
+	*rmq.Producer
+	conn *amqp.Connection
+}
+
+func (p *rmqPublisher) Close() error {
+	err := p.Producer.Close()
+	if connErr := p.conn.Close(); err == nil {
+		err = connErr
+	}
+
+	return err
+}
+
+func getRMQPublisher(cfg *config.AppConfig, m *metrics.Metrics) (eventPublisher, error) {
+	encoding, err := rmq.NewEncoding(cfg.Rmq.Encoding)
+	if err != nil {
+		return nil, fmt.Errorf("get publisher -> %w", err)
+	}
+
+	conn, err := amqp.Dial(cfg.Rmq.Uri)
+	if err != nil {
+		return nil, fmt.Errorf("get publisher -> %w", err)
+	}
+
+	var opts []rmq.ProducerOption
+	if m != nil {
+		opts = append(opts, rmq.WithPublishObserver(m))
+	}
+
+	p := rmq.NewRMQProducer(cfg.Rmq.Name, getInstance(cfg), conn, encoding, opts...)
+	if err = p.Connect(); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("get publisher -> %w", err)
+	}
+
+	return &rmqPublisher{Producer: p, conn: conn}, nil
+}
+
+func getInstance(cfg *config.AppConfig) string {
+	if cfg.Rmq.Instance != "" {
+		return cfg.Rmq.Instance
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "rotator"
+	}
+
+	return hostname
+}
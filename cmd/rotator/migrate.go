@@ -0,0 +1,122 @@
+package main
+
+import (
+	"banners-rotator/internal/config"
+	"banners-rotator/internal/migrator"
+	"banners-rotator/internal/rotator"
+	sqlstorage "banners-rotator/internal/storage/sql"
+	"banners-rotator/migrations"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"text/tabwriter"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var ErrUnknownMigrateCommand = errors.New("unknown migrate command, expected up, down or status")
+
+func getMigrator(ctx context.Context, cfg *config.AppConfig) (*migrator.Migrator, *sqlx.DB, error) {
+	var (
+		db   *sqlx.DB
+		fsys fs.FS
+		err  error
+	)
+	switch cfg.Storage.Type {
+	case "sql":
+		db, err = sqlx.ConnectContext(ctx, "postgres", cfg.Storage.ConnectionString)
+		fsys = migrations.FS
+	case "sqlite":
+		db, err = sqlstorage.OpenSQLite(ctx, cfg.Storage.Path)
+		fsys = migrations.SQLiteFS
+	default:
+		return nil, nil, fmt.Errorf("migrate -> %w (%s)", ErrUnknownStorage, cfg.Storage.Type)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("migrate -> %w", err)
+	}
+
+	m, err := migrator.NewMigrator(db, fsys)
+	if err != nil {
+		_ = db.Close()
+		return nil, nil, fmt.Errorf("migrate -> %w", err)
+	}
+
+	return m, db, nil
+}
+
+func runMigrate(ctx context.Context, cfg *config.AppConfig, args []string) error {
+	if len(args) != 1 {
+		return ErrUnknownMigrateCommand
+	}
+
+	m, db, err := getMigrator(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	switch args[0] {
+	case "up":
+		applied, err := m.Up(ctx)
+		for _, migration := range applied {
+			fmt.Printf("applied %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			return fmt.Errorf("migrate -> %w", err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+	case "down":
+		reverted, err := m.Down(ctx)
+		if err != nil {
+			return fmt.Errorf("migrate -> %w", err)
+		}
+		fmt.Printf("reverted %04d_%s\n", reverted.Version, reverted.Name)
+	case "status":
+		status, err := m.Status(ctx)
+		if err != nil {
+			return fmt.Errorf("migrate -> %w", err)
+		}
+		printMigrationsStatus(status)
+	default:
+		return ErrUnknownMigrateCommand
+	}
+
+	return nil
+}
+
+func printMigrationsStatus(status []migrator.Status) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+	for _, s := range status {
+		appliedAt := "pending"
+		if s.Applied {
+			appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05 MST")
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+	}
+	w.Flush()
+}
+
+// migrateOnStart applies the pending migrations before the service starts.
+func migrateOnStart(ctx context.Context, cfg *config.AppConfig, logg rotator.Logger) error {
+	m, db, err := getMigrator(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	applied, err := m.Up(ctx)
+	for _, migration := range applied {
+		logg.Info("migration applied", "version", migration.Version, "name", migration.Name)
+	}
+	if err != nil {
+		return fmt.Errorf("migrate -> %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"banners-rotator/internal/bandit"
+	"banners-rotator/internal/config"
+	"banners-rotator/internal/replay"
+	"banners-rotator/internal/rotator"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+var (
+	ErrUnknownReplayFormat   = errors.New("unknown replay format, expected table or json")
+	ErrUnknownReplayStrategy = errors.New("unknown replay strategy")
+)
+
+// runReplay estimates the CTR of the strategies on the logged events of the
+// storage or of a JSON-lines file, or exports the events to such a file.
+func runReplay(ctx context.Context, cfg *config.AppConfig, args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	events := fs.String("events", "", "JSON-lines file with the events, the storage by default")
+	export := fs.String("export", "", "Export the events of the storage to the JSON-lines file and exit")
+	slotID := fs.Int64("slot", 0, "Slot id, all slots by default")
+	from := fs.String("from", "1970-01-01", "Start of the range, inclusive, RFC 3339 or 2006-01-02")
+	to := fs.String("to", "", "End of the range, exclusive, now by default")
+	strategies := fs.String("strategies", strings.Join(bandit.StrategyNames(), ","),
+		"Comma separated strategies: "+strings.Join(bandit.StrategyNames(), ", "))
+	seed := fs.Int64("seed", 1, "Random seed of the strategies")
+	format := fs.String("format", "table", "Output format: table or json")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("replay -> %w", err)
+	}
+
+	start, err := parseReportTime(*from)
+	if err != nil {
+		return fmt.Errorf("replay -> from -> %w", err)
+	}
+	filter := replay.Filter{SlotID: *slotID, From: start.Unix(), To: time.Now().Unix() + 1}
+	if *to != "" {
+		end, err := parseReportTime(*to)
+		if err != nil {
+			return fmt.Errorf("replay -> to -> %w", err)
+		}
+		filter.To = end.Unix()
+	}
+
+	if *export != "" {
+		return exportEvents(ctx, cfg, *export, filter)
+	}
+
+	names := strings.Split(*strategies, ",")
+	bandits := make(map[string]rotator.Bandit, len(names))
+	for _, name := range names {
+		newBandit, ok := bandit.Strategies[name]
+		if !ok {
+			return fmt.Errorf("replay -> %w (%s)", ErrUnknownReplayStrategy, name)
+		}
+		bandits[name] = newBandit(*seed)
+	}
+
+	write := writeReplayTable
+	switch *format {
+	case "table":
+	case "json":
+		write = writeReplayJSON
+	default:
+		return fmt.Errorf("replay -> %w (%s)", ErrUnknownReplayFormat, *format)
+	}
+
+	var src replay.Source
+	if *events != "" {
+		src = replay.NewFileSource(*events)
+	} else {
+		s, err := getReportStorage(ctx, cfg)
+		if err != nil {
+			return fmt.Errorf("replay -> %w", err)
+		}
+		defer s.Close()
+		src = s
+	}
+
+	results, err := replay.Evaluate(ctx, src, filter, names, bandits)
+	if err != nil {
+		return err
+	}
+
+	if err = write(os.Stdout, results); err != nil {
+		return fmt.Errorf("replay -> %w", err)
+	}
+
+	return nil
+}
+
+func exportEvents(ctx context.Context, cfg *config.AppConfig, path string, filter replay.Filter) error {
+	s, err := getReportStorage(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("replay -> export -> %w", err)
+	}
+	defer s.Close()
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("replay -> export -> %w", err)
+	}
+	defer f.Close()
+
+	if err = s.Events(ctx, filter.SlotID, filter.From, filter.To, replay.WriteEvents(f)); err != nil {
+		return fmt.Errorf("replay -> export -> %w", err)
+	}
+
+	return nil
+}
+
+func writeReplayTable(w io.Writer, results []replay.Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "strategy\tviews\tclicks\tlogged ctr\tmatched\tmatched clicks\tctr\tfailures")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.4f\t%d\t%d\t%.4f\t%d\n",
+			r.Strategy, r.Views, r.Clicks, r.LoggedCTR, r.Matched, r.MatchedClicks, r.CTR, r.Failures)
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("write table -> %w", err)
+	}
+
+	return nil
+}
+
+func writeReplayJSON(w io.Writer, results []replay.Result) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(results); err != nil {
+		return fmt.Errorf("write json -> %w", err)
+	}
+
+	return nil
+}
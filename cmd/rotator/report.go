@@ -0,0 +1,180 @@
+package main
+
+import (
+	"banners-rotator/internal/bandit"
+	"banners-rotator/internal/config"
+	"banners-rotator/internal/publisher"
+	"banners-rotator/internal/rotator"
+	sqlstorage "banners-rotator/internal/storage/sql"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrUnknownReportFormat = errors.New("unknown report format, expected csv or json")
+	ErrReportStorage       = errors.New("report and replay need sql or sqlite storage")
+	ErrInvalidReportTime   = errors.New("invalid report time, expected RFC 3339 or 2006-01-02")
+)
+
+type reportRow struct {
+	Time   string  `json:"time"`
+	Views  int64   `json:"views"`
+	Clicks int64   `json:"clicks"`
+	CTR    float64 `json:"ctr"`
+}
+
+// runReport writes the CTR report of the slot in CSV or JSON.
+func runReport(ctx context.Context, cfg *config.AppConfig, args []string) error {
+	fs := flag.NewFlagSet("report", flag.ContinueOnError)
+	slotID := fs.Int64("slot", 0, "Slot id")
+	bannerID := fs.Int64("banner", 0, "Banner id, all banners by default")
+	groupID := fs.Int64("group", 0, "Group id, all groups by default")
+	from := fs.String("from", "", "Start of the range, inclusive, RFC 3339 or 2006-01-02")
+	to := fs.String("to", "", "End of the range, exclusive, now by default")
+	bucket := fs.String("bucket", rotator.BucketDay, "Bucket: hour, day or week")
+	format := fs.String("format", "csv", "Output format: csv or json")
+	out := fs.String("out", "", "Output file, stdout by default")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("report -> %w", err)
+	}
+
+	filter := rotator.ReportFilter{SlotID: *slotID, BannerID: *bannerID, GroupID: *groupID, Bucket: *bucket}
+	start, err := parseReportTime(*from)
+	if err != nil {
+		return fmt.Errorf("report -> from -> %w", err)
+	}
+	filter.From = start.Unix()
+
+	filter.To = time.Now().Unix() + 1
+	if *to != "" {
+		end, err := parseReportTime(*to)
+		if err != nil {
+			return fmt.Errorf("report -> to -> %w", err)
+		}
+		filter.To = end.Unix()
+	}
+
+	write := writeReportCSV
+	switch *format {
+	case "csv":
+	case "json":
+		write = writeReportJSON
+	default:
+		return fmt.Errorf("report -> %w (%s)", ErrUnknownReportFormat, *format)
+	}
+
+	s, err := getReportStorage(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("report -> %w", err)
+	}
+	defer s.Close()
+
+	app := rotator.NewApp(s, publisher.NewNopPublisher(), bandit.NewBandit())
+	points, err := app.CTRReport(filter)
+	if err != nil {
+		return fmt.Errorf("report -> %w", err)
+	}
+
+	w := io.Writer(os.Stdout)
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return fmt.Errorf("report -> %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err = write(w, points); err != nil {
+		return fmt.Errorf("report -> %w", err)
+	}
+
+	return nil
+}
+
+// getReportStorage opens the storage for the report and replay commands
+// without migrations and partitions maintenance, the reads go to the replica
+// when it is configured.
+func getReportStorage(ctx context.Context, cfg *config.AppConfig) (*sqlstorage.Storage, error) {
+	switch cfg.Storage.Type {
+	case "sql":
+		var opts []sqlstorage.Option
+		if cfg.Storage.Replica.ConnectionString != "" {
+			opts = append(opts, sqlstorage.WithReplica(cfg.Storage.Replica.ConnectionString, cfg.Storage.Replica.CheckInterval))
+		}
+
+		return sqlstorage.NewStorage(ctx, cfg.Storage.ConnectionString, opts...)
+	case "sqlite":
+		return sqlstorage.NewSQLiteStorage(ctx, cfg.Storage.Path)
+	default:
+		return nil, fmt.Errorf("%w (%s)", ErrReportStorage, cfg.Storage.Type)
+	}
+}
+
+func parseReportTime(value string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%w (%q)", ErrInvalidReportTime, value)
+}
+
+func reportRows(points []rotator.ReportPoint) []reportRow {
+	rows := make([]reportRow, 0, len(points))
+	for _, p := range points {
+		rows = append(rows, reportRow{
+			Time:   time.Unix(p.Time, 0).UTC().Format(time.RFC3339),
+			Views:  p.Views,
+			Clicks: p.Clicks,
+			CTR:    p.CTR,
+		})
+	}
+
+	return rows
+}
+
+func writeReportCSV(w io.Writer, points []rotator.ReportPoint) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"time", "views", "clicks", "ctr"}); err != nil {
+		return fmt.Errorf("write csv -> %w", err)
+	}
+
+	for _, row := range reportRows(points) {
+		err := cw.Write([]string{
+			row.Time,
+			strconv.FormatInt(row.Views, 10),
+			strconv.FormatInt(row.Clicks, 10),
+			strconv.FormatFloat(row.CTR, 'f', -1, 64),
+		})
+		if err != nil {
+			return fmt.Errorf("write csv -> %w", err)
+		}
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("write csv -> %w", err)
+	}
+
+	return nil
+}
+
+func writeReportJSON(w io.Writer, points []rotator.ReportPoint) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(reportRows(points)); err != nil {
+		return fmt.Errorf("write json -> %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"banners-rotator/internal/bandit"
+	"banners-rotator/internal/simulator"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+)
+
+var (
+	ErrUnknownStrategy = errors.New("unknown strategy")
+	ErrUnknownFormat   = errors.New("unknown format, expected table or json")
+)
+
+var (
+	scenarioFile string
+	strategy     string
+	format       string
+	steps        int
+	seed         int64
+)
+
+func init() {
+	flag.StringVar(&scenarioFile, "scenario", "configs/simulation.json", "Path to scenario file")
+	flag.StringVar(&strategy, "strategy", "ucb1", "Bandit strategy: "+strings.Join(bandit.StrategyNames(), ", "))
+	flag.StringVar(&format, "format", "table", "Output format: table or json")
+	flag.IntVar(&steps, "steps", 0, "Number of steps, overrides the scenario")
+	flag.Int64Var(&seed, "seed", 0, "Random seed, overrides the scenario")
+}
+
+func main() {
+	flag.Parse()
+
+	ctx, cancel := signal.NotifyContext(context.Background(),
+		syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	defer cancel()
+
+	if err := run(ctx, os.Stdout); err != nil {
+		fmt.Printf("Critical app error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, w io.Writer) error {
+	newBandit, ok := bandit.Strategies[strategy]
+	if !ok {
+		return fmt.Errorf("simulate -> %w (%s)", ErrUnknownStrategy, strategy)
+	}
+
+	write := writeTable
+	switch format {
+	case "table":
+	case "json":
+		write = writeJSON
+	default:
+		return fmt.Errorf("simulate -> %w (%s)", ErrUnknownFormat, format)
+	}
+
+	sc, err := readScenario(scenarioFile)
+	if err != nil {
+		return fmt.Errorf("simulate -> %w", err)
+	}
+	if steps > 0 {
+		sc.Steps = steps
+	}
+	if seed != 0 {
+		sc.Seed = seed
+	}
+
+	result, err := simulator.Run(ctx, newBandit(sc.Seed), sc)
+	if err != nil {
+		return err
+	}
+
+	return write(w, result)
+}
+
+func readScenario(path string) (simulator.Scenario, error) {
+	var sc simulator.Scenario
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return sc, fmt.Errorf("read scenario -> %w", err)
+	}
+	if err = json.Unmarshal(data, &sc); err != nil {
+		return sc, fmt.Errorf("read scenario -> %w", err)
+	}
+
+	return sc, nil
+}
+
+func writeTable(w io.Writer, result *simulator.Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "steps\t%d\n", result.Steps)
+	fmt.Fprintf(tw, "clicks\t%d\n", result.Clicks)
+	fmt.Fprintf(tw, "regret\t%.2f\n", result.Regret)
+	fmt.Fprintf(tw, "failures\t%d\n", result.Failures)
+	if result.ConvergenceStep < 0 {
+		fmt.Fprintf(tw, "convergence\tnot converged\n")
+	} else {
+		fmt.Fprintf(tw, "convergence\t%d\n", result.ConvergenceStep)
+	}
+	fmt.Fprintln(tw)
+
+	fmt.Fprintln(tw, "banner\timpressions\tclicks\tshare\tctr")
+	for _, b := range result.Banners {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.4f\t%.4f\n", b.Name, b.Impressions, b.Clicks, b.Share, b.CTR)
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("write table -> %w", err)
+	}
+
+	return nil
+}
+
+func writeJSON(w io.Writer, result *simulator.Result) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(result); err != nil {
+		return fmt.Errorf("write json -> %w", err)
+	}
+
+	return nil
+}
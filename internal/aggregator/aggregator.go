@@ -0,0 +1,84 @@
+package aggregator
+
+import (
+	"banners-rotator/internal/rmq"
+	"banners-rotator/internal/rotator"
+	"banners-rotator/internal/storage"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const hour = 3600
+
+type Storage interface {
+	AddHourlyStats(messageID string, stats storage.HourlyStats) error
+	// PurgeProcessedMessages forgets the messages processed before the time.
+	PurgeProcessedMessages(before time.Time) (int64, error)
+}
+
+type Aggregator struct {
+	storage Storage
+	logger  rotator.Logger
+}
+
+func NewAggregator(s Storage, logger rotator.Logger) *Aggregator {
+	return &Aggregator{storage: s, logger: logger}
+}
+
+// Handle is a rmq.Handler which adds the event to the hourly statistics.
+func (a *Aggregator) Handle(messageID string, message rmq.QMessage) error {
+	if messageID == "" {
+		return fmt.Errorf("aggregator -> handle -> %w (empty message id)", rmq.ErrMessageRejected)
+	}
+
+	stats := storage.HourlyStats{
+		SlotID:   message.SlotID,
+		BannerID: message.BannerID,
+		GroupID:  message.GroupID,
+	}
+	date := message.Timestamp / 1000
+	stats.Hour = date - date%hour
+
+	switch message.Type {
+	case rmq.EventView:
+		stats.Views = 1
+	case rmq.EventClick:
+		stats.Clicks = 1
+	case rmq.EventConversion, rmq.EventPrune:
+		// the hourly stats count views and clicks only
+		return nil
+	default:
+		return fmt.Errorf(
+			"aggregator -> handle -> %w (unknown event type %q)",
+			rmq.ErrMessageRejected,
+			message.Type,
+		)
+	}
+
+	err := a.storage.AddHourlyStats(messageID, stats)
+	if errors.Is(err, storage.ErrMessageProcessed) {
+		a.logger.Debug("skip processed message", "id", messageID)
+
+		return nil
+	}
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("aggregator -> handle -> %s", err))
+
+		return fmt.Errorf("aggregator -> handle -> %w", err)
+	}
+
+	return nil
+}
+
+// Purge forgets the IDs of the messages processed before the time, a
+// redelivery of such a message is counted again.
+func (a *Aggregator) Purge(before time.Time) error {
+	count, err := a.storage.PurgeProcessedMessages(before)
+	if err != nil {
+		return fmt.Errorf("aggregator -> purge -> %w", err)
+	}
+	a.logger.Debug("processed messages purged", "count", count)
+
+	return nil
+}
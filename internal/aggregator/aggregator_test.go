@@ -0,0 +1,142 @@
+package aggregator
+
+import (
+	"banners-rotator/internal/logger"
+	"banners-rotator/internal/rmq"
+	"banners-rotator/internal/storage"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testStorage struct {
+	processed map[string]struct{}
+	purged    time.Time
+	stats     map[storage.HourlyStats]storage.HourlyStats
+	err       error
+}
+
+func newTestStorage() *testStorage {
+	return &testStorage{
+		processed: make(map[string]struct{}),
+		stats:     make(map[storage.HourlyStats]storage.HourlyStats),
+	}
+}
+
+func (s *testStorage) AddHourlyStats(messageID string, stats storage.HourlyStats) error {
+	if s.err != nil {
+		return s.err
+	}
+	if _, ok := s.processed[messageID]; ok {
+		return fmt.Errorf("test -> %w", storage.ErrMessageProcessed)
+	}
+	s.processed[messageID] = struct{}{}
+
+	key := storage.HourlyStats{SlotID: stats.SlotID, BannerID: stats.BannerID, GroupID: stats.GroupID, Hour: stats.Hour}
+	item := s.stats[key]
+	item.Views += stats.Views
+	item.Clicks += stats.Clicks
+	s.stats[key] = item
+
+	return nil
+}
+
+func (s *testStorage) PurgeProcessedMessages(before time.Time) (int64, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	s.purged = before
+	count := int64(len(s.processed))
+	s.processed = make(map[string]struct{})
+
+	return count, nil
+}
+
+func getAggregator(t *testing.T) (*Aggregator, *testStorage) {
+	t.Helper()
+
+	logg, err := logger.NewLogger("error", []string{"stderr"})
+	require.NoError(t, err)
+
+	s := newTestStorage()
+
+	return NewAggregator(s, logg), s
+}
+
+func TestAggregator_Handle(t *testing.T) {
+	t.Run("aggregate events by hour", func(t *testing.T) {
+		a, s := getAggregator(t)
+
+		require.NoError(t, a.Handle("1", rmq.QMessage{Type: "view", SlotID: 1, BannerID: 1, GroupID: 1, Timestamp: 3601000}))
+		require.NoError(t, a.Handle("2", rmq.QMessage{Type: "view", SlotID: 1, BannerID: 1, GroupID: 1, Timestamp: 7199000}))
+		require.NoError(t, a.Handle("3", rmq.QMessage{Type: "click", SlotID: 1, BannerID: 1, GroupID: 1, Timestamp: 7000000}))
+		require.NoError(t, a.Handle("4", rmq.QMessage{Type: "view", SlotID: 1, BannerID: 1, GroupID: 1, Timestamp: 7200000}))
+
+		key := storage.HourlyStats{SlotID: 1, BannerID: 1, GroupID: 1, Hour: 3600}
+		require.Equal(t, int64(2), s.stats[key].Views)
+		require.Equal(t, int64(1), s.stats[key].Clicks)
+
+		key.Hour = 7200
+		require.Equal(t, int64(1), s.stats[key].Views)
+	})
+
+	t.Run("skip redelivered message", func(t *testing.T) {
+		a, s := getAggregator(t)
+		message := rmq.QMessage{Type: "click", SlotID: 1, BannerID: 1, GroupID: 1, Timestamp: 1000}
+
+		require.NoError(t, a.Handle("1", message))
+		require.NoError(t, a.Handle("1", message))
+
+		key := storage.HourlyStats{SlotID: 1, BannerID: 1, GroupID: 1}
+		require.Equal(t, int64(1), s.stats[key].Clicks)
+	})
+
+	t.Run("ignore conversion", func(t *testing.T) {
+		a, s := getAggregator(t)
+
+		err := a.Handle("1", rmq.QMessage{Type: "conversion", SlotID: 1, BannerID: 1, GroupID: 1, Value: 10})
+		require.NoError(t, err)
+		require.Empty(t, s.stats)
+	})
+
+	t.Run("reject invalid message", func(t *testing.T) {
+		a, _ := getAggregator(t)
+
+		err := a.Handle("", rmq.QMessage{Type: "view"})
+		require.ErrorIs(t, err, rmq.ErrMessageRejected)
+
+		err = a.Handle("1", rmq.QMessage{Type: "unknown"})
+		require.ErrorIs(t, err, rmq.ErrMessageRejected)
+	})
+
+	t.Run("storage error", func(t *testing.T) {
+		a, s := getAggregator(t)
+		s.err = storage.ErrStatsNotSaved
+
+		err := a.Handle("1", rmq.QMessage{Type: "view"})
+		require.ErrorIs(t, err, storage.ErrStatsNotSaved)
+		require.NotErrorIs(t, err, rmq.ErrMessageRejected)
+	})
+}
+
+func TestAggregator_Purge(t *testing.T) {
+	t.Run("purge processed messages", func(t *testing.T) {
+		a, s := getAggregator(t)
+		message := rmq.QMessage{Type: "click", SlotID: 1, BannerID: 1, GroupID: 1, Timestamp: 1000}
+		require.NoError(t, a.Handle("1", message))
+
+		before := time.Unix(3600, 0)
+		require.NoError(t, a.Purge(before))
+		require.Equal(t, before, s.purged)
+		require.Empty(t, s.processed)
+	})
+
+	t.Run("storage error", func(t *testing.T) {
+		a, s := getAggregator(t)
+		s.err = fmt.Errorf("test error")
+
+		require.Error(t, a.Purge(time.Unix(3600, 0)))
+	})
+}
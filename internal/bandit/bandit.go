@@ -5,100 +5,193 @@ import (
 	"banners-rotator/internal/storage"
 	"math"
 	"math/rand"
+	"sort"
+	"sync"
+	"time"
 )
 
-type scoresItem struct {
-	banner storage.Banner
-	score  float64
+// Bandit draws all random numbers from its own source, so the choices are
+// reproducible for a seeded source.
+type Bandit struct {
+	mu  sync.Mutex
+	rnd *rand.Rand
 }
 
-type Bandit struct{}
+type Option func(b *Bandit)
 
-func NewBandit() rotator.Bandit {
-	return &Bandit{}
+// WithSeed seeds the random source of the bandit.
+func WithSeed(seed int64) Option {
+	return WithSource(rand.NewSource(seed))
+}
+
+// WithSource makes the bandit to draw random numbers from src. The bandit
+// guards the source, it is not required to be safe for concurrent use.
+func WithSource(src rand.Source) Option {
+	return func(b *Bandit) {
+		b.rnd = rand.New(src)
+	}
+}
+
+func NewBandit(opts ...Option) rotator.Bandit {
+	b := &Bandit{}
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	return b
 }
 
 func (b *Bandit) RandomBanner(banners []storage.Banner) (*storage.Banner, error) {
 	if len(banners) > 0 {
-		i := rand.Intn(len(banners))
+		i := b.intn(len(banners))
 		return &banners[i], nil
 	}
 
 	return nil, ErrEmptyBanners
 }
 
+func (b *Bandit) intn(n int) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.rnd == nil {
+		b.rnd = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	return b.rnd.Intn(n)
+}
+
+// TopRatedBanner selects the banner with the top UCB1 score, the priors are
+// added to the views and rewards of the banners. The banners without views
+// and prior have the infinite score, so they are shown first.
 func (b *Bandit) TopRatedBanner(
 	banners []storage.Banner,
 	views []storage.ViewEvent,
-	clicks []storage.ClickEvent,
+	rewards []rotator.Reward,
+	priors map[int64]storage.Prior,
 ) (*storage.Banner, error) {
-	cViews, cClicks, err := b.prepare(banners, views, clicks)
-	if err != nil {
-		return nil, err
-	}
+	top := b.topBanners(b.Scores(banners, views, rewards, priors))
+	return b.RandomBanner(top)
+}
 
-	scores := make(map[int64]scoresItem)
-	for _, banner := range banners {
-		scores[banner.ID] = scoresItem{
-			banner,
-			b.bannerScore(
-				float64(cViews[banner.ID]),
-				float64(cClicks[banner.ID]),
-				float64(len(cClicks)),
-			),
-		}
-	}
+// Score is the UCB1 score of the banner with the views and the rewards among
+// the totalViews views of all banners of the slot. A banner without views
+// has the infinite score, as it is shown before any viewed banner.
+func (b *Bandit) Score(views int64, rewards float64, totalViews int64) float64 {
+	return b.bannerScore(float64(views), rewards, float64(totalViews))
+}
 
-	top := b.topBanners(scores)
-	return b.RandomBanner(top)
+// rewardsItem is the number and the sum of the rewards of a banner.
+type rewardsItem struct {
+	count int64
+	sum   float64
 }
 
-func (b Bandit) prepare(
-	banners []storage.Banner,
-	views []storage.ViewEvent,
-	clicks []storage.ClickEvent,
-) (map[int64]int, map[int64]int, error) {
-	cachedViews := make(map[int64]int)
-	cachedClicks := make(map[int64]int)
+func (b *Bandit) count(views []storage.ViewEvent, rewards []rotator.Reward) (map[int64]int64, map[int64]rewardsItem) {
+	cachedViews := make(map[int64]int64)
+	cachedRewards := make(map[int64]rewardsItem)
 
 	for _, view := range views {
 		cachedViews[view.BannerID]++
 	}
 
-	for _, click := range clicks {
-		cachedClicks[click.BannerID]++
+	for _, reward := range rewards {
+		item := cachedRewards[reward.BannerID]
+		item.count++
+		item.sum += reward.Value
+		cachedRewards[reward.BannerID] = item
+	}
+
+	return cachedViews, cachedRewards
+}
+
+// Scores splits the scores of the banners, as TopRatedBanner computes them,
+// into the average reward and the exploration bonus. The total number of
+// views is the sum of the views and prior views of the banners. A banner
+// without views and prior has the infinite bonus.
+func (b *Bandit) Scores(
+	banners []storage.Banner,
+	views []storage.ViewEvent,
+	rewards []rotator.Reward,
+	priors map[int64]storage.Prior,
+) []rotator.BannerScore {
+	cViews, cRewards := b.count(views, rewards)
+
+	var totalViews int64
+	for _, banner := range banners {
+		totalViews += cViews[banner.ID] + priors[banner.ID].Views
 	}
 
+	scores := make([]rotator.BannerScore, 0, len(banners))
 	for _, banner := range banners {
-		if cachedViews[banner.ID] == 0 {
-			return nil, nil, ErrBannerWithoutViews
+		score := rotator.BannerScore{
+			Banner:   banner,
+			Views:    cViews[banner.ID],
+			Rewarded: cRewards[banner.ID].count,
+			Rewards:  cRewards[banner.ID].sum,
+			Prior:    priors[banner.ID],
 		}
+
+		v := float64(score.Views + score.Prior.Views)
+		r := score.Rewards + score.Prior.Rewards
+		if v > 0 {
+			score.Reward = r / v
+		}
+		score.Bonus = b.explorationBonus(v, float64(totalViews))
+		score.Score = b.bannerScore(v, r, float64(totalViews))
+		scores = append(scores, score)
+	}
+
+	return scores
+}
+
+// bannerScore is the average reward of a view plus the exploration bonus,
+// the rewards are in [0, 1] like the clicks. It is infinite for a banner
+// without views.
+func (b *Bandit) bannerScore(views float64, rewards float64, totalViews float64) float64 {
+	if views <= 0 {
+		return math.Inf(1)
 	}
 
-	return cachedViews, cachedClicks, nil
+	averageReward := rewards / views
+	banditRate := b.explorationBonus(views, totalViews)
+
+	return averageReward + banditRate
 }
 
-func (b *Bandit) bannerScore(views float64, clicks float64, totalViews float64) float64 {
-	clickViewRate := clicks / views
-	banditRate := math.Sqrt(2 * math.Log(totalViews) / views)
+// explorationBonus is sqrt(2 * ln(n) / views). The total number of views n is
+// at least the views of the banner, so the bonus of a viewed banner is
+// finite and not negative, e.g. zero for the single view of a slot.
+func (b *Bandit) explorationBonus(views float64, totalViews float64) float64 {
+	if views <= 0 {
+		return math.Inf(1)
+	}
 
-	return clickViewRate + banditRate
+	n := math.Max(totalViews, views)
+
+	return math.Sqrt(2 * math.Log(n) / views)
 }
 
-func (b *Bandit) topBanners(scores map[int64]scoresItem) []storage.Banner {
-	max := 0.0
-	for _, item := range scores {
-		if item.score > max {
-			max = item.score
+// topBanners returns the banners with the top score ordered by ID, so the
+// ties are broken the same way for the seeded source.
+func (b *Bandit) topBanners(scores []rotator.BannerScore) []storage.Banner {
+	max := math.Inf(-1)
+	for _, score := range scores {
+		if score.Score > max {
+			max = score.Score
 		}
 	}
 
 	var banners []storage.Banner
-	for _, item := range scores {
-		if item.score == max {
-			banners = append(banners, item.banner)
+	for _, score := range scores {
+		if score.Score == max {
+			banners = append(banners, score.Banner)
 		}
 	}
 
+	sort.Slice(banners, func(i, j int) bool {
+		return banners[i].ID < banners[j].ID
+	})
+
 	return banners
 }
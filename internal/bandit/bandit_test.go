@@ -1,10 +1,15 @@
 package bandit
 
 import (
+	"banners-rotator/internal/rotator"
 	"banners-rotator/internal/storage"
 	"fmt"
 	"math"
+	"math/rand"
+	"reflect"
+	"sync"
 	"testing"
+	"testing/quick"
 	"time"
 
 	"github.com/stretchr/testify/require"
@@ -28,41 +33,113 @@ func TestBandit_RandomBanner(t *testing.T) {
 	})
 }
 
+func TestBandit_Seed(t *testing.T) {
+	t.Run("same seed, same choices", func(t *testing.T) {
+		first := NewBandit(WithSeed(42))
+		second := NewBandit(WithSeed(42))
+		banners := getBanners(10)
+		// every banner has the same score, the choice is a tie-break
+		views := getViews(banners)
+		rewards := getRewards(banners)
+
+		for i := 0; i < 100; i++ {
+			a, err := first.RandomBanner(banners)
+			require.NoError(t, err)
+			b, err := second.RandomBanner(banners)
+			require.NoError(t, err)
+			require.Equal(t, a.ID, b.ID)
+
+			a, err = first.TopRatedBanner(banners, views, rewards, nil)
+			require.NoError(t, err)
+			b, err = second.TopRatedBanner(banners, views, rewards, nil)
+			require.NoError(t, err)
+			require.Equal(t, a.ID, b.ID)
+		}
+	})
+
+	t.Run("concurrent use", func(t *testing.T) {
+		bnd := NewBandit(WithSeed(1))
+		banners := getBanners(3)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					_, _ = bnd.RandomBanner(banners)
+				}
+			}()
+		}
+		wg.Wait()
+	})
+}
+
 func TestBandit_TopRatedBanner(t *testing.T) {
 	bnd := NewBandit()
 
 	t.Run("top rated banner", func(t *testing.T) {
 		banners := getBanners(3)
 		views := getViews(banners)
-		clicks := getClicks(banners)
-		clicks = append(clicks, clicks[len(clicks)-1])
+		rewards := getRewards(banners)
+		rewards = append(rewards, rewards[len(rewards)-1])
 
-		banner, err := bnd.TopRatedBanner(banners, views, clicks)
+		banner, err := bnd.TopRatedBanner(banners, views, rewards, nil)
 		require.NoError(t, err)
-		require.Equal(t, clicks[len(clicks)-1].BannerID, banner.ID)
+		require.Equal(t, rewards[len(rewards)-1].BannerID, banner.ID)
 	})
-}
 
-func TestBandit_prepare(t *testing.T) {
-	bnd := &Bandit{}
-
-	t.Run("prepare", func(t *testing.T) {
+	t.Run("top rated banner by real-valued rewards", func(t *testing.T) {
 		banners := getBanners(3)
 		views := getViews(banners)
-		clicks := getClicks(banners)
-		cViews, cClicks, err := bnd.prepare(banners, views, clicks)
+		rewards := []rotator.Reward{
+			{BannerID: 1, Value: 0.2},
+			{BannerID: 1, Value: 0.2},
+			{BannerID: 2, Value: 0.9},
+			{BannerID: 3, Value: 0.1},
+		}
+
+		banner, err := bnd.TopRatedBanner(banners, views, rewards, nil)
 		require.NoError(t, err)
-		require.Len(t, cViews, len(banners))
-		require.Len(t, cClicks, len(banners))
+		require.Equal(t, int64(2), banner.ID)
 	})
 
-	t.Run("prepare with banner without views", func(t *testing.T) {
+	t.Run("top rated banner without rewards", func(t *testing.T) {
 		banners := getBanners(3)
-		views := getViews(banners)
-		views = views[1:]
-		clicks := getClicks(banners)
-		_, _, err := bnd.prepare(banners, views, clicks)
-		require.ErrorIs(t, err, ErrBannerWithoutViews, "actual error is %s", err)
+		views := append(getViews(banners), getViews(banners[:2])...)
+
+		banner, err := bnd.TopRatedBanner(banners, views, nil, nil)
+		require.NoError(t, err)
+		require.Equal(t, int64(3), banner.ID)
+	})
+
+	t.Run("banner without views first", func(t *testing.T) {
+		banners := getBanners(3)
+		views := getViews(banners[:2])
+		rewards := getRewards(banners[:2])
+
+		banner, err := bnd.TopRatedBanner(banners, views, rewards, nil)
+		require.NoError(t, err)
+		require.Equal(t, int64(3), banner.ID)
+	})
+
+	t.Run("banner with prior", func(t *testing.T) {
+		banners := getBanners(3)
+		var views []storage.ViewEvent
+		for i := 0; i < 10; i++ {
+			views = append(views, getViews(banners[:2])...)
+		}
+		rewards := getRewards(banners[:2])
+		priors := map[int64]storage.Prior{3: {Views: 100, Rewards: 1}}
+
+		banner, err := bnd.TopRatedBanner(banners, views, rewards, priors)
+		require.NoError(t, err)
+		require.NotEqual(t, int64(3), banner.ID)
+
+		priors[3] = storage.Prior{Views: 100, Rewards: 90}
+		banner, err = bnd.TopRatedBanner(banners, views, rewards, priors)
+		require.NoError(t, err)
+		require.Equal(t, int64(3), banner.ID)
 	})
 }
 
@@ -85,32 +162,207 @@ func TestBandit_bannerScore(t *testing.T) {
 	})
 }
 
+func TestBandit_Score(t *testing.T) {
+	bnd := &Bandit{}
+
+	t.Run("score", func(t *testing.T) {
+		require.Equal(t, bnd.bannerScore(10, 2, 100), bnd.Score(10, 2, 100))
+	})
+
+	t.Run("score without views", func(t *testing.T) {
+		require.True(t, math.IsInf(bnd.Score(0, 0, 100), 1))
+	})
+
+	t.Run("score without rewards", func(t *testing.T) {
+		require.Equal(t, math.Sqrt(2*math.Log(100)/10), bnd.Score(10, 0, 100))
+	})
+
+	t.Run("single view", func(t *testing.T) {
+		require.Zero(t, bnd.Score(1, 0, 1))
+		require.Equal(t, 1.0, bnd.Score(1, 1, 1))
+	})
+
+	t.Run("total views below views", func(t *testing.T) {
+		require.Equal(t, bnd.Score(10, 2, 10), bnd.Score(10, 2, 0))
+	})
+}
+
+func TestBandit_Scores(t *testing.T) {
+	bnd := &Bandit{}
+
+	t.Run("scores", func(t *testing.T) {
+		banners := getBanners(3)
+		views := getViews(banners[:2])
+		views = append(views, views[0])
+		rewards := getRewards(banners[:1])
+
+		scores := bnd.Scores(banners, views, rewards, nil)
+		require.Len(t, scores, 3)
+
+		require.Equal(t, banners[0], scores[0].Banner)
+		require.Equal(t, int64(2), scores[0].Views)
+		require.Equal(t, int64(1), scores[0].Rewarded)
+		require.Equal(t, 1.0, scores[0].Rewards)
+		require.Equal(t, 0.5, scores[0].Reward)
+		require.Equal(t, bnd.explorationBonus(2, 3), scores[0].Bonus)
+		require.Equal(t, bnd.bannerScore(2, 1, 3), scores[0].Score)
+
+		require.Zero(t, scores[1].Reward)
+		require.True(t, math.IsInf(scores[2].Bonus, 1))
+		require.True(t, math.IsInf(scores[2].Score, 1))
+	})
+
+	t.Run("scores with prior", func(t *testing.T) {
+		banners := getBanners(2)
+		views := getViews(banners[:1])
+		rewards := getRewards(banners[:1])
+		priors := map[int64]storage.Prior{2: {Views: 10, Rewards: 4}}
+
+		scores := bnd.Scores(banners, views, rewards, priors)
+		require.Len(t, scores, 2)
+
+		require.Zero(t, scores[1].Views)
+		require.Equal(t, priors[2], scores[1].Prior)
+		require.Equal(t, 0.4, scores[1].Reward)
+		require.Equal(t, bnd.bannerScore(10, 4, 11), scores[1].Score)
+	})
+}
+
 func TestBandit_topBanners(t *testing.T) {
 	bnd := &Bandit{}
 
 	t.Run("top banners", func(t *testing.T) {
 		banners := getBanners(3)
 		views := getViews(banners)
-		clicks := getClicks(banners)
-		clicks = append(clicks, clicks[len(clicks)-1])
-		cViews, cClicks, err := bnd.prepare(banners, views, clicks)
-		require.NoError(t, err)
+		rewards := getRewards(banners)
+		rewards = append(rewards, rewards[len(rewards)-1])
+
+		top := bnd.topBanners(bnd.Scores(banners, views, rewards, nil))
+		require.Len(t, top, 1)
+		require.Equal(t, rewards[len(rewards)-1].BannerID, top[0].ID)
+	})
+
+	t.Run("ties ordered by id", func(t *testing.T) {
+		banners := getBanners(3)
+		scores := bnd.Scores([]storage.Banner{banners[2], banners[0], banners[1]}, nil, nil, nil)
 
-		scores := make(map[int64]scoresItem)
-		for _, banner := range banners {
-			scores[banner.ID] = scoresItem{
-				banner,
-				bnd.bannerScore(
-					float64(cViews[banner.ID]),
-					float64(cClicks[banner.ID]),
-					float64(len(cClicks)),
-				),
+		require.Equal(t, banners, bnd.topBanners(scores))
+	})
+}
+
+// randomSlot is a slot with up to 8 banners, some of them with views,
+// rewards and priors.
+type randomSlot struct {
+	banners []storage.Banner
+	views   []storage.ViewEvent
+	rewards []rotator.Reward
+	priors  map[int64]storage.Prior
+}
+
+func (randomSlot) Generate(rnd *rand.Rand, _ int) reflect.Value {
+	slot := randomSlot{banners: getBanners(1 + rnd.Intn(8)), priors: make(map[int64]storage.Prior)}
+	for _, banner := range slot.banners {
+		views := rnd.Intn(3) * rnd.Intn(50)
+		for i := 0; i < views; i++ {
+			slot.views = append(slot.views, storage.ViewEvent{BannerID: banner.ID})
+			if rnd.Intn(4) == 0 {
+				slot.rewards = append(slot.rewards, rotator.Reward{BannerID: banner.ID, Value: rnd.Float64()})
 			}
 		}
+		if rnd.Intn(4) == 0 {
+			prior := storage.Prior{Views: int64(rnd.Intn(100))}
+			prior.Rewards = rnd.Float64() * float64(prior.Views)
+			slot.priors[banner.ID] = prior
+		}
+	}
 
-		top := bnd.topBanners(scores)
-		require.Len(t, top, 1)
-		require.Equal(t, clicks[len(clicks)-1].BannerID, top[0].ID)
+	return reflect.ValueOf(slot)
+}
+
+func TestBandit_Properties(t *testing.T) {
+	bnd := NewBandit(WithSeed(1))
+	config := &quick.Config{MaxCount: 500, Rand: rand.New(rand.NewSource(1))}
+
+	t.Run("scores are finite for viewed banners", func(t *testing.T) {
+		property := func(slot randomSlot) bool {
+			for _, score := range bnd.Scores(slot.banners, slot.views, slot.rewards, slot.priors) {
+				viewed := score.Views+score.Prior.Views > 0
+				if math.IsNaN(score.Score) || score.Score < 0 || viewed == math.IsInf(score.Score, 1) {
+					return false
+				}
+			}
+
+			return true
+		}
+		require.NoError(t, quick.Check(property, config))
+	})
+
+	t.Run("top rated banner is always chosen", func(t *testing.T) {
+		property := func(slot randomSlot) bool {
+			banner, err := bnd.TopRatedBanner(slot.banners, slot.views, slot.rewards, slot.priors)
+
+			return err == nil && banner != nil
+		}
+		require.NoError(t, quick.Check(property, config))
+	})
+
+	t.Run("every banner is explored", func(t *testing.T) {
+		property := func(count uint8, seed int64) bool {
+			rnd := rand.New(rand.NewSource(seed))
+			banners := getBanners(1 + int(count)%8)
+			ctr := make(map[int64]float64, len(banners))
+			for _, banner := range banners {
+				ctr[banner.ID] = rnd.Float64()
+			}
+
+			var views []storage.ViewEvent
+			var rewards []rotator.Reward
+			shown := make(map[int64]int)
+			for step := 0; step < 20*len(banners); step++ {
+				banner, err := bnd.TopRatedBanner(banners, views, rewards, nil)
+				if err != nil {
+					return false
+				}
+				if step < len(banners) && shown[banner.ID] > 0 {
+					// the banners without views go first
+					return false
+				}
+				shown[banner.ID]++
+				views = append(views, storage.ViewEvent{BannerID: banner.ID})
+				if rnd.Float64() < ctr[banner.ID] {
+					rewards = append(rewards, rotator.Reward{BannerID: banner.ID, Value: 1})
+				}
+			}
+
+			return len(shown) == len(banners)
+		}
+		require.NoError(t, quick.Check(property, config))
+	})
+
+	t.Run("no rewards rotate evenly", func(t *testing.T) {
+		property := func(count uint8) bool {
+			banners := getBanners(1 + int(count)%8)
+
+			var views []storage.ViewEvent
+			shown := make(map[int64]int)
+			for step := 0; step < 10*len(banners); step++ {
+				banner, err := bnd.TopRatedBanner(banners, views, nil, nil)
+				if err != nil {
+					return false
+				}
+				shown[banner.ID]++
+				views = append(views, storage.ViewEvent{BannerID: banner.ID})
+			}
+
+			for _, banner := range banners {
+				if shown[banner.ID] != 10 {
+					return false
+				}
+			}
+
+			return true
+		}
+		require.NoError(t, quick.Check(property, config))
 	})
 }
 
@@ -142,17 +394,12 @@ func getViews(banners []storage.Banner) []storage.ViewEvent {
 	return views
 }
 
-func getClicks(banners []storage.Banner) []storage.ClickEvent {
-	var clicks []storage.ClickEvent
+func getRewards(banners []storage.Banner) []rotator.Reward {
+	var rewards []rotator.Reward
 
-	for i, banner := range banners {
-		clicks = append(clicks, storage.ClickEvent{
-			SlotID:   int64(i + 1),
-			BannerID: banner.ID,
-			GroupID:  int64(i + 1),
-			Date:     time.Now().Unix(),
-		})
+	for _, banner := range banners {
+		rewards = append(rewards, rotator.Reward{BannerID: banner.ID, Value: 1})
 	}
 
-	return clicks
+	return rewards
 }
@@ -3,6 +3,5 @@ package bandit
 import "errors"
 
 var (
-	ErrEmptyBanners       = errors.New("empty banners")
-	ErrBannerWithoutViews = errors.New("banner without views")
+	ErrEmptyBanners = errors.New("empty banners")
 )
@@ -0,0 +1,265 @@
+// Package linucb implements the LinUCB contextual bandit with disjoint models.
+package linucb
+
+import (
+	"banners-rotator/internal/bandit"
+	"banners-rotator/internal/rotator"
+	"banners-rotator/internal/storage"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	defaultAlpha     = 1.0
+	defaultDimension = 32
+)
+
+var ErrInvalidState = errors.New("invalid model state")
+
+// Bandit scores a banner by the predicted reward plus alpha deviations.
+type Bandit struct {
+	alpha     float64
+	dimension int
+	logger    rotator.Logger
+	mu        sync.Mutex
+	rnd       *rand.Rand
+}
+
+type Option func(b *Bandit)
+
+// WithAlpha sets the weight of the exploration term.
+func WithAlpha(alpha float64) Option {
+	return func(b *Bandit) {
+		b.alpha = alpha
+	}
+}
+
+// WithDimension sets the length of the hashed feature vector.
+func WithDimension(dimension int) Option {
+	return func(b *Bandit) {
+		b.dimension = dimension
+	}
+}
+
+// WithLogger reports the discarded models to the logger.
+func WithLogger(logger rotator.Logger) Option {
+	return func(b *Bandit) {
+		b.logger = logger
+	}
+}
+
+// WithSeed seeds the source the ties are broken with.
+func WithSeed(seed int64) Option {
+	return func(b *Bandit) {
+		b.rnd = rand.New(rand.NewSource(seed))
+	}
+}
+
+func NewBandit(opts ...Option) rotator.ContextualBandit {
+	b := &Bandit{alpha: defaultAlpha, dimension: defaultDimension}
+	for _, opt := range opts {
+		opt(b)
+	}
+	if b.dimension < 2 {
+		b.dimension = 2
+	}
+	if b.rnd == nil {
+		b.rnd = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	return b
+}
+
+// arm is the model of a banner: the inverse of A = I + sum(x * x^T) in row
+// major order and b = sum(reward * x).
+type arm struct {
+	AInv []float64 `json:"aInv"`
+	B    []float64 `json:"b"`
+}
+
+type state struct {
+	Dimension int            `json:"dimension"`
+	Arms      map[int64]*arm `json:"arms"`
+}
+
+func (b *Bandit) ChooseBanner(
+	banners []storage.Banner,
+	features rotator.Features,
+	data []byte,
+) (*storage.Banner, error) {
+	if len(banners) == 0 {
+		return nil, bandit.ErrEmptyBanners
+	}
+
+	s, err := b.decode(data)
+	if err != nil {
+		return nil, err
+	}
+	x := b.vector(features)
+
+	best := math.Inf(-1)
+	var top []int
+	for i, banner := range banners {
+		score := b.score(s.arm(banner.ID), x)
+		switch {
+		case score > best:
+			best = score
+			top = append(top[:0], i)
+		case score == best:
+			top = append(top, i)
+		}
+	}
+
+	return &banners[top[b.intn(len(top))]], nil
+}
+
+func (b *Bandit) View(data []byte, bannerID int64, features rotator.Features) ([]byte, error) {
+	s, err := b.decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	a := s.arm(bannerID)
+	x := b.vector(features)
+	d := s.Dimension
+
+	// Sherman-Morrison: (A + x*x^T)^-1 = A^-1 - (A^-1*x)(x^T*A^-1) / (1 + x^T*A^-1*x),
+	// A^-1 is symmetric, so x^T*A^-1 = (A^-1*x)^T.
+	ax := mulVec(a.AInv, x, d)
+	denom := 1 + dot(x, ax)
+	for i := 0; i < d; i++ {
+		for j := 0; j < d; j++ {
+			a.AInv[i*d+j] -= ax[i] * ax[j] / denom
+		}
+	}
+	s.Arms[bannerID] = a
+
+	return encode(s)
+}
+
+func (b *Bandit) Reward(data []byte, bannerID int64, features rotator.Features, reward float64) ([]byte, error) {
+	s, err := b.decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	a := s.arm(bannerID)
+	for i, v := range b.vector(features) {
+		a.B[i] += reward * v
+	}
+	s.Arms[bannerID] = a
+
+	return encode(s)
+}
+
+// score is theta^T * x + alpha * sqrt(x^T * A^-1 * x) with theta = A^-1 * b.
+func (b *Bandit) score(a *arm, x []float64) float64 {
+	d := len(x)
+	theta := mulVec(a.AInv, a.B, d)
+	variance := dot(x, mulVec(a.AInv, x, d))
+
+	return dot(theta, x) + b.alpha*math.Sqrt(math.Max(variance, 0))
+}
+
+// vector hashes the features into the coordinates after the bias.
+func (b *Bandit) vector(features rotator.Features) []float64 {
+	x := make([]float64, b.dimension)
+	x[0] = 1
+	for key, value := range features {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(key + "=" + value))
+		x[1+int(h.Sum32()%uint32(b.dimension-1))]++
+	}
+
+	return x
+}
+
+func (b *Bandit) intn(n int) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.rnd.Intn(n)
+}
+
+// decode restores the state, a state of another dimension is discarded.
+func (b *Bandit) decode(data []byte) (*state, error) {
+	s := &state{Dimension: b.dimension, Arms: make(map[int64]*arm)}
+	if len(data) == 0 {
+		return s, nil
+	}
+
+	var saved state
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("linucb -> %w (%s)", ErrInvalidState, err)
+	}
+	if saved.Dimension != b.dimension {
+		if b.logger != nil {
+			b.logger.Warn("linucb -> model of another dimension discarded",
+				"saved", saved.Dimension,
+				"dimension", b.dimension,
+			)
+		}
+
+		return s, nil
+	}
+
+	d := saved.Dimension
+	for id, a := range saved.Arms {
+		if a == nil || len(a.AInv) != d*d || len(a.B) != d {
+			return nil, fmt.Errorf("linucb -> %w (banner %d)", ErrInvalidState, id)
+		}
+		s.Arms[id] = a
+	}
+
+	return s, nil
+}
+
+func encode(s *state) ([]byte, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, fmt.Errorf("linucb -> %w", err)
+	}
+
+	return data, nil
+}
+
+// arm returns the model of the banner, an empty one for a new banner.
+func (s *state) arm(bannerID int64) *arm {
+	if a, ok := s.Arms[bannerID]; ok {
+		return a
+	}
+
+	d := s.Dimension
+	a := &arm{AInv: make([]float64, d*d), B: make([]float64, d)}
+	for i := 0; i < d; i++ {
+		a.AInv[i*d+i] = 1
+	}
+
+	return a
+}
+
+func mulVec(m, v []float64, d int) []float64 {
+	r := make([]float64, d)
+	for i := 0; i < d; i++ {
+		for j := 0; j < d; j++ {
+			r[i] += m[i*d+j] * v[j]
+		}
+	}
+
+	return r
+}
+
+func dot(a, b []float64) float64 {
+	var r float64
+	for i := range a {
+		r += a[i] * b[i]
+	}
+
+	return r
+}
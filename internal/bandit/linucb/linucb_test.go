@@ -0,0 +1,123 @@
+package linucb
+
+import (
+	"banners-rotator/internal/bandit"
+	"banners-rotator/internal/rotator"
+	"banners-rotator/internal/storage"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var banners = []storage.Banner{{ID: 1}, {ID: 2}, {ID: 3}}
+
+func TestBandit_ChooseBanner(t *testing.T) {
+	t.Run("without banners", func(t *testing.T) {
+		_, err := NewBandit().ChooseBanner(nil, rotator.Features{"device": "mobile"}, nil)
+		require.ErrorIs(t, err, bandit.ErrEmptyBanners)
+	})
+
+	t.Run("new banners tie", func(t *testing.T) {
+		b := NewBandit(WithSeed(1))
+		chosen := make(map[int64]struct{})
+		for i := 0; i < 50; i++ {
+			banner, err := b.ChooseBanner(banners, rotator.Features{"device": "mobile"}, nil)
+			require.NoError(t, err)
+			chosen[banner.ID] = struct{}{}
+		}
+		require.Len(t, chosen, len(banners))
+	})
+
+	t.Run("learns the best banner per context", func(t *testing.T) {
+		b := NewBandit(WithSeed(1), WithAlpha(0.5), WithDimension(8))
+		mobile := rotator.Features{"device": "mobile"}
+		desktop := rotator.Features{"device": "desktop"}
+		best := map[string]int64{"mobile": 1, "desktop": 3}
+
+		var state []byte
+		for i := 0; i < 400; i++ {
+			features := mobile
+			if i%2 == 1 {
+				features = desktop
+			}
+
+			banner, err := b.ChooseBanner(banners, features, state)
+			require.NoError(t, err)
+			state, err = b.View(state, banner.ID, features)
+			require.NoError(t, err)
+			if banner.ID == best[features["device"]] {
+				state, err = b.Reward(state, banner.ID, features, 1)
+				require.NoError(t, err)
+			}
+		}
+
+		banner, err := b.ChooseBanner(banners, mobile, state)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), banner.ID)
+		banner, err = b.ChooseBanner(banners, desktop, state)
+		require.NoError(t, err)
+		require.Equal(t, int64(3), banner.ID)
+	})
+}
+
+func TestBandit_View(t *testing.T) {
+	b := NewBandit(WithDimension(4)).(*Bandit)
+	features := rotator.Features{"device": "mobile", "country": "ru"}
+
+	data, err := b.View(nil, 1, features)
+	require.NoError(t, err)
+	data, err = b.View(data, 1, features)
+	require.NoError(t, err)
+
+	s, err := b.decode(data)
+	require.NoError(t, err)
+
+	// A = I + 2 * x * x^T, A^-1 * A is the identity
+	x := b.vector(features)
+	d := len(x)
+	for i := 0; i < d; i++ {
+		for j := 0; j < d; j++ {
+			var v float64
+			for k := 0; k < d; k++ {
+				a := 2 * x[k] * x[j]
+				if k == j {
+					a++
+				}
+				v += s.Arms[1].AInv[i*d+k] * a
+			}
+
+			expected := 0.0
+			if i == j {
+				expected = 1
+			}
+			require.InDelta(t, expected, v, 1e-9)
+		}
+	}
+}
+
+func TestBandit_State(t *testing.T) {
+	features := rotator.Features{"device": "mobile"}
+
+	t.Run("another dimension", func(t *testing.T) {
+		data, err := NewBandit(WithDimension(4)).Reward(nil, 1, features, 1)
+		require.NoError(t, err)
+
+		data, err = NewBandit(WithDimension(8)).Reward(data, 2, features, 1)
+		require.NoError(t, err)
+
+		var s state
+		require.NoError(t, json.Unmarshal(data, &s))
+		require.Equal(t, 8, s.Dimension)
+		require.Len(t, s.Arms, 1)
+		require.Contains(t, s.Arms, int64(2))
+	})
+
+	t.Run("invalid state", func(t *testing.T) {
+		_, err := NewBandit().ChooseBanner(banners, features, []byte("not json"))
+		require.ErrorIs(t, err, ErrInvalidState)
+
+		_, err = NewBandit(WithDimension(4)).View([]byte(`{"dimension":4,"arms":{"1":{"aInv":[1],"b":[0]}}}`), 1, features)
+		require.ErrorIs(t, err, ErrInvalidState)
+	})
+}
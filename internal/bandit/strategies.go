@@ -0,0 +1,24 @@
+package bandit
+
+import (
+	"banners-rotator/internal/rotator"
+	"sort"
+)
+
+// Strategies builds the bandits by name for the offline tools, the seed
+// makes the choices reproducible.
+var Strategies = map[string]func(seed int64) rotator.Bandit{
+	"ucb1": func(seed int64) rotator.Bandit {
+		return NewBandit(WithSeed(seed))
+	},
+}
+
+func StrategyNames() []string {
+	names := make([]string, 0, len(Strategies))
+	for name := range Strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -5,15 +5,21 @@ import (
 	"fmt"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 type AppConfig struct {
-	Logger  LoggerConf  `yaml:"logger"`
-	Api     ApiConf     `yaml:"api"`
-	Storage StorageConf `yaml:"storage"`
-	Rmq     RmqConf     `yaml:"rmq"`
+	Logger     LoggerConf     `yaml:"logger"`
+	Api        ApiConf        `yaml:"api"`
+	Storage    StorageConf    `yaml:"storage"`
+	Bandit     BanditConf     `yaml:"bandit"`
+	Rmq        RmqConf        `yaml:"rmq"`
+	Publisher  PublisherConf  `yaml:"publisher"`
+	Pruning    PruningConf    `yaml:"pruning"`
+	Metrics    MetricsConf    `yaml:"metrics"`
+	Aggregator AggregatorConf `yaml:"aggregator"`
 }
 
 type LoggerConf struct {
@@ -26,19 +32,138 @@ type ApiConf struct {
 }
 
 type StorageConf struct {
-	Type             string `yaml:"type"`
-	ConnectionString string `yaml:"connectionString"`
+	Type             string           `yaml:"type"`
+	ConnectionString string           `yaml:"connectionString"`
+	Path             string           `yaml:"path"`
+	Migrate          bool             `yaml:"migrate"`
+	Batch            StorageBatchConf `yaml:"batch"`
+	Partitions       PartitionsConf   `yaml:"partitions"`
+	Pool             PoolConf         `yaml:"pool"`
+	Replica          ReplicaConf      `yaml:"replica"`
+}
+
+type PoolConf struct {
+	MaxOpenConns    int           `yaml:"maxOpenConns"`
+	MaxIdleConns    int           `yaml:"maxIdleConns"`
+	ConnMaxLifetime time.Duration `yaml:"connMaxLifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"connMaxIdleTime"`
+}
+
+type ReplicaConf struct {
+	ConnectionString string        `yaml:"connectionString"`
+	CheckInterval    time.Duration `yaml:"checkInterval"`
+}
+
+type StorageBatchConf struct {
+	Size          int           `yaml:"size"`
+	FlushInterval time.Duration `yaml:"flushInterval"`
+}
+
+type PartitionsConf struct {
+	Enabled       bool          `yaml:"enabled"`
+	Interval      string        `yaml:"interval"`
+	Ahead         int           `yaml:"ahead"`
+	Retention     time.Duration `yaml:"retention"`
+	CheckInterval time.Duration `yaml:"checkInterval"`
+}
+
+type BanditConf struct {
+	Seed          int64          `yaml:"seed"`
+	GroupMinViews int64          `yaml:"groupMinViews"`
+	Contextual    ContextualConf `yaml:"contextual"`
+}
+
+type ContextualConf struct {
+	Enabled       bool          `yaml:"enabled"`
+	Alpha         float64       `yaml:"alpha"`
+	Dimension     int           `yaml:"dimension"`
+	FlushInterval time.Duration `yaml:"flushInterval"`
 }
 
 type RmqConf struct {
-	Uri  string `yaml:"uri"`
-	Name string `yaml:"name"`
+	Uri      string `yaml:"uri"`
+	Name     string `yaml:"name"`
+	Prefetch int    `yaml:"prefetch"`
+	Encoding string `yaml:"encoding"`
+	Instance string `yaml:"instance"`
+}
+
+type PublisherConf struct {
+	Type  string    `yaml:"type"`
+	Path  string    `yaml:"path"`
+	Batch BatchConf `yaml:"batch"`
+}
+
+type BatchConf struct {
+	Enabled         bool          `yaml:"enabled"`
+	Workers         int           `yaml:"workers"`
+	QueueSize       int           `yaml:"queueSize"`
+	Size            int           `yaml:"size"`
+	FlushInterval   time.Duration `yaml:"flushInterval"`
+	Policy          string        `yaml:"policy"`
+	SpillPath       string        `yaml:"spillPath"`
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout"`
+}
+
+// PruningConf sets how often the slots with an enabled pruning policy are
+// pruned, zero interval disables the automatic pruning.
+type PruningConf struct {
+	Interval time.Duration `yaml:"interval"`
+}
+
+// AggregatorConf sets how long the IDs of the processed messages are kept to
+// detect the redelivered messages and how often the older ones are deleted.
+type AggregatorConf struct {
+	ProcessedRetention time.Duration `yaml:"processedRetention"`
+	PurgeInterval      time.Duration `yaml:"purgeInterval"`
+	ReconnectDelay     time.Duration `yaml:"reconnectDelay"`
+}
+
+// MetricsConf sets the address of the HTTP server of the /metrics endpoint
+// and the slots labelled in the selection metrics.
+type MetricsConf struct {
+	Enabled bool    `yaml:"enabled"`
+	Host    string  `yaml:"host"`
+	Port    string  `yaml:"port"`
+	Slots   []int64 `yaml:"slots"`
 }
 
 var ErrUnreadableConfig = errors.New("unreadable config")
 
 func init() {
 	viper.SetDefault("logger.level", "info")
+	viper.SetDefault("storage.type", "sql")
+	viper.SetDefault("storage.path", "rotator.db")
+	viper.SetDefault("storage.batch.flushInterval", time.Second)
+	viper.SetDefault("storage.partitions.interval", "daily")
+	viper.SetDefault("storage.partitions.ahead", 7)
+	viper.SetDefault("storage.partitions.checkInterval", time.Hour)
+	viper.SetDefault("storage.pool.maxOpenConns", 20)
+	viper.SetDefault("storage.pool.maxIdleConns", 5)
+	viper.SetDefault("storage.pool.connMaxLifetime", 30*time.Minute)
+	viper.SetDefault("storage.pool.connMaxIdleTime", 5*time.Minute)
+	viper.SetDefault("storage.replica.checkInterval", 5*time.Second)
+	viper.SetDefault("bandit.groupMinViews", 1000)
+	viper.SetDefault("bandit.contextual.alpha", 1.0)
+	viper.SetDefault("bandit.contextual.dimension", 32)
+	viper.SetDefault("bandit.contextual.flushInterval", time.Second)
+	viper.SetDefault("rmq.prefetch", 50)
+	viper.SetDefault("rmq.encoding", "json")
+	viper.SetDefault("publisher.type", "rmq")
+	viper.SetDefault("publisher.path", "events.jsonl")
+	viper.SetDefault("publisher.batch.workers", 2)
+	viper.SetDefault("publisher.batch.queueSize", 10000)
+	viper.SetDefault("publisher.batch.size", 100)
+	viper.SetDefault("publisher.batch.flushInterval", time.Second)
+	viper.SetDefault("publisher.batch.policy", "block")
+	viper.SetDefault("publisher.batch.spillPath", "events.spill.jsonl")
+	viper.SetDefault("publisher.batch.shutdownTimeout", 10*time.Second)
+	viper.SetDefault("pruning.interval", time.Minute)
+	viper.SetDefault("aggregator.processedRetention", 7*24*time.Hour)
+	viper.SetDefault("aggregator.purgeInterval", time.Hour)
+	viper.SetDefault("aggregator.reconnectDelay", 5*time.Second)
+	viper.SetDefault("metrics.enabled", false)
+	viper.SetDefault("metrics.port", "9090")
 }
 
 func NewAppConfig(path string) (*AppConfig, error) {
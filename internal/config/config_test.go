@@ -2,6 +2,7 @@ package config
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -18,6 +19,16 @@ func TestConfig(t *testing.T) {
 		cfg, err := NewAppConfig("./config_test_err.yaml")
 		require.NoError(t, err)
 		require.Equal(t, "info", cfg.Logger.Level)
+		require.Equal(t, "rmq", cfg.Publisher.Type)
+		require.False(t, cfg.Publisher.Batch.Enabled)
+		require.Equal(t, time.Second, cfg.Publisher.Batch.FlushInterval)
+		require.Equal(t, time.Minute, cfg.Pruning.Interval)
+		require.Equal(t, 7*24*time.Hour, cfg.Aggregator.ProcessedRetention)
+		require.Equal(t, 5*time.Second, cfg.Aggregator.ReconnectDelay)
+		require.Equal(t, int64(1000), cfg.Bandit.GroupMinViews)
+		require.Equal(t, time.Second, cfg.Bandit.Contextual.FlushInterval)
+		require.False(t, cfg.Metrics.Enabled)
+		require.Equal(t, "9090", cfg.Metrics.Port)
 	})
 
 	t.Run("reading config error", func(t *testing.T) {
@@ -0,0 +1,197 @@
+package metrics
+
+import (
+	"banners-rotator/internal/publisher"
+	"context"
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+const namespace = "rotator"
+
+// OtherSlots is the slot label of the selections in the slots without their
+// own label.
+const OtherSlots = "other"
+
+// The results of the publishes.
+const (
+	PublishSuccess = "success"
+	PublishFailure = "failure"
+)
+
+// Metrics collects the metrics of the service in its own registry, it
+// observes the gRPC requests, the selected banners, the storage queries and
+// the published events.
+type Metrics struct {
+	registry       *prometheus.Registry
+	requests       *prometheus.CounterVec
+	requestLatency *prometheus.HistogramVec
+	selections     *prometheus.CounterVec
+	queryLatency   *prometheus.HistogramVec
+	publishes      *prometheus.CounterVec
+	slots          map[int64]string
+}
+
+type Option func(m *Metrics)
+
+// WithSlotLabels labels the banner selections of the slots by their IDs, the
+// selections of the other slots are counted as OtherSlots, so the number of
+// the series does not grow with the slots.
+func WithSlotLabels(slotIDs []int64) Option {
+	return func(m *Metrics) {
+		for _, id := range slotIDs {
+			m.slots[id] = strconv.FormatInt(id, 10)
+		}
+	}
+}
+
+func NewMetrics(opts ...Option) *Metrics {
+	m := &Metrics{
+		slots:    make(map[int64]string),
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "grpc_requests_total",
+			Help:      "Number of the gRPC requests by method and status code.",
+		}, []string{"method", "code"}),
+		requestLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "grpc_request_duration_seconds",
+			Help:      "Duration of the gRPC requests by method and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "code"}),
+		selections: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "banner_selections_total",
+			Help:      "Number of the selected banners by slot and selection path.",
+		}, []string{"slot", "path"}),
+		queryLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_query_duration_seconds",
+			Help:      "Duration of the storage queries by storage method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method"}),
+		publishes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rmq_publishes_total",
+			Help:      "Number of the events published to RabbitMQ by result.",
+		}, []string{"result"}),
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.requestLatency,
+		m.selections,
+		m.queryLatency,
+		m.publishes,
+	)
+
+	return m
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// UnaryServerInterceptor counts the requests and observes their duration by
+// the method name without the service and the status code of the response.
+func (m *Metrics) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		method := path.Base(info.FullMethod)
+		code := status.Code(err).String()
+		m.requests.WithLabelValues(method, code).Inc()
+		m.requestLatency.WithLabelValues(method, code).Observe(time.Since(start).Seconds())
+
+		return resp, err
+	}
+}
+
+// BatchStats reports the queue depth and the counters of a batch publisher.
+type BatchStats interface {
+	Stats() publisher.BatchStats
+}
+
+// ObserveBatchPublisher exposes the queue depth of the batch publisher as a
+// gauge and its published, dropped, spilled and failed events as counters,
+// they are read from the publisher on every scrape.
+func (m *Metrics) ObserveBatchPublisher(p BatchStats) {
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "batch_publisher_queue_depth",
+		Help:      "Number of the events waiting in the queue of the batch publisher.",
+	}, func() float64 {
+		return float64(p.Stats().QueueDepth)
+	}))
+
+	for result, count := range map[string]func(s publisher.BatchStats) uint64{
+		"published": func(s publisher.BatchStats) uint64 { return s.Published },
+		"dropped":   func(s publisher.BatchStats) uint64 { return s.Dropped },
+		"spilled":   func(s publisher.BatchStats) uint64 { return s.Spilled },
+		"failed":    func(s publisher.BatchStats) uint64 { return s.Failed },
+	} {
+		count := count
+		m.registry.MustRegister(prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        "batch_publisher_events_total",
+			Help:        "Number of the events of the batch publisher by result.",
+			ConstLabels: prometheus.Labels{"result": result},
+		}, func() float64 {
+			return float64(count(p.Stats()))
+		}))
+	}
+}
+
+// ObserveDroppedEvents exposes the buffered view and click events the storage
+// dropped for failing to be written, they are read on every scrape.
+func (m *Metrics) ObserveDroppedEvents(dropped func() uint64) {
+	m.registry.MustRegister(prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_dropped_events_total",
+		Help:      "Number of the buffered events dropped for failing to be written.",
+	}, func() float64 {
+		return float64(dropped())
+	}))
+}
+
+func (m *Metrics) ObserveSelection(slotID int64, path string) {
+	slot, ok := m.slots[slotID]
+	if !ok {
+		slot = OtherSlots
+	}
+	m.selections.WithLabelValues(slot, path).Inc()
+}
+
+func (m *Metrics) ObserveQuery(method string, duration time.Duration) {
+	m.queryLatency.WithLabelValues(method).Observe(duration.Seconds())
+}
+
+func (m *Metrics) ObservePublish(err error) {
+	result := PublishSuccess
+	if err != nil {
+		result = PublishFailure
+	}
+	m.publishes.WithLabelValues(result).Inc()
+}
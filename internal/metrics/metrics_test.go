@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"banners-rotator/internal/publisher"
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type testBatchStats publisher.BatchStats
+
+func (s testBatchStats) Stats() publisher.BatchStats {
+	return publisher.BatchStats(s)
+}
+
+func TestMetrics(t *testing.T) {
+	m := NewMetrics(WithSlotLabels([]int64{1}))
+
+	t.Run("grpc requests", func(t *testing.T) {
+		interceptor := m.UnaryServerInterceptor()
+		info := &grpc.UnaryServerInfo{FullMethod: "/rotator.BannersRotator/BannerForSlot"}
+
+		_, err := interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return "ok", nil
+		})
+		require.NoError(t, err)
+		_, err = interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, status.Error(codes.InvalidArgument, "bad request")
+		})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		require.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues("BannerForSlot", "OK")))
+		require.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues("BannerForSlot", "InvalidArgument")))
+		require.Equal(t, 2, testutil.CollectAndCount(m.requestLatency))
+	})
+
+	t.Run("banner selections", func(t *testing.T) {
+		m.ObserveSelection(1, "exploration")
+		m.ObserveSelection(1, "top_rated")
+		m.ObserveSelection(1, "top_rated")
+
+		require.Equal(t, 1.0, testutil.ToFloat64(m.selections.WithLabelValues("1", "exploration")))
+		require.Equal(t, 2.0, testutil.ToFloat64(m.selections.WithLabelValues("1", "top_rated")))
+
+		m.ObserveSelection(2, "top_rated")
+		m.ObserveSelection(3, "top_rated")
+		require.Equal(t, 2.0, testutil.ToFloat64(m.selections.WithLabelValues(OtherSlots, "top_rated")))
+	})
+
+	t.Run("storage queries", func(t *testing.T) {
+		m.ObserveQuery("SlotViews", 10*time.Millisecond)
+
+		require.Equal(t, 1, testutil.CollectAndCount(m.queryLatency))
+	})
+
+	t.Run("publishes", func(t *testing.T) {
+		m.ObservePublish(nil)
+		m.ObservePublish(errors.New("failed"))
+		m.ObservePublish(nil)
+
+		require.Equal(t, 2.0, testutil.ToFloat64(m.publishes.WithLabelValues(PublishSuccess)))
+		require.Equal(t, 1.0, testutil.ToFloat64(m.publishes.WithLabelValues(PublishFailure)))
+	})
+
+	t.Run("batch publisher", func(t *testing.T) {
+		m.ObserveBatchPublisher(testBatchStats{
+			QueueDepth: 3, Published: 10, Dropped: 1, Spilled: 2, Failed: 4,
+		})
+
+		count, err := testutil.GatherAndCount(
+			m.registry,
+			"rotator_batch_publisher_queue_depth",
+			"rotator_batch_publisher_events_total",
+		)
+		require.NoError(t, err)
+		require.Equal(t, 5, count)
+	})
+
+	t.Run("dropped events", func(t *testing.T) {
+		m.ObserveDroppedEvents(func() uint64 { return 7 })
+	})
+
+	t.Run("handler", func(t *testing.T) {
+		srv := httptest.NewServer(m.Handler())
+		defer srv.Close()
+
+		resp, err := srv.Client().Get(srv.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		for _, name := range []string{
+			`rotator_grpc_requests_total{code="OK",method="BannerForSlot"} 1`,
+			`rotator_grpc_request_duration_seconds_bucket`,
+			`rotator_banner_selections_total{path="top_rated",slot="1"} 2`,
+			`rotator_db_query_duration_seconds_count{method="SlotViews"} 1`,
+			`rotator_rmq_publishes_total{result="failure"} 1`,
+			`rotator_batch_publisher_queue_depth 3`,
+			`rotator_db_dropped_events_total 7`,
+			`rotator_batch_publisher_events_total{result="spilled"} 2`,
+			`go_goroutines`,
+		} {
+			require.Contains(t, string(body), name)
+		}
+	})
+}
@@ -0,0 +1,353 @@
+package migrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// lockID is the key of the advisory lock which serializes concurrent runners.
+const lockID = 7424389
+
+const sqliteDriver = "sqlite"
+
+// legacyTable is a table of the schema which was applied by init.sql before
+// the migrations, a database having it without the applied migrations is
+// baselined at the first migration.
+const legacyTable = "slots"
+
+var (
+	ErrInvalidMigration = errors.New("invalid migration")
+	ErrNoMigrations     = errors.New("no applied migrations")
+)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+type appliedMigration struct {
+	Version   int64     `db:"version"`
+	AppliedAt time.Time `db:"applied_at"`
+}
+
+type Migrator struct {
+	db         *sqlx.DB
+	migrations []Migration
+}
+
+// NewMigrator reads the <version>_<name>.up.sql and <version>_<name>.down.sql
+// files from the root of fsys.
+func NewMigrator(db *sqlx.DB, fsys fs.FS) (*Migrator, error) {
+	migrations, err := readMigrations(fsys)
+	if err != nil {
+		return nil, fmt.Errorf("new migrator -> %w", err)
+	}
+
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Up applies all pending migrations in order, each in its own transaction.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	if err := m.init(ctx); err != nil {
+		return nil, fmt.Errorf("migrator -> up -> %w", err)
+	}
+
+	var result []Migration
+	for _, migration := range m.migrations {
+		applied, err := m.apply(ctx, migration, true)
+		if err != nil {
+			return result, fmt.Errorf("migrator -> up -> %w", err)
+		}
+		if applied {
+			result = append(result, migration)
+		}
+	}
+
+	return result, nil
+}
+
+// Down reverts the last applied migration.
+func (m *Migrator) Down(ctx context.Context) (*Migration, error) {
+	if err := m.init(ctx); err != nil {
+		return nil, fmt.Errorf("migrator -> down -> %w", err)
+	}
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("migrator -> down -> %w", err)
+	}
+
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+
+		if _, err = m.apply(ctx, migration, false); err != nil {
+			return nil, fmt.Errorf("migrator -> down -> %w", err)
+		}
+
+		return &migration, nil
+	}
+
+	return nil, fmt.Errorf("migrator -> down -> %w", ErrNoMigrations)
+}
+
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	if err := m.init(ctx); err != nil {
+		return nil, fmt.Errorf("migrator -> status -> %w", err)
+	}
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("migrator -> status -> %w", err)
+	}
+
+	result := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		appliedAt, ok := applied[migration.Version]
+		result = append(result, Status{Migration: migration, Applied: ok, AppliedAt: appliedAt})
+	}
+
+	return result, nil
+}
+
+func (m *Migrator) init(ctx context.Context) error {
+	appliedAt := "timestamptz NOT NULL DEFAULT now()"
+	if m.db.DriverName() == sqliteDriver {
+		appliedAt = "timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP"
+	}
+
+	_, err := m.db.ExecContext(
+		ctx,
+		`CREATE TABLE IF NOT EXISTS schema_migrations
+				(
+					version    bigint NOT NULL,
+					name       text   NOT NULL,
+					applied_at `+appliedAt+`,
+					CONSTRAINT "schema_migrations_pk" PRIMARY KEY (version)
+				);`,
+	)
+	if err != nil {
+		return fmt.Errorf("create migrations table -> %w", err)
+	}
+
+	if err = m.baseline(ctx); err != nil {
+		return fmt.Errorf("baseline -> %w", err)
+	}
+
+	return nil
+}
+
+// baseline records the first migration as applied when no migration is
+// recorded yet, but the schema of init.sql already exists, as its script
+// would fail on the existing tables.
+func (m *Migrator) baseline(ctx context.Context) error {
+	if len(m.migrations) == 0 {
+		return nil
+	}
+
+	var recorded bool
+	if err := m.db.GetContext(ctx, &recorded, `SELECT EXISTS(SELECT 1 FROM schema_migrations);`); err != nil {
+		return fmt.Errorf("check applied migrations -> %w", err)
+	}
+	if recorded {
+		return nil
+	}
+
+	query := `SELECT to_regclass($1) IS NOT NULL;`
+	if m.db.DriverName() == sqliteDriver {
+		query = `SELECT EXISTS(SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = $1);`
+	}
+
+	var legacy bool
+	if err := m.db.GetContext(ctx, &legacy, query, legacyTable); err != nil {
+		return fmt.Errorf("check legacy schema -> %w", err)
+	}
+	if !legacy {
+		return nil
+	}
+
+	first := m.migrations[0]
+	_, err := m.db.ExecContext(
+		ctx,
+		`INSERT INTO schema_migrations (version, name) VALUES ($1, $2) ON CONFLICT DO NOTHING;`,
+		first.Version, first.Name,
+	)
+	if err != nil {
+		return fmt.Errorf("record migration %d -> %w", first.Version, err)
+	}
+
+	return nil
+}
+
+func (m *Migrator) applied(ctx context.Context) (map[int64]time.Time, error) {
+	var rows []appliedMigration
+	err := m.db.SelectContext(ctx, &rows, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("select applied migrations -> %w", err)
+	}
+
+	result := make(map[int64]time.Time, len(rows))
+	for _, row := range rows {
+		result[row.Version] = row.AppliedAt
+	}
+
+	return result, nil
+}
+
+// apply runs the up or down script of the migration in a transaction holding
+// the advisory lock. SQLite has no advisory locks, its write transactions are
+// serialized by the database itself. It reports false when the migration is
+// already in the requested state.
+func (m *Migrator) apply(ctx context.Context, migration Migration, up bool) (bool, error) {
+	tx, err := m.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("begin migration %d -> %w", migration.Version, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if m.db.DriverName() != sqliteDriver {
+		if _, err = tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1);`, lockID); err != nil {
+			return false, fmt.Errorf("lock migration %d -> %w", migration.Version, err)
+		}
+	}
+
+	var exists bool
+	err = tx.GetContext(
+		ctx,
+		&exists,
+		`SELECT EXISTS(SELECT 1 FROM schema_migrations WHERE version = $1);`,
+		migration.Version,
+	)
+	if err != nil {
+		return false, fmt.Errorf("check migration %d -> %w", migration.Version, err)
+	}
+	if exists == up {
+		return false, nil
+	}
+
+	script := migration.Down
+	if up {
+		script = migration.Up
+	}
+
+	if _, err = tx.ExecContext(ctx, script); err != nil {
+		return false, fmt.Errorf("run migration %d -> %w", migration.Version, err)
+	}
+
+	if up {
+		_, err = tx.ExecContext(
+			ctx,
+			`INSERT INTO schema_migrations (version, name) VALUES ($1, $2);`,
+			migration.Version, migration.Name,
+		)
+	} else {
+		_, err = tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1;`, migration.Version)
+	}
+	if err != nil {
+		return false, fmt.Errorf("record migration %d -> %w", migration.Version, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, fmt.Errorf("commit migration %d -> %w", migration.Version, err)
+	}
+
+	return true, nil
+}
+
+func readMigrations(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("read migrations -> %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, file := range files {
+		version, name, up, err := parseFileName(file)
+		if err != nil {
+			return nil, err
+		}
+
+		b, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("read migration %s -> %w", file, err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: name}
+			byVersion[version] = migration
+		}
+		if migration.Name != name {
+			return nil, fmt.Errorf("%w: version %d has different names", ErrInvalidMigration, version)
+		}
+
+		if up {
+			migration.Up = string(b)
+		} else {
+			migration.Down = string(b)
+		}
+	}
+
+	result := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("%w: version %d needs up and down scripts", ErrInvalidMigration, migration.Version)
+		}
+		result = append(result, *migration)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version < result[j].Version
+	})
+
+	return result, nil
+}
+
+// parseFileName splits 0001_init.up.sql into its version, name and direction.
+func parseFileName(file string) (int64, string, bool, error) {
+	base := strings.TrimSuffix(path.Base(file), ".sql")
+
+	var up bool
+	switch {
+	case strings.HasSuffix(base, ".up"):
+		up = true
+		base = strings.TrimSuffix(base, ".up")
+	case strings.HasSuffix(base, ".down"):
+		base = strings.TrimSuffix(base, ".down")
+	default:
+		return 0, "", false, fmt.Errorf("%w: %s has no direction", ErrInvalidMigration, file)
+	}
+
+	parts := strings.SplitN(base, "_", 2)
+	if len(parts) != 2 {
+		return 0, "", false, fmt.Errorf("%w: %s has no name", ErrInvalidMigration, file)
+	}
+
+	version, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, "", false, fmt.Errorf("%w: %s has no version", ErrInvalidMigration, file)
+	}
+
+	return version, parts[1], up, nil
+}
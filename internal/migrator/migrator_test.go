@@ -0,0 +1,223 @@
+package migrator
+
+import (
+	sqlstorage "banners-rotator/internal/storage/sql"
+	"banners-rotator/migrations"
+	"context"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+)
+
+func getFS() fstest.MapFS {
+	return fstest.MapFS{
+		"0002_second.up.sql":   {Data: []byte("CREATE TABLE second (id int);")},
+		"0002_second.down.sql": {Data: []byte("DROP TABLE second;")},
+		"0001_first.up.sql":    {Data: []byte("CREATE TABLE first (id int);")},
+		"0001_first.down.sql":  {Data: []byte("DROP TABLE first;")},
+	}
+}
+
+func getMigrator(t *testing.T) (*Migrator, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	m, err := NewMigrator(sqlx.NewDb(db, "sqlmock"), getFS())
+	require.NoError(t, err)
+
+	return m, mock
+}
+
+// expectInit expects the migrations table to be created, recorded reports
+// whether it already has applied migrations.
+func expectInit(mock sqlmock.Sqlmock, recorded bool) {
+	mock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE IF NOT EXISTS schema_migrations`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS(SELECT 1 FROM schema_migrations);`)).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(recorded))
+}
+
+func expectApply(mock sqlmock.Sqlmock, version int64, exists bool, script string) {
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_xact_lock($1);`)).
+		WithArgs(lockID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS(SELECT 1 FROM schema_migrations WHERE version = $1);`)).
+		WithArgs(version).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(exists))
+	if script == "" {
+		mock.ExpectRollback()
+		return
+	}
+	mock.ExpectExec(regexp.QuoteMeta(script)).WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func TestMigrator_readMigrations(t *testing.T) {
+	t.Run("read migrations in order", func(t *testing.T) {
+		result, err := readMigrations(getFS())
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+		require.Equal(t, int64(1), result[0].Version)
+		require.Equal(t, "first", result[0].Name)
+		require.Equal(t, "DROP TABLE first;", result[0].Down)
+		require.Equal(t, int64(2), result[1].Version)
+	})
+
+	t.Run("read embedded migrations", func(t *testing.T) {
+		result, err := readMigrations(migrations.FS)
+		require.NoError(t, err)
+		require.NotEmpty(t, result)
+		require.Equal(t, int64(1), result[0].Version)
+	})
+
+	t.Run("migration without down script", func(t *testing.T) {
+		fsys := getFS()
+		delete(fsys, "0002_second.down.sql")
+		_, err := readMigrations(fsys)
+		require.ErrorIs(t, err, ErrInvalidMigration)
+	})
+
+	t.Run("migration with wrong name", func(t *testing.T) {
+		fsys := getFS()
+		fsys["second.up.sql"] = &fstest.MapFile{Data: []byte("SELECT 1;")}
+		_, err := readMigrations(fsys)
+		require.ErrorIs(t, err, ErrInvalidMigration)
+	})
+}
+
+func TestMigrator_Up(t *testing.T) {
+	m, mock := getMigrator(t)
+
+	t.Run("apply pending migrations", func(t *testing.T) {
+		expectInit(mock, true)
+
+		expectApply(mock, 1, true, "")
+
+		expectApply(mock, 2, false, "CREATE TABLE second (id int);")
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO schema_migrations (version, name) VALUES ($1, $2);`)).
+			WithArgs(2, "second").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		applied, err := m.Up(context.Background())
+		require.NoError(t, err)
+		require.Len(t, applied, 1)
+		require.Equal(t, int64(2), applied[0].Version)
+	})
+
+	t.Run("baseline legacy schema", func(t *testing.T) {
+		expectInit(mock, false)
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT to_regclass($1) IS NOT NULL;`)).
+			WithArgs(legacyTable).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectExec(regexp.QuoteMeta(
+			`INSERT INTO schema_migrations (version, name) VALUES ($1, $2) ON CONFLICT DO NOTHING;`,
+		)).
+			WithArgs(1, "first").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		expectApply(mock, 1, true, "")
+		expectApply(mock, 2, true, "")
+
+		applied, err := m.Up(context.Background())
+		require.NoError(t, err)
+		require.Empty(t, applied)
+	})
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestMigrator_UpLegacySchema(t *testing.T) {
+	ctx := context.Background()
+	db, err := sqlstorage.OpenSQLite(ctx, filepath.Join(t.TempDir(), "rotator.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	m, err := NewMigrator(db, migrations.SQLiteFS)
+	require.NoError(t, err)
+
+	// the schema of init.sql, applied before the migrations
+	_, err = db.ExecContext(ctx, m.migrations[0].Up)
+	require.NoError(t, err)
+
+	applied, err := m.Up(ctx)
+	require.NoError(t, err)
+	require.Len(t, applied, len(m.migrations)-1)
+	require.NotEqual(t, int64(1), applied[0].Version)
+
+	status, err := m.Status(ctx)
+	require.NoError(t, err)
+	for _, s := range status {
+		require.True(t, s.Applied, s.Version)
+	}
+}
+
+func TestMigrator_Down(t *testing.T) {
+	m, mock := getMigrator(t)
+	appliedQuery := regexp.QuoteMeta(`SELECT version, applied_at FROM schema_migrations`)
+
+	t.Run("revert last migration", func(t *testing.T) {
+		expectInit(mock, true)
+		mock.ExpectQuery(appliedQuery).
+			WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, time.Now()))
+
+		expectApply(mock, 1, true, "DROP TABLE first;")
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM schema_migrations WHERE version = $1;`)).
+			WithArgs(1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		reverted, err := m.Down(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, int64(1), reverted.Version)
+	})
+
+	t.Run("nothing to revert", func(t *testing.T) {
+		expectInit(mock, false)
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT to_regclass($1) IS NOT NULL;`)).
+			WithArgs(legacyTable).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectQuery(appliedQuery).
+			WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}))
+
+		_, err := m.Down(context.Background())
+		require.ErrorIs(t, err, ErrNoMigrations)
+	})
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestMigrator_Status(t *testing.T) {
+	m, mock := getMigrator(t)
+
+	t.Run("migrations status", func(t *testing.T) {
+		expectInit(mock, true)
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT version, applied_at FROM schema_migrations`)).
+			WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, time.Now()))
+
+		status, err := m.Status(context.Background())
+		require.NoError(t, err)
+		require.Len(t, status, 2)
+		require.True(t, status[0].Applied)
+		require.False(t, status[1].Applied)
+	})
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
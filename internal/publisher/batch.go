@@ -0,0 +1,274 @@
+package publisher
+
+import (
+	"banners-rotator/internal/rmq"
+	"banners-rotator/internal/rotator"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Back-pressure policies applied when the queue of the batch publisher is full.
+const (
+	PolicyBlock = "block"
+	PolicyDrop  = "drop"
+	PolicySpill = "spill"
+)
+
+var (
+	ErrPublisherClosed = errors.New("publisher is closed")
+	ErrUnknownPolicy   = errors.New("unknown back-pressure policy")
+	ErrSpillNotSet     = errors.New("spill publisher is not set")
+)
+
+// BatchSender publishes several messages at once, e.g. in one broker round
+// trip, it returns the messages which failed to be published with the error.
+type BatchSender interface {
+	PublishBatch(messages []rmq.QMessage) ([]rmq.QMessage, error)
+}
+
+type BatchConfig struct {
+	Workers       int
+	QueueSize     int
+	Size          int
+	FlushInterval time.Duration
+	Policy        string
+}
+
+type BatchStats struct {
+	QueueDepth int
+	Published  uint64
+	Dropped    uint64
+	Spilled    uint64
+	Failed     uint64
+}
+
+type batchCounters struct {
+	published uint64
+	dropped   uint64
+	spilled   uint64
+	failed    uint64
+}
+
+// BatchPublisher buffers events and publishes them in batches from background
+// workers, so the request path does not wait for the broker. A next publisher
+// implementing BatchSender receives every batch at once.
+type BatchPublisher struct {
+	counters batchCounters
+	next     rotator.EventPublisher
+	spill    rotator.EventPublisher
+	logger   rotator.Logger
+	cfg      BatchConfig
+	queue    chan rmq.QMessage
+	done     chan struct{}
+	mu       sync.RWMutex
+	closed   bool
+	senders  sync.WaitGroup
+	wg       sync.WaitGroup
+}
+
+// NewBatchPublisher starts the workers publishing to next. The spill publisher
+// receives events which do not fit into the queue or failed to be published,
+// it is required for the spill policy and optional otherwise.
+func NewBatchPublisher(
+	next, spill rotator.EventPublisher,
+	logger rotator.Logger,
+	cfg BatchConfig,
+) (*BatchPublisher, error) {
+	switch cfg.Policy {
+	case PolicyBlock, PolicyDrop:
+	case PolicySpill:
+		if spill == nil {
+			return nil, fmt.Errorf("new batch publisher -> %w", ErrSpillNotSet)
+		}
+	default:
+		return nil, fmt.Errorf("new batch publisher -> %w (%s)", ErrUnknownPolicy, cfg.Policy)
+	}
+
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	if cfg.Size <= 0 {
+		cfg.Size = 1
+	}
+	if cfg.QueueSize < 0 {
+		cfg.QueueSize = 0
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = time.Second
+	}
+
+	p := &BatchPublisher{
+		next:   next,
+		spill:  spill,
+		logger: logger,
+		cfg:    cfg,
+		queue:  make(chan rmq.QMessage, cfg.QueueSize),
+		done:   make(chan struct{}),
+	}
+
+	p.wg.Add(cfg.Workers)
+	for i := 0; i < cfg.Workers; i++ {
+		go p.worker()
+	}
+
+	return p, nil
+}
+
+func (p *BatchPublisher) Publish(message rmq.QMessage) error {
+	p.mu.RLock()
+	if p.closed {
+		p.mu.RUnlock()
+		return ErrPublisherClosed
+	}
+	p.senders.Add(1)
+	p.mu.RUnlock()
+	defer p.senders.Done()
+
+	if p.cfg.Policy == PolicyBlock {
+		return p.publishBlocking(message)
+	}
+
+	select {
+	case p.queue <- message:
+		return nil
+	default:
+	}
+
+	if p.cfg.Policy == PolicyDrop {
+		atomic.AddUint64(&p.counters.dropped, 1)
+		return nil
+	}
+
+	if err := p.spill.Publish(message); err != nil {
+		atomic.AddUint64(&p.counters.dropped, 1)
+		return fmt.Errorf("batch publisher spill -> %w", err)
+	}
+	atomic.AddUint64(&p.counters.spilled, 1)
+
+	return nil
+}
+
+// publishBlocking waits for the room in the queue without holding the lock,
+// the shutdown unblocks it.
+func (p *BatchPublisher) publishBlocking(message rmq.QMessage) error {
+	select {
+	case p.queue <- message:
+		return nil
+	case <-p.done:
+		return ErrPublisherClosed
+	}
+}
+
+func (p *BatchPublisher) Stats() BatchStats {
+	return BatchStats{
+		QueueDepth: len(p.queue),
+		Published:  atomic.LoadUint64(&p.counters.published),
+		Dropped:    atomic.LoadUint64(&p.counters.dropped),
+		Spilled:    atomic.LoadUint64(&p.counters.spilled),
+		Failed:     atomic.LoadUint64(&p.counters.failed),
+	}
+}
+
+// Shutdown stops accepting events and waits until the queued ones are published.
+func (p *BatchPublisher) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	close(p.done)
+	p.mu.Unlock()
+
+	p.senders.Wait()
+	close(p.queue)
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("batch publisher shutdown -> %w", ctx.Err())
+	}
+}
+
+func (p *BatchPublisher) Close() error {
+	return p.Shutdown(context.Background())
+}
+
+func (p *BatchPublisher) worker() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]rmq.QMessage, 0, p.cfg.Size)
+	for {
+		select {
+		case message, ok := <-p.queue:
+			if !ok {
+				p.flush(batch)
+				return
+			}
+
+			batch = append(batch, message)
+			if len(batch) >= p.cfg.Size {
+				p.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			p.flush(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+// flush publishes the batch at once when the next publisher supports it and
+// message by message otherwise. The failed messages go to the spill publisher.
+func (p *BatchPublisher) flush(batch []rmq.QMessage) {
+	if len(batch) == 0 {
+		return
+	}
+
+	if sender, ok := p.next.(BatchSender); ok {
+		failed, err := sender.PublishBatch(batch)
+		atomic.AddUint64(&p.counters.published, uint64(len(batch)-len(failed)))
+		if err != nil {
+			p.logger.Error(fmt.Sprintf("batch publisher -> %s", err))
+		}
+		for _, message := range failed {
+			p.fail(message)
+		}
+
+		return
+	}
+
+	for _, message := range batch {
+		err := p.next.Publish(message)
+		if err == nil {
+			atomic.AddUint64(&p.counters.published, 1)
+			continue
+		}
+
+		p.logger.Error(fmt.Sprintf("batch publisher -> %s", err))
+		p.fail(message)
+	}
+}
+
+// fail spills the message which failed to be published or counts it as failed.
+func (p *BatchPublisher) fail(message rmq.QMessage) {
+	if p.spill != nil && p.spill.Publish(message) == nil {
+		atomic.AddUint64(&p.counters.spilled, 1)
+		return
+	}
+	atomic.AddUint64(&p.counters.failed, 1)
+}
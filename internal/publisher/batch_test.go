@@ -0,0 +1,239 @@
+package publisher
+
+import (
+	"banners-rotator/internal/logger"
+	"banners-rotator/internal/rmq"
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testPublisher struct {
+	mu       sync.Mutex
+	messages []rmq.QMessage
+	release  chan struct{}
+	err      error
+}
+
+func (p *testPublisher) Publish(message rmq.QMessage) error {
+	if p.release != nil {
+		<-p.release
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.err != nil {
+		return p.err
+	}
+	p.messages = append(p.messages, message)
+
+	return nil
+}
+
+func (p *testPublisher) count() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.messages)
+}
+
+// testBatchSender fails the messages of the slot failSlotID.
+type testBatchSender struct {
+	testPublisher
+	batches    []int
+	failSlotID int64
+}
+
+func (p *testBatchSender) PublishBatch(messages []rmq.QMessage) ([]rmq.QMessage, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.batches = append(p.batches, len(messages))
+
+	var failed []rmq.QMessage
+	for _, message := range messages {
+		if message.SlotID == p.failSlotID {
+			failed = append(failed, message)
+			continue
+		}
+		p.messages = append(p.messages, message)
+	}
+	if len(failed) > 0 {
+		return failed, fmt.Errorf("test error")
+	}
+
+	return nil, nil
+}
+
+func getBatchPublisher(
+	t *testing.T,
+	next, spill *testPublisher,
+	cfg BatchConfig,
+) *BatchPublisher {
+	t.Helper()
+
+	logg, err := logger.NewLogger("error", []string{"stderr"})
+	require.NoError(t, err)
+
+	var p *BatchPublisher
+	if spill == nil {
+		p, err = NewBatchPublisher(next, nil, logg, cfg)
+	} else {
+		p, err = NewBatchPublisher(next, spill, logg, cfg)
+	}
+	require.NoError(t, err)
+
+	return p
+}
+
+func TestBatchPublisher(t *testing.T) {
+	message := rmq.QMessage{Type: rmq.EventView, SlotID: 1, BannerID: 1, GroupID: 1}
+
+	t.Run("flush by size", func(t *testing.T) {
+		next := &testPublisher{}
+		p := getBatchPublisher(t, next, nil, BatchConfig{
+			Workers: 1, QueueSize: 10, Size: 2, FlushInterval: time.Hour, Policy: PolicyBlock,
+		})
+
+		require.NoError(t, p.Publish(message))
+		require.NoError(t, p.Publish(message))
+		require.Eventually(t, func() bool { return next.count() == 2 }, time.Second, time.Millisecond)
+		require.NoError(t, p.Close())
+	})
+
+	t.Run("flush by interval", func(t *testing.T) {
+		next := &testPublisher{}
+		p := getBatchPublisher(t, next, nil, BatchConfig{
+			Workers: 1, QueueSize: 10, Size: 100, FlushInterval: 10 * time.Millisecond, Policy: PolicyBlock,
+		})
+
+		require.NoError(t, p.Publish(message))
+		require.Eventually(t, func() bool { return next.count() == 1 }, time.Second, time.Millisecond)
+		require.NoError(t, p.Close())
+	})
+
+	t.Run("flush on shutdown", func(t *testing.T) {
+		next := &testPublisher{}
+		p := getBatchPublisher(t, next, nil, BatchConfig{
+			Workers: 2, QueueSize: 100, Size: 100, FlushInterval: time.Hour, Policy: PolicyBlock,
+		})
+
+		for i := 0; i < 50; i++ {
+			require.NoError(t, p.Publish(message))
+		}
+		require.NoError(t, p.Shutdown(context.Background()))
+		require.Equal(t, 50, next.count())
+		require.Equal(t, uint64(50), p.Stats().Published)
+		require.ErrorIs(t, p.Publish(message), ErrPublisherClosed)
+	})
+
+	t.Run("shutdown while blocked on full queue", func(t *testing.T) {
+		next := &testPublisher{release: make(chan struct{})}
+		p := getBatchPublisher(t, next, nil, BatchConfig{
+			Workers: 1, QueueSize: 1, Size: 1, FlushInterval: time.Hour, Policy: PolicyBlock,
+		})
+
+		require.NoError(t, p.Publish(message))
+		require.Eventually(t, func() bool { return p.Stats().QueueDepth == 0 }, time.Second, time.Millisecond)
+		require.NoError(t, p.Publish(message))
+
+		blocked := make(chan error)
+		go func() { blocked <- p.Publish(message) }()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		require.ErrorIs(t, p.Shutdown(ctx), context.DeadlineExceeded)
+		require.ErrorIs(t, <-blocked, ErrPublisherClosed)
+
+		close(next.release)
+		require.Eventually(t, func() bool { return next.count() == 2 }, time.Second, time.Millisecond)
+	})
+
+	t.Run("drop when queue is full", func(t *testing.T) {
+		next := &testPublisher{release: make(chan struct{})}
+		p := getBatchPublisher(t, next, nil, BatchConfig{
+			Workers: 1, QueueSize: 1, Size: 1, FlushInterval: time.Hour, Policy: PolicyDrop,
+		})
+
+		for i := 0; i < 10; i++ {
+			require.NoError(t, p.Publish(message))
+		}
+		require.Greater(t, p.Stats().Dropped, uint64(0))
+
+		close(next.release)
+		require.NoError(t, p.Close())
+		stats := p.Stats()
+		require.Equal(t, uint64(10), stats.Published+stats.Dropped)
+	})
+
+	t.Run("spill when queue is full", func(t *testing.T) {
+		next := &testPublisher{release: make(chan struct{})}
+		spill := &testPublisher{}
+		p := getBatchPublisher(t, next, spill, BatchConfig{
+			Workers: 1, QueueSize: 1, Size: 1, FlushInterval: time.Hour, Policy: PolicySpill,
+		})
+
+		for i := 0; i < 10; i++ {
+			require.NoError(t, p.Publish(message))
+		}
+
+		close(next.release)
+		require.NoError(t, p.Close())
+		stats := p.Stats()
+		require.Greater(t, stats.Spilled, uint64(0))
+		require.Equal(t, int(stats.Spilled), spill.count())
+		require.Equal(t, uint64(10), stats.Published+stats.Spilled)
+	})
+
+	t.Run("count failed events", func(t *testing.T) {
+		next := &testPublisher{err: fmt.Errorf("test error")}
+		p := getBatchPublisher(t, next, nil, BatchConfig{
+			Workers: 1, QueueSize: 10, Size: 10, FlushInterval: time.Hour, Policy: PolicyBlock,
+		})
+
+		require.NoError(t, p.Publish(message))
+		require.NoError(t, p.Close())
+		require.Equal(t, uint64(1), p.Stats().Failed)
+	})
+
+	t.Run("publish batch at once", func(t *testing.T) {
+		logg, err := logger.NewLogger("error", []string{"stderr"})
+		require.NoError(t, err)
+
+		next := &testBatchSender{failSlotID: 2}
+		spill := &testPublisher{}
+		p, err := NewBatchPublisher(next, spill, logg, BatchConfig{
+			Workers: 1, QueueSize: 10, Size: 5, FlushInterval: time.Hour, Policy: PolicyBlock,
+		})
+		require.NoError(t, err)
+
+		for i := 0; i < 4; i++ {
+			require.NoError(t, p.Publish(message))
+		}
+		require.NoError(t, p.Publish(rmq.QMessage{Type: rmq.EventView, SlotID: 2, BannerID: 1, GroupID: 1}))
+		require.NoError(t, p.Close())
+
+		require.Equal(t, []int{5}, next.batches)
+		require.Equal(t, 4, next.count())
+		require.Equal(t, 1, spill.count())
+		stats := p.Stats()
+		require.Equal(t, uint64(4), stats.Published)
+		require.Equal(t, uint64(1), stats.Spilled)
+	})
+
+	t.Run("wrong config", func(t *testing.T) {
+		logg, err := logger.NewLogger("error", []string{"stderr"})
+		require.NoError(t, err)
+
+		_, err = NewBatchPublisher(&testPublisher{}, nil, logg, BatchConfig{Policy: "unknown"})
+		require.ErrorIs(t, err, ErrUnknownPolicy)
+
+		_, err = NewBatchPublisher(&testPublisher{}, nil, logg, BatchConfig{Policy: PolicySpill})
+		require.ErrorIs(t, err, ErrSpillNotSet)
+	})
+}
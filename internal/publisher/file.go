@@ -0,0 +1,48 @@
+package publisher
+
+import (
+	"banners-rotator/internal/rmq"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// FilePublisher appends events to a local file, one JSON document per line.
+type FilePublisher struct {
+	mu       sync.Mutex
+	instance string
+	file     *os.File
+}
+
+func NewFilePublisher(path, instance string) (*FilePublisher, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("new file publisher -> %w", err)
+	}
+
+	return &FilePublisher{instance: instance, file: file}, nil
+}
+
+func (p *FilePublisher) Publish(message rmq.QMessage) error {
+	b, err := json.Marshal(message.WithDefaults(p.instance))
+	if err != nil {
+		return fmt.Errorf("file publisher marshall message -> %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, err = p.file.Write(append(b, '\n')); err != nil {
+		return fmt.Errorf("file publisher write message -> %w", err)
+	}
+
+	return nil
+}
+
+func (p *FilePublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.file.Close()
+}
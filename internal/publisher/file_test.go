@@ -0,0 +1,49 @@
+package publisher
+
+import (
+	"banners-rotator/internal/rmq"
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilePublisher(t *testing.T) {
+	t.Run("publish events as json lines", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "events.jsonl")
+		p, err := NewFilePublisher(path, "test")
+		require.NoError(t, err)
+
+		require.NoError(t, p.Publish(rmq.QMessage{Type: rmq.EventView, SlotID: 1, BannerID: 1, GroupID: 1}))
+		require.NoError(t, p.Publish(rmq.QMessage{Type: rmq.EventClick, SlotID: 1, BannerID: 1, GroupID: 1}))
+		require.NoError(t, p.Close())
+
+		f, err := os.Open(path)
+		require.NoError(t, err)
+		defer f.Close()
+
+		var messages []rmq.QMessage
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var message rmq.QMessage
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &message))
+			messages = append(messages, message)
+		}
+		require.NoError(t, scanner.Err())
+
+		require.Len(t, messages, 2)
+		require.Equal(t, rmq.EventView, messages[0].Type)
+		require.Equal(t, rmq.EventClick, messages[1].Type)
+		require.Equal(t, rmq.SchemaVersion, messages[0].Version)
+		require.Equal(t, "test", messages[0].Instance)
+		require.NotEmpty(t, messages[0].ID)
+	})
+
+	t.Run("file publisher with wrong path", func(t *testing.T) {
+		_, err := NewFilePublisher(filepath.Join(t.TempDir(), "not", "found"), "test")
+		require.Error(t, err)
+	})
+}
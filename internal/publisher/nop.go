@@ -0,0 +1,18 @@
+package publisher
+
+import "banners-rotator/internal/rmq"
+
+// NopPublisher discards all events.
+type NopPublisher struct{}
+
+func NewNopPublisher() *NopPublisher {
+	return &NopPublisher{}
+}
+
+func (p *NopPublisher) Publish(message rmq.QMessage) error {
+	return nil
+}
+
+func (p *NopPublisher) Close() error {
+	return nil
+}
@@ -0,0 +1,77 @@
+package replay
+
+import (
+	"banners-rotator/internal/storage"
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// FileSource reads the events from a JSON-lines file, one storage.Event per
+// line, ordered by date. The file is read anew on every call.
+type FileSource struct {
+	path string
+}
+
+func NewFileSource(path string) *FileSource {
+	return &FileSource{path: path}
+}
+
+func (s *FileSource) Events(ctx context.Context, slotID, from, to int64, fn func(storage.Event) error) error {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return fmt.Errorf("file source -> %w", err)
+	}
+	defer f.Close()
+
+	return ReadEvents(ctx, f, slotID, from, to, fn)
+}
+
+// ReadEvents streams the JSON-lines events of r with from <= date < to to fn,
+// a zero slotID selects all slots.
+func ReadEvents(ctx context.Context, r io.Reader, slotID, from, to int64, fn func(storage.Event) error) error {
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("read events -> %w", err)
+		}
+
+		var e storage.Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return fmt.Errorf("read events -> line %d -> %w", line, err)
+		}
+		if e.Date < from || e.Date >= to || (slotID > 0 && e.SlotID != slotID) {
+			continue
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read events -> %w", err)
+	}
+
+	return nil
+}
+
+// WriteEvents returns fn writing the events to w as JSON lines, the format
+// FileSource reads.
+func WriteEvents(w io.Writer) func(storage.Event) error {
+	enc := json.NewEncoder(w)
+
+	return func(e storage.Event) error {
+		if err := enc.Encode(e); err != nil {
+			return fmt.Errorf("write events -> %w", err)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,231 @@
+// Package replay estimates the CTR a rotator.Bandit would have achieved on
+// the logged traffic with the replay method: a logged view counts only when
+// the bandit chooses the same banner, the other views are skipped.
+package replay
+
+import (
+	"banners-rotator/internal/publisher"
+	"banners-rotator/internal/rotator"
+	"banners-rotator/internal/storage"
+	memorystorage "banners-rotator/internal/storage/memory"
+	"context"
+	"errors"
+	"fmt"
+)
+
+var ErrUnorderedEvents = errors.New("events are not ordered by date")
+
+// Source streams the logged events with from <= date < to in the order of
+// dates, a zero slotID selects all slots.
+type Source interface {
+	Events(ctx context.Context, slotID, from, to int64, fn func(storage.Event) error) error
+}
+
+type Filter struct {
+	SlotID int64
+	From   int64
+	To     int64
+}
+
+// Result holds the logged counters and the estimate of a strategy. Matched
+// views are the logged views the strategy chose the same banner for, the
+// estimated CTR is the share of them followed by a click.
+type Result struct {
+	Strategy      string  `json:"strategy"`
+	Views         int64   `json:"views"`
+	Clicks        int64   `json:"clicks"`
+	LoggedCTR     float64 `json:"loggedCtr"`
+	Matched       int64   `json:"matched"`
+	MatchedClicks int64   `json:"matchedClicks"`
+	CTR           float64 `json:"ctr"`
+	// Failures counts the views the strategy failed to choose a banner for.
+	Failures int64 `json:"failures"`
+}
+
+type key struct {
+	slotID, bannerID, groupID int64
+}
+
+// policy replays the log for a single strategy. The rotator works on its own
+// in-memory storage holding only the matched events, a banner joins the
+// rotation of the slot with its first logged event.
+type policy struct {
+	app       rotator.App
+	storage   *memorystorage.Storage
+	slots     map[int64]int64
+	banners   map[int64]int64
+	groups    map[int64]int64
+	rotations map[key]struct{}
+	// A click is attributed to the last logged view of its slot, banner and
+	// group. clickable holds whether that view is matched and not clicked yet.
+	clickable map[key]bool
+	result    Result
+}
+
+func newPolicy(name string, b rotator.Bandit) *policy {
+	s := memorystorage.NewStorage()
+
+	return &policy{
+		app:       rotator.NewApp(s, publisher.NewNopPublisher(), b),
+		storage:   s,
+		slots:     make(map[int64]int64),
+		banners:   make(map[int64]int64),
+		groups:    make(map[int64]int64),
+		rotations: make(map[key]struct{}),
+		clickable: make(map[key]bool),
+		result:    Result{Strategy: name},
+	}
+}
+
+// Evaluate replays the events of the source selected by the filter for every
+// strategy in a single pass and returns the results in the order of names.
+func Evaluate(
+	ctx context.Context,
+	src Source,
+	filter Filter,
+	names []string,
+	bandits map[string]rotator.Bandit,
+) ([]Result, error) {
+	policies := make([]*policy, 0, len(names))
+	for _, name := range names {
+		policies = append(policies, newPolicy(name, bandits[name]))
+	}
+
+	var last int64
+	err := src.Events(ctx, filter.SlotID, filter.From, filter.To, func(e storage.Event) error {
+		if e.Date < last {
+			return fmt.Errorf("%w (%d after %d)", ErrUnorderedEvents, e.Date, last)
+		}
+		last = e.Date
+
+		for _, p := range policies {
+			if err := p.handle(e); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("replay -> %w", err)
+	}
+
+	results := make([]Result, 0, len(policies))
+	for _, p := range policies {
+		r := p.result
+		if r.Views > 0 {
+			r.LoggedCTR = float64(r.Clicks) / float64(r.Views)
+		}
+		if r.Matched > 0 {
+			r.CTR = float64(r.MatchedClicks) / float64(r.Matched)
+		}
+		results = append(results, r)
+	}
+
+	return results, nil
+}
+
+func (p *policy) handle(e storage.Event) error {
+	k, err := p.key(e)
+	if err != nil {
+		return err
+	}
+
+	switch e.Type {
+	case storage.EventView:
+		p.result.Views++
+		p.clickable[k] = false
+
+		explanation, err := p.app.ExplainBannerForSlot(k.slotID, k.groupID, "")
+		if err != nil {
+			p.result.Failures++
+			return nil
+		}
+		if explanation.Chosen == nil || explanation.Chosen.ID != k.bannerID {
+			return nil
+		}
+
+		if err = p.storage.CreateViewEvent(k.slotID, k.bannerID, k.groupID, e.Date); err != nil {
+			return err
+		}
+		p.result.Matched++
+		p.clickable[k] = true
+	case storage.EventClick:
+		p.result.Clicks++
+		if !p.clickable[k] {
+			return nil
+		}
+
+		if err = p.storage.CreateClickEvent(k.slotID, k.bannerID, k.groupID, e.Date); err != nil {
+			return err
+		}
+		p.result.MatchedClicks++
+		p.clickable[k] = false
+	}
+
+	return nil
+}
+
+// key maps the logged IDs of the event to the IDs of the replay storage,
+// creating the entities and the rotation on the first event.
+func (p *policy) key(e storage.Event) (key, error) {
+	var k key
+	var err error
+
+	if k.slotID, err = mapID(p.slots, e.SlotID, func() (int64, error) {
+		slot, err := p.storage.CreateSlot(fmt.Sprintf("slot %d", e.SlotID))
+		if err != nil {
+			return 0, err
+		}
+
+		return slot.ID, nil
+	}); err != nil {
+		return k, err
+	}
+
+	if k.bannerID, err = mapID(p.banners, e.BannerID, func() (int64, error) {
+		banner, err := p.storage.CreateBanner(fmt.Sprintf("banner %d", e.BannerID))
+		if err != nil {
+			return 0, err
+		}
+
+		return banner.ID, nil
+	}); err != nil {
+		return k, err
+	}
+
+	if k.groupID, err = mapID(p.groups, e.GroupID, func() (int64, error) {
+		group, err := p.storage.CreateGroup(fmt.Sprintf("group %d", e.GroupID))
+		if err != nil {
+			return 0, err
+		}
+
+		return group.ID, nil
+	}); err != nil {
+		return k, err
+	}
+
+	rotation := key{slotID: k.slotID, bannerID: k.bannerID}
+	if _, ok := p.rotations[rotation]; !ok {
+		if err = p.storage.CreateRotation(k.slotID, k.bannerID); err != nil {
+			return k, err
+		}
+		p.rotations[rotation] = struct{}{}
+	}
+
+	return k, nil
+}
+
+func mapID(ids map[int64]int64, id int64, create func() (int64, error)) (int64, error) {
+	if mapped, ok := ids[id]; ok {
+		return mapped, nil
+	}
+
+	mapped, err := create()
+	if err != nil {
+		return 0, err
+	}
+	ids[id] = mapped
+
+	return mapped, nil
+}
@@ -0,0 +1,168 @@
+package replay
+
+import (
+	"banners-rotator/internal/bandit"
+	"banners-rotator/internal/rotator"
+	"banners-rotator/internal/storage"
+	"context"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var errNoChoice = errors.New("no choice")
+
+// firstBandit always chooses the first banner, or fails when fail is set.
+type firstBandit struct {
+	fail bool
+}
+
+func (b firstBandit) RandomBanner(banners []storage.Banner) (*storage.Banner, error) {
+	if b.fail || len(banners) == 0 {
+		return nil, errNoChoice
+	}
+
+	return &banners[0], nil
+}
+
+func (b firstBandit) TopRatedBanner(
+	banners []storage.Banner,
+	_ []storage.ViewEvent,
+	_ []rotator.Reward,
+	_ map[int64]storage.Prior,
+) (*storage.Banner, error) {
+	return b.RandomBanner(banners)
+}
+
+func (b firstBandit) Score(_ int64, _ float64, _ int64) float64 {
+	return 0
+}
+
+func (b firstBandit) Scores(
+	banners []storage.Banner,
+	_ []storage.ViewEvent,
+	_ []rotator.Reward,
+	_ map[int64]storage.Prior,
+) []rotator.BannerScore {
+	scores := make([]rotator.BannerScore, 0, len(banners))
+	for _, banner := range banners {
+		scores = append(scores, rotator.BannerScore{Banner: banner})
+	}
+
+	return scores
+}
+
+func writeEventsFile(t *testing.T, events []storage.Event) *FileSource {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	write := WriteEvents(f)
+	for _, e := range events {
+		require.NoError(t, write(e))
+	}
+
+	return NewFileSource(path)
+}
+
+func TestEvaluate(t *testing.T) {
+	view := func(bannerID, date int64) storage.Event {
+		return storage.Event{Type: storage.EventView, SlotID: 10, BannerID: bannerID, GroupID: 5, Date: date}
+	}
+	click := func(bannerID, date int64) storage.Event {
+		return storage.Event{Type: storage.EventClick, SlotID: 10, BannerID: bannerID, GroupID: 5, Date: date}
+	}
+	src := writeEventsFile(t, []storage.Event{
+		view(100, 1),
+		click(100, 2),
+		view(200, 3),
+		click(200, 4),
+		view(100, 5),
+		click(100, 6),
+		click(100, 7),
+		view(200, 8),
+	})
+	filter := Filter{From: 0, To: math.MaxInt64}
+
+	// the bandit always chooses the banner 100
+	t.Run("counts matched views", func(t *testing.T) {
+		results, err := Evaluate(context.Background(), src, filter, []string{"first"},
+			map[string]rotator.Bandit{"first": firstBandit{}})
+		require.NoError(t, err)
+		require.Equal(t, []Result{{
+			Strategy:      "first",
+			Views:         4,
+			Clicks:        4,
+			LoggedCTR:     1,
+			Matched:       2,
+			MatchedClicks: 2,
+			CTR:           1,
+		}}, results)
+	})
+
+	t.Run("counts failures", func(t *testing.T) {
+		results, err := Evaluate(context.Background(), src, filter, []string{"failing"},
+			map[string]rotator.Bandit{"failing": firstBandit{fail: true}})
+		require.NoError(t, err)
+		require.Equal(t, int64(4), results[0].Failures)
+		require.Zero(t, results[0].Matched)
+	})
+
+	t.Run("several strategies", func(t *testing.T) {
+		names := []string{"first", "ucb1"}
+		results, err := Evaluate(context.Background(), src, Filter{From: 0, To: 5}, names,
+			map[string]rotator.Bandit{"first": firstBandit{}, "ucb1": bandit.NewBandit(bandit.WithSeed(1))})
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		for i, r := range results {
+			require.Equal(t, names[i], r.Strategy)
+			require.Equal(t, int64(2), r.Views)
+			require.Equal(t, int64(2), r.Clicks)
+		}
+	})
+
+	t.Run("unordered events", func(t *testing.T) {
+		unordered := writeEventsFile(t, []storage.Event{view(100, 2), view(100, 1)})
+		_, err := Evaluate(context.Background(), unordered, filter, []string{"first"},
+			map[string]rotator.Bandit{"first": firstBandit{}})
+		require.ErrorIs(t, err, ErrUnorderedEvents)
+	})
+}
+
+func TestReadEvents(t *testing.T) {
+	input := `{"type":"view","slot_id":1,"banner_id":2,"group_id":3,"date":10}
+
+{"type":"view","slot_id":2,"banner_id":2,"group_id":3,"date":11}
+{"type":"click","slot_id":1,"banner_id":2,"group_id":3,"date":12}
+{"type":"view","slot_id":1,"banner_id":2,"group_id":3,"date":20}
+`
+
+	t.Run("filter", func(t *testing.T) {
+		var events []storage.Event
+		err := ReadEvents(context.Background(), strings.NewReader(input), 1, 10, 20, func(e storage.Event) error {
+			events = append(events, e)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []storage.Event{
+			{Type: storage.EventView, SlotID: 1, BannerID: 2, GroupID: 3, Date: 10},
+			{Type: storage.EventClick, SlotID: 1, BannerID: 2, GroupID: 3, Date: 12},
+		}, events)
+	})
+
+	t.Run("invalid line", func(t *testing.T) {
+		err := ReadEvents(context.Background(), strings.NewReader("{}\nnot json\n"), 0, 0, 1, func(storage.Event) error {
+			return nil
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "line 2")
+	})
+}
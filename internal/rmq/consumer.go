@@ -0,0 +1,131 @@
+package rmq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/streadway/amqp"
+)
+
+var (
+	ErrDeliveriesClosed = errors.New("deliveries channel is closed")
+	ErrMessageRejected  = errors.New("message rejected")
+)
+
+// Handler processes a single message. Returning nil acknowledges the message,
+// an error wrapping ErrMessageRejected drops it and any other error requeues it.
+type Handler func(messageID string, message QMessage) error
+
+type Consumer struct {
+	name     string
+	prefetch int
+	conn     Connection
+	channel  *amqp.Channel
+}
+
+func NewRMQConsumer(name string, prefetch int, conn Connection) *Consumer {
+	return &Consumer{name: name, prefetch: prefetch, conn: conn}
+}
+
+func (c *Consumer) Connect() error {
+	ch, err := c.conn.Channel()
+	if err != nil {
+		return fmt.Errorf("rmq get channel -> %w", err)
+	}
+
+	c.channel = ch
+
+	_, err = ch.QueueDeclare(
+		c.name,
+		false,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		return fmt.Errorf("rmq declare queue -> %w", err)
+	}
+
+	if err = ch.Qos(c.prefetch, 0, false); err != nil {
+		return fmt.Errorf("rmq set qos -> %w", err)
+	}
+
+	return nil
+}
+
+// Consume handles the deliveries until the context is done. It returns when
+// the channel is closed or a delivery can not be acknowledged, the broker
+// redelivers the unacknowledged messages after the channel is closed.
+func (c *Consumer) Consume(ctx context.Context, handler Handler) error {
+	if c.channel == nil {
+		return ErrChanNotDeclared
+	}
+
+	deliveries, err := c.channel.Consume(
+		c.name, // queue
+		"",     // consumer
+		false,  // auto-ack
+		false,  // exclusive
+		false,  // no-local
+		false,  // no-wait
+		nil,    // args
+	)
+	if err != nil {
+		return fmt.Errorf("rmq consume -> %w", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case d, ok := <-deliveries:
+			if !ok {
+				return ErrDeliveriesClosed
+			}
+
+			if err = c.handle(d, handler); err != nil {
+				return fmt.Errorf("rmq consume -> %w", err)
+			}
+		}
+	}
+}
+
+func (c *Consumer) Close() error {
+	if c.channel == nil {
+		return nil
+	}
+
+	return c.channel.Close()
+}
+
+func (c *Consumer) handle(d amqp.Delivery, handler Handler) error {
+	encoding, err := deliveryEncoding(d)
+	if err != nil {
+		return d.Reject(false)
+	}
+
+	message, err := encoding.Unmarshal(d.Body)
+	if err != nil {
+		return d.Reject(false)
+	}
+
+	err = handler(d.MessageId, message)
+	switch {
+	case err == nil:
+		return d.Ack(false)
+	case errors.Is(err, ErrMessageRejected):
+		return d.Reject(false)
+	default:
+		return d.Nack(false, true)
+	}
+}
+
+// deliveryEncoding returns the encoding named in the message headers.
+// Messages published before the headers were introduced are JSON.
+func deliveryEncoding(d amqp.Delivery) (Encoding, error) {
+	name, _ := d.Headers[encodingHeader].(string)
+
+	return NewEncoding(name)
+}
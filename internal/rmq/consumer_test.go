@@ -0,0 +1,121 @@
+package rmq
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/require"
+)
+
+type testAcknowledger struct {
+	acked    int
+	nacked   int
+	rejected int
+	requeue  bool
+}
+
+func (a *testAcknowledger) Ack(tag uint64, multiple bool) error {
+	a.acked++
+	return nil
+}
+
+func (a *testAcknowledger) Nack(tag uint64, multiple bool, requeue bool) error {
+	a.nacked++
+	a.requeue = requeue
+	return nil
+}
+
+func (a *testAcknowledger) Reject(tag uint64, requeue bool) error {
+	a.rejected++
+	a.requeue = requeue
+	return nil
+}
+
+func getDelivery(t *testing.T, ack amqp.Acknowledger) amqp.Delivery {
+	t.Helper()
+
+	b, err := json.Marshal(QMessage{Version: SchemaVersion, ID: "id", Type: EventView, SlotID: 1, BannerID: 1, GroupID: 1})
+	require.NoError(t, err)
+
+	return amqp.Delivery{Acknowledger: ack, MessageId: "id", Body: b}
+}
+
+func TestConsumer_handle(t *testing.T) {
+	c := &Consumer{}
+
+	t.Run("ack handled message", func(t *testing.T) {
+		ack := &testAcknowledger{}
+		err := c.handle(getDelivery(t, ack), func(messageID string, message QMessage) error {
+			require.Equal(t, "id", messageID)
+			require.Equal(t, "view", message.Type)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, ack.acked)
+	})
+
+	t.Run("requeue failed message", func(t *testing.T) {
+		ack := &testAcknowledger{}
+		err := c.handle(getDelivery(t, ack), func(messageID string, message QMessage) error {
+			return fmt.Errorf("test error")
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, ack.nacked)
+		require.True(t, ack.requeue)
+	})
+
+	t.Run("drop rejected message", func(t *testing.T) {
+		ack := &testAcknowledger{}
+		err := c.handle(getDelivery(t, ack), func(messageID string, message QMessage) error {
+			return fmt.Errorf("test -> %w", ErrMessageRejected)
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, ack.rejected)
+		require.False(t, ack.requeue)
+	})
+
+	t.Run("decode message by encoding header", func(t *testing.T) {
+		ack := &testAcknowledger{}
+		b, err := protobufEncoding{}.Marshal(QMessage{Version: SchemaVersion, ID: "id", Type: EventClick, SlotID: 2})
+		require.NoError(t, err)
+
+		d := amqp.Delivery{
+			Acknowledger: ack,
+			Headers:      amqp.Table{encodingHeader: EncodingProtobuf},
+			MessageId:    "id",
+			Body:         b,
+		}
+		err = c.handle(d, func(messageID string, message QMessage) error {
+			require.Equal(t, EventClick, message.Type)
+			require.Equal(t, int64(2), message.SlotID)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, ack.acked)
+	})
+
+	t.Run("drop message with unknown encoding", func(t *testing.T) {
+		ack := &testAcknowledger{}
+		d := getDelivery(t, ack)
+		d.Headers = amqp.Table{encodingHeader: "xml"}
+		err := c.handle(d, func(messageID string, message QMessage) error {
+			t.Fatal("handler must not be called")
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, ack.rejected)
+	})
+
+	t.Run("drop malformed message", func(t *testing.T) {
+		ack := &testAcknowledger{}
+		d := amqp.Delivery{Acknowledger: ack, MessageId: "id", Body: []byte("{")}
+		err := c.handle(d, func(messageID string, message QMessage) error {
+			t.Fatal("handler must not be called")
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, ack.rejected)
+	})
+}
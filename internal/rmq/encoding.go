@@ -0,0 +1,138 @@
+//go:generate mkdir -p ./eventspb
+//go:generate protoc -I ../../api/ --go_out=./eventspb/ ../../api/Events.proto
+
+package rmq
+
+import (
+	"banners-rotator/internal/rmq/eventspb"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	EncodingJSON     = "json"
+	EncodingProtobuf = "protobuf"
+)
+
+const (
+	encodingHeader      = "encoding"
+	schemaVersionHeader = "schema-version"
+)
+
+var ErrUnknownEncoding = errors.New("unknown encoding")
+
+type Encoding interface {
+	Name() string
+	ContentType() string
+	Marshal(message QMessage) ([]byte, error)
+	Unmarshal(data []byte) (QMessage, error)
+}
+
+func NewEncoding(name string) (Encoding, error) {
+	switch name {
+	case EncodingJSON, "":
+		return jsonEncoding{}, nil
+	case EncodingProtobuf:
+		return protobufEncoding{}, nil
+	default:
+		return nil, fmt.Errorf("rmq new encoding -> %w (%s)", ErrUnknownEncoding, name)
+	}
+}
+
+type jsonEncoding struct{}
+
+// legacyMessage is the unversioned message which carried the time in seconds.
+type legacyMessage struct {
+	QMessage
+	Date int64 `json:"date"`
+}
+
+func (jsonEncoding) Name() string {
+	return EncodingJSON
+}
+
+func (jsonEncoding) ContentType() string {
+	return "application/json"
+}
+
+func (jsonEncoding) Marshal(message QMessage) ([]byte, error) {
+	return json.Marshal(message)
+}
+
+func (jsonEncoding) Unmarshal(data []byte) (QMessage, error) {
+	var message legacyMessage
+	if err := json.Unmarshal(data, &message); err != nil {
+		return QMessage{}, err
+	}
+
+	if message.Version == 0 {
+		message.Timestamp = message.Date * 1000
+	}
+
+	return message.QMessage, nil
+}
+
+type protobufEncoding struct{}
+
+var eventTypes = map[string]eventspb.EventType{
+	EventView:       eventspb.EventType_EVENT_TYPE_VIEW,
+	EventClick:      eventspb.EventType_EVENT_TYPE_CLICK,
+	EventConversion: eventspb.EventType_EVENT_TYPE_CONVERSION,
+	EventPrune:      eventspb.EventType_EVENT_TYPE_PRUNE,
+}
+
+func (protobufEncoding) Name() string {
+	return EncodingProtobuf
+}
+
+func (protobufEncoding) ContentType() string {
+	return "application/x-protobuf"
+}
+
+func (protobufEncoding) Marshal(message QMessage) ([]byte, error) {
+	return proto.Marshal(&eventspb.Event{
+		SchemaVersion:  uint32(message.Version),
+		Id:             message.ID,
+		ImpressionId:   message.ImpressionID,
+		Type:           eventTypes[message.Type],
+		SlotId:         message.SlotID,
+		BannerId:       message.BannerID,
+		GroupId:        message.GroupID,
+		Timestamp:      message.Timestamp,
+		Instance:       message.Instance,
+		ConversionType: message.ConversionType,
+		Value:          message.Value,
+		LeaderId:       message.LeaderID,
+	})
+}
+
+func (protobufEncoding) Unmarshal(data []byte) (QMessage, error) {
+	var event eventspb.Event
+	if err := proto.Unmarshal(data, &event); err != nil {
+		return QMessage{}, err
+	}
+
+	message := QMessage{
+		Version:        int(event.SchemaVersion),
+		ID:             event.Id,
+		ImpressionID:   event.ImpressionId,
+		SlotID:         event.SlotId,
+		BannerID:       event.BannerId,
+		GroupID:        event.GroupId,
+		Timestamp:      event.Timestamp,
+		Instance:       event.Instance,
+		ConversionType: event.ConversionType,
+		Value:          event.Value,
+		LeaderID:       event.LeaderId,
+	}
+	for name, t := range eventTypes {
+		if t == event.Type {
+			message.Type = name
+		}
+	}
+
+	return message, nil
+}
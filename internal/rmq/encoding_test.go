@@ -0,0 +1,92 @@
+package rmq
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncoding(t *testing.T) {
+	message := QMessage{
+		Version:      SchemaVersion,
+		ID:           "id",
+		ImpressionID: "impression",
+		Type:         EventView,
+		SlotID:       1,
+		BannerID:     2,
+		GroupID:      3,
+		Timestamp:    1642582800123,
+		Instance:     "rotator-1",
+	}
+
+	for _, name := range []string{EncodingJSON, EncodingProtobuf} {
+		name := name
+		t.Run("marshal and unmarshal "+name, func(t *testing.T) {
+			enc, err := NewEncoding(name)
+			require.NoError(t, err)
+			require.Equal(t, name, enc.Name())
+
+			b, err := enc.Marshal(message)
+			require.NoError(t, err)
+
+			result, err := enc.Unmarshal(b)
+			require.NoError(t, err)
+			require.Equal(t, message, result)
+		})
+	}
+
+	conversion := message
+	conversion.Type = EventConversion
+	conversion.ConversionType = "purchase"
+	conversion.Value = 99.5
+
+	for _, name := range []string{EncodingJSON, EncodingProtobuf} {
+		name := name
+		t.Run("marshal and unmarshal conversion "+name, func(t *testing.T) {
+			enc, err := NewEncoding(name)
+			require.NoError(t, err)
+
+			b, err := enc.Marshal(conversion)
+			require.NoError(t, err)
+
+			result, err := enc.Unmarshal(b)
+			require.NoError(t, err)
+			require.Equal(t, conversion, result)
+		})
+	}
+
+	prune := message
+	prune.Type = EventPrune
+	prune.GroupID = 0
+	prune.LeaderID = 4
+	prune.Value = 0.99
+
+	for _, name := range []string{EncodingJSON, EncodingProtobuf} {
+		name := name
+		t.Run("marshal and unmarshal prune "+name, func(t *testing.T) {
+			enc, err := NewEncoding(name)
+			require.NoError(t, err)
+
+			b, err := enc.Marshal(prune)
+			require.NoError(t, err)
+
+			result, err := enc.Unmarshal(b)
+			require.NoError(t, err)
+			require.Equal(t, prune, result)
+		})
+	}
+
+	t.Run("unmarshal legacy json message", func(t *testing.T) {
+		result, err := jsonEncoding{}.Unmarshal(
+			[]byte(`{"type":"click","slotId":1,"bannerId":2,"groupId":3,"date":1642582800}`),
+		)
+		require.NoError(t, err)
+		require.Equal(t, EventClick, result.Type)
+		require.Equal(t, int64(1642582800000), result.Timestamp)
+	})
+
+	t.Run("unknown encoding", func(t *testing.T) {
+		_, err := NewEncoding("xml")
+		require.ErrorIs(t, err, ErrUnknownEncoding)
+	})
+}
@@ -1,14 +1,25 @@
 package rmq
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/streadway/amqp"
 )
 
+// SchemaVersion is the version of the event schema described in api/Events.proto.
+const SchemaVersion = 1
+
+const (
+	EventView       = "view"
+	EventClick      = "click"
+	EventConversion = "conversion"
+	EventPrune      = "prune"
+)
+
 var ErrChanNotDeclared = errors.New("channel is not declared")
 
 type Connection interface {
@@ -16,23 +27,87 @@ type Connection interface {
 }
 
 type QMessage struct {
-	Type     string `json:"type"`
-	SlotID   int64  `json:"slotId"`
-	BannerID int64  `json:"bannerId"`
-	GroupID  int64  `json:"groupId"`
-	Date     int64  `json:"date"`
+	Version      int    `json:"version"`
+	ID           string `json:"id"`
+	ImpressionID string `json:"impressionId,omitempty"`
+	Type         string `json:"type"`
+	SlotID       int64  `json:"slotId"`
+	BannerID     int64  `json:"bannerId"`
+	GroupID      int64  `json:"groupId"`
+	Timestamp    int64  `json:"timestamp"`
+	Instance     string `json:"instance"`
+	// ConversionType and Value are set for conversion events.
+	ConversionType string  `json:"conversionType,omitempty"`
+	Value          float64 `json:"value,omitempty"`
+	// LeaderID is set for prune events, Value is the probability the leader
+	// is better than the paused banner.
+	LeaderID int64 `json:"leaderId,omitempty"`
+}
+
+// WithDefaults returns the message with the current schema version and
+// the ID and instance filled in when they are not set.
+func (m QMessage) WithDefaults(instance string) QMessage {
+	m.Version = SchemaVersion
+	if m.ID == "" {
+		m.ID = uuid.NewString()
+	}
+	if m.Instance == "" {
+		m.Instance = instance
+	}
+
+	return m
+}
+
+// PublishObserver records the outcome of every publish, err is nil for a
+// published message.
+type PublishObserver interface {
+	ObservePublish(err error)
 }
 
+// confirmsCapacity is the buffer of the publisher confirms, a batch waits for
+// the confirms of at most this many messages at once.
+const confirmsCapacity = 1000
+
+// confirmTimeout limits the wait for the broker to confirm a batch.
+const confirmTimeout = 10 * time.Second
+
+var ErrNotConfirmed = errors.New("message is not confirmed by the broker")
+
 type Producer struct {
-	name    string
-	conn    Connection
-	channel *amqp.Channel
+	name     string
+	instance string
+	conn     Connection
+	encoding Encoding
+	channel  *amqp.Channel
+	observer PublishObserver
+
+	// batchMu guards the confirm channel, its delivery tags and confirms.
+	batchMu     sync.Mutex
+	confirmChan *amqp.Channel
+	confirms    chan amqp.Confirmation
+	deliveryTag uint64
 }
 
-func NewRMQProducer(name string, conn Connection) *Producer {
-	return &Producer{name: name, conn: conn}
+type ProducerOption func(p *Producer)
+
+// WithPublishObserver reports the outcome of every publish to the observer.
+func WithPublishObserver(observer PublishObserver) ProducerOption {
+	return func(p *Producer) {
+		p.observer = observer
+	}
+}
+
+func NewRMQProducer(name, instance string, conn Connection, encoding Encoding, opts ...ProducerOption) *Producer {
+	p := &Producer{name: name, instance: instance, conn: conn, encoding: encoding}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
 }
 
+// Connect declares the queue and opens the channels of the producer: the
+// channel of the single messages and the confirm channel of the batches.
 func (p *Producer) Connect() error {
 	ch, err := p.conn.Channel()
 	if err != nil {
@@ -53,34 +128,178 @@ func (p *Producer) Connect() error {
 		return fmt.Errorf("rmq declare queue -> %w", err)
 	}
 
+	confirmChan, err := p.conn.Channel()
+	if err != nil {
+		return fmt.Errorf("rmq get confirm channel -> %w", err)
+	}
+	if err = confirmChan.Confirm(false); err != nil {
+		_ = confirmChan.Close()
+		return fmt.Errorf("rmq enable publisher confirms -> %w", err)
+	}
+
+	p.confirmChan = confirmChan
+	p.confirms = confirmChan.NotifyPublish(make(chan amqp.Confirmation, confirmsCapacity))
+
 	return nil
 }
 
 func (p *Producer) Publish(message QMessage) error {
-	if p.channel != nil {
-		b, err := json.Marshal(message)
-		if err != nil {
-			return fmt.Errorf("rmq marshall message -> %w", err)
+	err := p.publish(p.channel, message.WithDefaults(p.instance))
+	if p.observer != nil {
+		p.observer.ObservePublish(err)
+	}
+
+	return err
+}
+
+// PublishBatch publishes the messages on the confirm channel and waits for
+// the broker to confirm them in one round-trip. The messages which are not
+// confirmed are returned with the error, their IDs are filled in, so a retry
+// is deduplicated by the consumers.
+func (p *Producer) PublishBatch(messages []QMessage) ([]QMessage, error) {
+	withDefaults := make([]QMessage, len(messages))
+	for i, message := range messages {
+		withDefaults[i] = message.WithDefaults(p.instance)
+	}
+
+	p.batchMu.Lock()
+	defer p.batchMu.Unlock()
+
+	var failed []QMessage
+	var firstErr error
+	for start := 0; start < len(withDefaults); start += confirmsCapacity {
+		end := start + confirmsCapacity
+		if end > len(withDefaults) {
+			end = len(withDefaults)
 		}
 
-		err = p.channel.Publish(
-			"",     // exchange
-			p.name, // routing key
-			false,  // mandatory
-			false,  // immediate
-			amqp.Publishing{
-				MessageId:    uuid.NewString(),
-				DeliveryMode: amqp.Persistent,
-				ContentType:  "text/plain",
-				Body:         b,
-			})
-
-		if err != nil {
-			return fmt.Errorf("rmq publish message -> %w", err)
+		chunkFailed, err := p.publishConfirmed(withDefaults[start:end])
+		failed = append(failed, chunkFailed...)
+		if err != nil && firstErr == nil {
+			firstErr = err
 		}
+	}
+
+	if p.observer != nil {
+		for i := len(failed); i < len(withDefaults); i++ {
+			p.observer.ObservePublish(nil)
+		}
+		for range failed {
+			p.observer.ObservePublish(firstErr)
+		}
+	}
 
-		return nil
+	return failed, firstErr
+}
+
+// publishConfirmed publishes the messages and waits for their confirms, it
+// returns the messages which are not published or confirmed.
+func (p *Producer) publishConfirmed(messages []QMessage) ([]QMessage, error) {
+	if p.confirmChan == nil {
+		return messages, ErrChanNotDeclared
+	}
+
+	var publishErr error
+	pending := make(map[uint64]int, len(messages))
+	published := 0
+	for _, message := range messages {
+		if publishErr = p.publish(p.confirmChan, message); publishErr != nil {
+			break
+		}
+		p.deliveryTag++
+		pending[p.deliveryTag] = published
+		published++
+	}
+
+	nacked := make(map[int]bool)
+	timeout := time.NewTimer(confirmTimeout)
+	defer timeout.Stop()
+	for len(pending) > 0 {
+		var confirm amqp.Confirmation
+		var ok bool
+		select {
+		case confirm, ok = <-p.confirms:
+		case <-timeout.C:
+		}
+		if !ok {
+			// the channel is closed or the confirms are late, the late ones
+			// are ignored by their delivery tags
+			break
+		}
+
+		i, known := pending[confirm.DeliveryTag]
+		if !known {
+			continue
+		}
+		delete(pending, confirm.DeliveryTag)
+		if !confirm.Ack {
+			nacked[i] = true
+		}
+	}
+	for _, i := range pending {
+		nacked[i] = true
+	}
+
+	var failed []QMessage
+	for i, message := range messages[:published] {
+		if nacked[i] {
+			failed = append(failed, message)
+		}
+	}
+	failed = append(failed, messages[published:]...)
+
+	if publishErr != nil {
+		return failed, publishErr
+	}
+	if len(failed) > 0 {
+		return failed, fmt.Errorf("rmq publish batch -> %w (%d of %d messages)", ErrNotConfirmed, len(failed), len(messages))
+	}
+
+	return nil, nil
+}
+
+func (p *Producer) publish(ch *amqp.Channel, message QMessage) error {
+	if ch == nil {
+		return ErrChanNotDeclared
+	}
+
+	b, err := p.encoding.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("rmq marshall message -> %w", err)
+	}
+
+	err = ch.Publish(
+		"",     // exchange
+		p.name, // routing key
+		false,  // mandatory
+		false,  // immediate
+		amqp.Publishing{
+			Headers: amqp.Table{
+				encodingHeader:      p.encoding.Name(),
+				schemaVersionHeader: int32(SchemaVersion),
+			},
+			MessageId:    message.ID,
+			DeliveryMode: amqp.Persistent,
+			ContentType:  p.encoding.ContentType(),
+			Body:         b,
+		})
+	if err != nil {
+		return fmt.Errorf("rmq publish message -> %w", err)
+	}
+
+	return nil
+}
+
+func (p *Producer) Close() error {
+	var err error
+	if p.confirmChan != nil {
+		err = p.confirmChan.Close()
+	}
+	if p.channel != nil {
+		if chErr := p.channel.Close(); err == nil {
+			err = chErr
+		}
 	}
 
-	return ErrChanNotDeclared
+	return err
 }
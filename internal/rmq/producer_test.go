@@ -0,0 +1,25 @@
+package rmq
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testPublishObserver struct {
+	errs []error
+}
+
+func (o *testPublishObserver) ObservePublish(err error) {
+	o.errs = append(o.errs, err)
+}
+
+func TestProducer_PublishObserver(t *testing.T) {
+	o := &testPublishObserver{}
+	p := NewRMQProducer("events", "instance", nil, jsonEncoding{}, WithPublishObserver(o))
+
+	err := p.Publish(QMessage{Type: EventView, SlotID: 1, BannerID: 1})
+	require.ErrorIs(t, err, ErrChanNotDeclared)
+	require.Len(t, o.errs, 1)
+	require.ErrorIs(t, o.errs[0], ErrChanNotDeclared)
+}
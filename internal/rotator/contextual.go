@@ -0,0 +1,224 @@
+package rotator
+
+import (
+	"banners-rotator/internal/storage"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// maxModelAttempts limits the retries of a conflicting model update.
+const maxModelAttempts = 5
+
+// maxPendingModelUpdates limits the model updates kept between the flushes.
+const maxPendingModelUpdates = 100000
+
+var (
+	ErrModelUpdatesDropped = errors.New("model updates dropped")
+	errNoModelUpdates      = errors.New("no model updates applied")
+)
+
+// Features describe the request, e.g. device type and country.
+type Features map[string]string
+
+// ContextualBandit chooses the banner by the features with the slot model.
+type ContextualBandit interface {
+	ChooseBanner(banners []storage.Banner, features Features, state []byte) (*storage.Banner, error)
+	// View returns the state after the banner was shown for the features.
+	View(state []byte, bannerID int64, features Features) ([]byte, error)
+	// Reward returns the state after the banner got the reward for the features.
+	Reward(state []byte, bannerID int64, features Features, reward float64) ([]byte, error)
+}
+
+// ContextualBannerForSlot selects the banner with the contextual bandit.
+func (r *Rotator) ContextualBannerForSlot(slotID, groupID int64, features Features) (*storage.Banner, error) {
+	if r.cb == nil || len(features) == 0 {
+		return r.BannerForSlot(slotID, groupID)
+	}
+
+	banners, err := r.storage.SlotBanners(slotID)
+	if err != nil {
+		return nil, fmt.Errorf("rotator -> contextual banner for slot -> %w", err)
+	}
+
+	model, err := r.storage.SlotModel(slotID)
+	if err != nil {
+		return nil, fmt.Errorf("rotator -> contextual banner for slot -> %w", err)
+	}
+
+	banner, err := r.cb.ChooseBanner(*banners, features, model.State)
+	if err != nil {
+		return nil, fmt.Errorf("rotator -> contextual banner for slot -> %w", err)
+	}
+
+	if banner.ImpressionID, err = r.createViewEvent(slotID, banner.ID, groupID); err != nil {
+		return nil, fmt.Errorf("rotator -> contextual banner for slot -> %w", err)
+	}
+
+	bannerID := banner.ID
+	r.models.add(slotID, func(state []byte) ([]byte, error) {
+		return r.cb.View(state, bannerID, features)
+	})
+
+	if r.selections != nil {
+		r.selections.ObserveSelection(slotID, PathContextual)
+	}
+
+	return banner, nil
+}
+
+// CreateContextualClickEvent records the click and queues the model reward.
+func (r *Rotator) CreateContextualClickEvent(
+	slotID, bannerID, groupID int64,
+	features Features,
+	impressionID string,
+) error {
+	if err := r.CreateClickEvent(slotID, bannerID, groupID, impressionID); err != nil {
+		return err
+	}
+	if r.cb == nil || len(features) == 0 {
+		return nil
+	}
+
+	r.models.add(slotID, func(state []byte) ([]byte, error) {
+		return r.cb.Reward(state, bannerID, features, 1)
+	})
+
+	return nil
+}
+
+// FlushModels applies the queued model updates with one save per slot.
+func (r *Rotator) FlushModels() error {
+	r.models.flushMu.Lock()
+	defer r.models.flushMu.Unlock()
+
+	var (
+		dropped  = r.models.takeDropped()
+		firstErr error
+	)
+	for slotID, updates := range r.models.take() {
+		var rejected int
+		err := r.updateModel(slotID, func(state []byte) ([]byte, error) {
+			rejected = 0
+			for _, update := range updates {
+				next, err := update(state)
+				if err != nil {
+					rejected++
+					continue
+				}
+				state = next
+			}
+			if rejected == len(updates) {
+				return nil, errNoModelUpdates
+			}
+
+			return state, nil
+		})
+		if errors.Is(err, errNoModelUpdates) {
+			dropped += rejected
+			continue
+		}
+		if err != nil {
+			r.models.keep(slotID, updates)
+			if firstErr == nil {
+				firstErr = fmt.Errorf("rotator -> flush models -> %w", err)
+			}
+			continue
+		}
+		dropped += rejected
+	}
+
+	if firstErr != nil {
+		return firstErr
+	}
+	if dropped > 0 {
+		return fmt.Errorf("rotator -> flush models -> %w (%d updates)", ErrModelUpdatesDropped, dropped)
+	}
+
+	return nil
+}
+
+// updateModel applies the update to the slot model, retrying on conflicts.
+func (r *Rotator) updateModel(slotID int64, update func(state []byte) ([]byte, error)) error {
+	var err error
+	for attempt := 0; attempt < maxModelAttempts; attempt++ {
+		var model *storage.SlotModel
+		model, err = r.storage.SlotModel(slotID)
+		if err != nil {
+			return fmt.Errorf("update model -> %w", err)
+		}
+
+		model.State, err = update(model.State)
+		if err != nil {
+			return fmt.Errorf("update model -> %w", err)
+		}
+
+		err = r.storage.SaveSlotModel(*model)
+		if !errors.Is(err, storage.ErrModelConflict) {
+			break
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("update model -> %w", err)
+	}
+
+	return nil
+}
+
+type modelUpdate func(state []byte) ([]byte, error)
+
+// modelQueue keeps the model updates of the slots until the flush.
+type modelQueue struct {
+	flushMu sync.Mutex
+	mu      sync.Mutex
+	pending map[int64][]modelUpdate
+	size    int
+	dropped int
+}
+
+func newModelQueue() *modelQueue {
+	return &modelQueue{pending: make(map[int64][]modelUpdate)}
+}
+
+func (q *modelQueue) add(slotID int64, update modelUpdate) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.size >= maxPendingModelUpdates {
+		q.dropped++
+
+		return
+	}
+	q.pending[slotID] = append(q.pending[slotID], update)
+	q.size++
+}
+
+func (q *modelQueue) take() map[int64][]modelUpdate {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	pending := q.pending
+	q.pending = make(map[int64][]modelUpdate)
+	q.size = 0
+
+	return pending
+}
+
+// keep puts the updates back in front of the updates added meanwhile.
+func (q *modelQueue) keep(slotID int64, updates []modelUpdate) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.pending[slotID] = append(updates, q.pending[slotID]...)
+	q.size += len(updates)
+}
+
+func (q *modelQueue) takeDropped() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	dropped := q.dropped
+	q.dropped = 0
+
+	return dropped
+}
@@ -0,0 +1,285 @@
+package rotator
+
+import (
+	"banners-rotator/internal/storage"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// ExperimentConfidence is the confidence level of the intervals of the
+	// experiment results.
+	ExperimentConfidence = 0.95
+	// zCritical is the two-sided critical value of the standard normal
+	// distribution for ExperimentConfidence.
+	zCritical = 1.959963984540054
+	// totalShare is the sum of the shares of the arms of an experiment.
+	totalShare = 100
+)
+
+var (
+	ErrInvalidExperiment = errors.New(
+		"invalid experiment, expected two or more banners of the slot with positive shares summing up to 100",
+	)
+	ErrExperimentRunning = errors.New("slot has an active experiment")
+)
+
+// ArmResult is the CTR of an arm of the experiment with its Wilson score
+// interval [Low, High].
+type ArmResult struct {
+	storage.ExperimentArm
+	Views  int64
+	Clicks int64
+	CTR    float64
+	Low    float64
+	High   float64
+}
+
+// Comparison is the difference of the CTR of the banner and the other one
+// with its interval [Low, High] and the p-value of the two-sided
+// two-proportion z-test of equal CTRs.
+type Comparison struct {
+	BannerID   int64
+	OtherID    int64
+	Difference float64
+	Low        float64
+	High       float64
+	PValue     float64
+}
+
+// ExperimentResults reports the arms of the experiment in the order of banner
+// IDs and compares every pair of them.
+type ExperimentResults struct {
+	Experiment  storage.Experiment
+	Confidence  float64
+	Arms        []ArmResult
+	Comparisons []Comparison
+}
+
+// StartExperiment switches the slot to the experiment mode: the traffic is
+// split between the banners of the arms by their shares in percent, the
+// bandit is not used until the experiment ends.
+func (r *Rotator) StartExperiment(slotID int64, arms []storage.ExperimentArm) (*storage.Experiment, error) {
+	if err := r.checkArms(slotID, arms); err != nil {
+		return nil, fmt.Errorf("rotator -> start experiment -> %w", err)
+	}
+
+	_, err := r.storage.ActiveExperiment(slotID)
+	if err == nil {
+		return nil, fmt.Errorf("rotator -> start experiment -> %w (slot %d)", ErrExperimentRunning, slotID)
+	}
+	if !errors.Is(err, storage.ErrExperimentNotFound) {
+		return nil, fmt.Errorf("rotator -> start experiment -> %w", err)
+	}
+
+	experiment, err := r.storage.CreateExperiment(storage.Experiment{
+		SlotID:    slotID,
+		StartedAt: time.Now().Unix(),
+		Arms:      arms,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("rotator -> start experiment -> %w", err)
+	}
+
+	return experiment, nil
+}
+
+// EndExperiment ends the experiment and switches its slot back to the bandit.
+func (r *Rotator) EndExperiment(experimentID int64) (*storage.Experiment, error) {
+	if err := r.storage.EndExperiment(experimentID, time.Now().Unix()); err != nil {
+		return nil, fmt.Errorf("rotator -> end experiment -> %w", err)
+	}
+
+	experiment, err := r.storage.Experiment(experimentID)
+	if err != nil {
+		return nil, fmt.Errorf("rotator -> end experiment -> %w", err)
+	}
+
+	return experiment, nil
+}
+
+// ExperimentResults counts the views and clicks of the arms from the start
+// to the end of the experiment, or up to now for the active one.
+func (r *Rotator) ExperimentResults(experimentID int64) (*ExperimentResults, error) {
+	experiment, err := r.storage.Experiment(experimentID)
+	if err != nil {
+		return nil, fmt.Errorf("rotator -> experiment results -> %w", err)
+	}
+
+	to := experiment.EndedAt
+	if to == 0 {
+		to = math.MaxInt64
+	}
+	stats, err := r.storage.SlotStats(experiment.SlotID, experiment.StartedAt, to, false)
+	if err != nil {
+		return nil, fmt.Errorf("rotator -> experiment results -> %w", err)
+	}
+
+	byBanner := make(map[int64]storage.BannerStats, len(*stats))
+	for _, s := range *stats {
+		byBanner[s.BannerID] = s
+	}
+
+	result := &ExperimentResults{
+		Experiment: *experiment,
+		Confidence: ExperimentConfidence,
+		Arms:       make([]ArmResult, 0, len(experiment.Arms)),
+	}
+	for _, arm := range experiment.Arms {
+		s := byBanner[arm.BannerID]
+		a := ArmResult{ExperimentArm: arm, Views: s.Views, Clicks: s.Clicks}
+		a.CTR, a.Low, a.High = wilsonInterval(s.Clicks, s.Views)
+		result.Arms = append(result.Arms, a)
+	}
+
+	for i, a := range result.Arms {
+		for _, b := range result.Arms[i+1:] {
+			result.Comparisons = append(result.Comparisons, compareArms(b, a))
+		}
+	}
+
+	return result, nil
+}
+
+// BannerForVisitor selects the banner for the request of the visitor. During
+// an experiment the visitor is assigned to an arm by the hash of the visitor
+// ID, so the visitor keeps seeing the same banner, a request without the ID
+// is assigned randomly. Without an experiment, or when the banner of the arm
+// has left the rotation, the banner is selected by ContextualBannerForSlot.
+func (r *Rotator) BannerForVisitor(
+	slotID, groupID int64,
+	visitorID string,
+	features Features,
+) (*storage.Banner, error) {
+	experiment, err := r.storage.ActiveExperiment(slotID)
+	if errors.Is(err, storage.ErrExperimentNotFound) {
+		return r.ContextualBannerForSlot(slotID, groupID, features)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("rotator -> banner for visitor -> %w", err)
+	}
+
+	if visitorID == "" {
+		visitorID = uuid.NewString()
+	}
+	bannerID := assignArm(*experiment, visitorID)
+
+	banners, err := r.storage.SlotBanners(slotID)
+	if err != nil {
+		return nil, fmt.Errorf("rotator -> banner for visitor -> %w", err)
+	}
+	for _, banner := range *banners {
+		if banner.ID != bannerID {
+			continue
+		}
+
+		if banner.ImpressionID, err = r.createViewEvent(slotID, banner.ID, groupID); err != nil {
+			return nil, fmt.Errorf("rotator -> banner for visitor -> %w", err)
+		}
+
+		if r.selections != nil {
+			r.selections.ObserveSelection(slotID, PathExperiment)
+		}
+
+		return &banner, nil
+	}
+
+	return r.ContextualBannerForSlot(slotID, groupID, features)
+}
+
+// checkArms validates the shares of the arms and that their banners are in
+// the rotation of the slot.
+func (r *Rotator) checkArms(slotID int64, arms []storage.ExperimentArm) error {
+	if len(arms) < 2 {
+		return fmt.Errorf("%w (%d arms)", ErrInvalidExperiment, len(arms))
+	}
+
+	banners, err := r.storage.SlotBanners(slotID)
+	if err != nil {
+		return err
+	}
+	rotated := make(map[int64]bool, len(*banners))
+	for _, banner := range *banners {
+		rotated[banner.ID] = true
+	}
+
+	var total int64
+	seen := make(map[int64]bool, len(arms))
+	for _, arm := range arms {
+		if arm.Share <= 0 || seen[arm.BannerID] || !rotated[arm.BannerID] {
+			return fmt.Errorf("%w (banner %d, share %d)", ErrInvalidExperiment, arm.BannerID, arm.Share)
+		}
+		seen[arm.BannerID] = true
+		total += arm.Share
+	}
+	if total != totalShare {
+		return fmt.Errorf("%w (total share %d)", ErrInvalidExperiment, total)
+	}
+
+	return nil
+}
+
+// assignArm maps the hash of the experiment and visitor IDs to one of the
+// totalShare buckets, the arms take the buckets by their shares. The
+// experiment ID is hashed too, so the visitors are split anew in every
+// experiment.
+func assignArm(experiment storage.Experiment, visitorID string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(strconv.FormatInt(experiment.ID, 10) + ":" + visitorID))
+	bucket := int64(h.Sum64() % totalShare)
+
+	for _, arm := range experiment.Arms {
+		if bucket < arm.Share {
+			return arm.BannerID
+		}
+		bucket -= arm.Share
+	}
+
+	return experiment.Arms[len(experiment.Arms)-1].BannerID
+}
+
+// wilsonInterval is the CTR and its Wilson score interval, an arm without
+// views gets the whole [0, 1].
+func wilsonInterval(clicks, views int64) (ctr, low, high float64) {
+	if views <= 0 {
+		return 0, 0, 1
+	}
+
+	n := float64(views)
+	p := math.Min(float64(clicks), n) / n
+	z2 := zCritical * zCritical
+	center := (p + z2/(2*n)) / (1 + z2/n)
+	margin := zCritical / (1 + z2/n) * math.Sqrt(p*(1-p)/n+z2/(4*n*n))
+
+	return p, math.Max(center-margin, 0), math.Min(center+margin, 1)
+}
+
+// compareArms compares the CTR of a with the CTR of b. The interval of the
+// difference uses the unpooled standard error, the z-test the pooled one.
+func compareArms(a, b ArmResult) Comparison {
+	c := Comparison{BannerID: a.BannerID, OtherID: b.BannerID, Difference: a.CTR - b.CTR, Low: -1, High: 1, PValue: 1}
+	if a.Views <= 0 || b.Views <= 0 {
+		c.Difference = 0
+
+		return c
+	}
+
+	n1, n2 := float64(a.Views), float64(b.Views)
+	se := math.Sqrt(a.CTR*(1-a.CTR)/n1 + b.CTR*(1-b.CTR)/n2)
+	c.Low = math.Max(c.Difference-zCritical*se, -1)
+	c.High = math.Min(c.Difference+zCritical*se, 1)
+
+	pooled := (a.CTR*n1 + b.CTR*n2) / (n1 + n2)
+	pooledSE := math.Sqrt(pooled * (1 - pooled) * (1/n1 + 1/n2))
+	if pooledSE > 0 {
+		c.PValue = math.Erfc(math.Abs(c.Difference) / pooledSE / math.Sqrt2)
+	}
+
+	return c
+}
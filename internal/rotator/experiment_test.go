@@ -0,0 +1,50 @@
+package rotator
+
+import (
+	"banners-rotator/internal/storage"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWilsonInterval(t *testing.T) {
+	ctr, low, high := wilsonInterval(50, 100)
+	require.Equal(t, 0.5, ctr)
+	require.InDelta(t, 0.4038, low, 1e-4)
+	require.InDelta(t, 0.5962, high, 1e-4)
+
+	ctr, low, high = wilsonInterval(0, 0)
+	require.Equal(t, []float64{0, 0, 1}, []float64{ctr, low, high})
+
+	ctr, low, _ = wilsonInterval(0, 10)
+	require.Equal(t, []float64{0, 0}, []float64{ctr, low})
+}
+
+func TestCompareArms(t *testing.T) {
+	a := ArmResult{ExperimentArm: storage.ExperimentArm{BannerID: 1}, Views: 100, Clicks: 60, CTR: 0.6}
+	b := ArmResult{ExperimentArm: storage.ExperimentArm{BannerID: 2}, Views: 100, Clicks: 40, CTR: 0.4}
+
+	c := compareArms(a, b)
+	require.InDelta(t, 0.2, c.Difference, 1e-9)
+	require.InDelta(t, 0.0642, c.Low, 1e-4)
+	require.InDelta(t, 0.3358, c.High, 1e-4)
+	require.InDelta(t, 0.00468, c.PValue, 1e-5)
+
+	c = compareArms(a, ArmResult{})
+	require.Equal(t, Comparison{BannerID: 1, Low: -1, High: 1, PValue: 1}, c)
+}
+
+func TestAssignArm(t *testing.T) {
+	experiment := storage.Experiment{
+		ID:   1,
+		Arms: []storage.ExperimentArm{{BannerID: 1, Share: 1}, {BannerID: 2, Share: 99}},
+	}
+
+	counts := make(map[int64]int)
+	for i := 0; i < 1000; i++ {
+		counts[assignArm(experiment, fmt.Sprintf("visitor-%d", i))]++
+	}
+	require.Less(t, counts[1], 50)
+	require.Equal(t, 1000, counts[1]+counts[2])
+}
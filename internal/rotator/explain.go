@@ -0,0 +1,119 @@
+package rotator
+
+import (
+	"banners-rotator/internal/storage"
+	"errors"
+	"fmt"
+)
+
+const (
+	// RuleNotViewed picks a random banner among the banners without views
+	// and prior, their score is infinite.
+	RuleNotViewed = "not_viewed"
+	// RuleTopRated picks the banner with the top score.
+	RuleTopRated = "top_rated"
+	// RuleExperiment picks the banner of the experiment arm the visitor is
+	// assigned to, a visitor without an ID is assigned randomly.
+	RuleExperiment = "experiment"
+)
+
+type Candidate struct {
+	BannerScore
+	NotViewed bool
+}
+
+// Explanation is a dry run of BannerForSlot: the candidates with their
+// scores, the rule and the banner it would choose. The rules pick randomly
+// among equal banners, so the choice may differ from the next real one.
+// ExperimentID is set while the slot is in an experiment, the candidates are
+// still scored by the bandit, which serves the slot once it ends.
+type Explanation struct {
+	Candidates   []Candidate
+	Rule         string
+	Chosen       *storage.Banner
+	ExperimentID int64
+}
+
+// ExplainBannerForSlot selects the banner for the request of the visitor in
+// the group the way BannerForVisitor does, without recording a view. The
+// events are narrowed to the group as in BannerForSlot, zero groupID scores
+// the banners by all events of the slot, as a top-level group does. During an
+// experiment the banner of the arm of the visitor is chosen, the choice for a
+// visitor without an ID is random and Chosen is nil.
+func (r *Rotator) ExplainBannerForSlot(slotID, groupID int64, visitorID string) (*Explanation, error) {
+	banners, err := r.storage.SlotBanners(slotID)
+	if err != nil {
+		return nil, fmt.Errorf("rotator -> explain banner for slot -> %w", err)
+	}
+
+	views, err := r.storage.SlotViews(slotID)
+	if err != nil {
+		return nil, fmt.Errorf("rotator -> explain banner for slot -> %w", err)
+	}
+
+	rewards, err := r.slotRewards(slotID)
+	if err != nil {
+		return nil, fmt.Errorf("rotator -> explain banner for slot -> %w", err)
+	}
+
+	priors, err := r.slotPriors(slotID)
+	if err != nil {
+		return nil, fmt.Errorf("rotator -> explain banner for slot -> %w", err)
+	}
+
+	groupViews := *views
+	if groupID != 0 {
+		groupViews, rewards, priors, err = r.blendGroup(groupID, *views, rewards, priors)
+		if err != nil {
+			return nil, fmt.Errorf("rotator -> explain banner for slot -> %w", err)
+		}
+	}
+
+	notViewed := make(map[int64]bool, len(*banners))
+	scores := r.b.Scores(*banners, groupViews, rewards, priors)
+	explanation := &Explanation{Candidates: make([]Candidate, 0, len(scores))}
+	for _, score := range scores {
+		candidate := Candidate{BannerScore: score, NotViewed: score.Views == 0 && score.Prior.Views == 0}
+		notViewed[score.Banner.ID] = candidate.NotViewed
+		explanation.Candidates = append(explanation.Candidates, candidate)
+	}
+
+	banner, err := r.b.TopRatedBanner(*banners, groupViews, rewards, priors)
+	if err != nil {
+		return nil, fmt.Errorf("rotator -> explain banner for slot -> %w", err)
+	}
+	explanation.Rule = RuleTopRated
+	if notViewed[banner.ID] {
+		explanation.Rule = RuleNotViewed
+	}
+	explanation.Chosen = banner
+
+	experiment, err := r.storage.ActiveExperiment(slotID)
+	if errors.Is(err, storage.ErrExperimentNotFound) {
+		return explanation, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("rotator -> explain banner for slot -> %w", err)
+	}
+	explanation.ExperimentID = experiment.ID
+
+	if visitorID == "" {
+		explanation.Rule = RuleExperiment
+		explanation.Chosen = nil
+
+		return explanation, nil
+	}
+
+	// the banner of the arm which has left the rotation falls back to the
+	// bandit, as in BannerForVisitor
+	bannerID := assignArm(*experiment, visitorID)
+	for _, banner := range *banners {
+		if banner.ID == bannerID {
+			banner := banner
+			explanation.Rule = RuleExperiment
+			explanation.Chosen = &banner
+		}
+	}
+
+	return explanation, nil
+}
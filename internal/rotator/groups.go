@@ -0,0 +1,294 @@
+package rotator
+
+import (
+	"banners-rotator/internal/storage"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultGroupMinViews is the views a group needs to stop borrowing from its parent.
+const DefaultGroupMinViews = 1000
+
+// groupsCacheTTL limits the age of the cached group parents.
+const groupsCacheTTL = time.Minute
+
+var ErrInvalidGroupParent = errors.New("invalid group parent, the group can not be its own ancestor")
+
+// WithGroupMinViews sets the views a group needs to stop borrowing from its parent.
+func WithGroupMinViews(views int64) Option {
+	return func(r *Rotator) {
+		r.groupMinViews = views
+	}
+}
+
+// CreateGroupWithParent creates the group under the parent, zero is none.
+func (r *Rotator) CreateGroupWithParent(description string, parentID int64) (*storage.Group, error) {
+	group, err := r.storage.CreateGroupWithParent(strings.TrimSpace(description), parentID)
+	if err != nil {
+		return nil, fmt.Errorf("rotator -> create group with parent -> %w", err)
+	}
+	r.groups.reset()
+
+	return group, nil
+}
+
+// SetGroupParent puts the group under the parent, zero is none.
+func (r *Rotator) SetGroupParent(groupID, parentID int64) error {
+	if parentID == groupID {
+		return fmt.Errorf("rotator -> set group parent -> %w (group %d)", ErrInvalidGroupParent, groupID)
+	}
+	if parentID != 0 {
+		groups, err := r.loadGroupParents()
+		if err != nil {
+			return fmt.Errorf("rotator -> set group parent -> %w", err)
+		}
+
+		for _, id := range ancestors(groups, parentID) {
+			if id == groupID {
+				return fmt.Errorf(
+					"rotator -> set group parent -> %w (group %d, parent %d)",
+					ErrInvalidGroupParent,
+					groupID,
+					parentID,
+				)
+			}
+		}
+	}
+
+	if err := r.storage.SetGroupParent(groupID, parentID); err != nil {
+		return fmt.Errorf("rotator -> set group parent -> %w", err)
+	}
+	r.groups.reset()
+
+	return nil
+}
+
+func (r *Rotator) Group(groupID int64) (*storage.Group, error) {
+	group, err := r.storage.Group(groupID)
+	if err != nil {
+		return nil, fmt.Errorf("rotator -> group -> %w", err)
+	}
+
+	return group, nil
+}
+
+func (r *Rotator) Groups() ([]storage.Group, error) {
+	groups, err := r.storage.Groups()
+	if err != nil {
+		return nil, fmt.Errorf("rotator -> groups -> %w", err)
+	}
+
+	return *groups, nil
+}
+
+// counts are the views and rewards per banner.
+type counts map[int64]*struct{ views, rewards float64 }
+
+func (c counts) add(bannerID int64, views, rewards float64) {
+	v, ok := c[bannerID]
+	if !ok {
+		v = &struct{ views, rewards float64 }{}
+		c[bannerID] = v
+	}
+	v.views += views
+	v.rewards += rewards
+}
+
+// without returns c minus the other counts.
+func (c counts) without(other counts) counts {
+	result := make(counts, len(c))
+	for bannerID, v := range c {
+		result.add(bannerID, v.views, v.rewards)
+		if o, ok := other[bannerID]; ok {
+			result.add(bannerID, -o.views, -o.rewards)
+		}
+	}
+
+	return result
+}
+
+// blend returns c plus the other counts multiplied by the weight.
+func (c counts) blend(other counts, weight float64) counts {
+	result := make(counts, len(c)+len(other))
+	for bannerID, v := range c {
+		result.add(bannerID, v.views, v.rewards)
+	}
+	if weight > 0 {
+		for bannerID, v := range other {
+			result.add(bannerID, weight*v.views, weight*v.rewards)
+		}
+	}
+
+	return result
+}
+
+// blendGroup narrows the events to the group with the parent statistics as priors.
+func (r *Rotator) blendGroup(
+	groupID int64,
+	views []storage.ViewEvent,
+	rewards []Reward,
+	priors map[int64]storage.Prior,
+) ([]storage.ViewEvent, []Reward, map[int64]storage.Prior, error) {
+	parents, err := r.groupParents(groupID)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("blend group -> %w", err)
+	}
+	parentID, ok := parents[groupID]
+	if !ok {
+		return nil, nil, nil, fmt.Errorf("blend group -> %w (%d)", storage.ErrGroupNotFound, groupID)
+	}
+	if parentID == 0 {
+		return views, rewards, priors, nil
+	}
+
+	// own[i] counts the events of the subtree of chain[i]
+	chain := append([]int64{groupID}, ancestors(parents, groupID)...)
+	levels := make(map[int64]int, len(chain))
+	for i, id := range chain {
+		levels[id] = i
+	}
+	// the lowest level of the chain above the group, len(chain) if none
+	level := func(groupID int64) int {
+		if i, ok := levels[groupID]; ok {
+			return i
+		}
+
+		i := len(chain)
+		for _, id := range ancestors(parents, groupID) {
+			if l, ok := levels[id]; ok {
+				i = l
+				break
+			}
+		}
+		levels[groupID] = i
+
+		return i
+	}
+
+	slot := make(counts)
+	own := make([]counts, len(chain))
+	for i := range own {
+		own[i] = make(counts)
+	}
+	var groupViews []storage.ViewEvent
+	for _, v := range views {
+		slot.add(v.BannerID, 1, 0)
+		i := level(v.GroupID)
+		for j := i; j < len(chain); j++ {
+			own[j].add(v.BannerID, 1, 0)
+		}
+		if i == 0 {
+			groupViews = append(groupViews, v)
+		}
+	}
+	var groupRewards []Reward
+	for _, reward := range rewards {
+		slot.add(reward.BannerID, 0, reward.Value)
+		i := level(reward.GroupID)
+		for j := i; j < len(chain); j++ {
+			own[j].add(reward.BannerID, 0, reward.Value)
+		}
+		if i == 0 {
+			groupRewards = append(groupRewards, reward)
+		}
+	}
+
+	// the own events plus the parent statistics without them
+	scored := slot
+	for i := len(chain) - 2; i >= 1; i-- {
+		scored = own[i].blend(scored.without(own[i]), r.groupWeight(own[i]))
+	}
+	borrowed := counts{}.blend(scored.without(own[0]), r.groupWeight(own[0]))
+
+	result := make(map[int64]storage.Prior, len(priors)+len(borrowed))
+	for bannerID, p := range priors {
+		result[bannerID] = p
+	}
+	for bannerID, c := range borrowed {
+		p := result[bannerID]
+		v := math.Round(c.views)
+		p.Views += int64(v)
+		p.Rewards += math.Min(c.rewards, v)
+		result[bannerID] = p
+	}
+
+	return groupViews, groupRewards, result, nil
+}
+
+// groupWeight is the weight of the parent statistics for the subtree counts.
+func (r *Rotator) groupWeight(c counts) float64 {
+	if r.groupMinViews <= 0 {
+		return 0
+	}
+
+	var views float64
+	for _, v := range c {
+		views += v.views
+	}
+
+	return math.Max(1-views/float64(r.groupMinViews), 0)
+}
+
+// groupCache keeps the parents of the groups.
+type groupCache struct {
+	mu      sync.Mutex
+	parents map[int64]int64
+	loaded  time.Time
+}
+
+func (c *groupCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.parents = nil
+}
+
+// groupParents maps the groups to their parents from the cache.
+func (r *Rotator) groupParents(groupID int64) (map[int64]int64, error) {
+	r.groups.mu.Lock()
+	defer r.groups.mu.Unlock()
+
+	if _, ok := r.groups.parents[groupID]; ok && time.Since(r.groups.loaded) < groupsCacheTTL {
+		return r.groups.parents, nil
+	}
+
+	parents, err := r.loadGroupParents()
+	if err != nil {
+		return nil, err
+	}
+	r.groups.parents = parents
+	r.groups.loaded = time.Now()
+
+	return parents, nil
+}
+
+// loadGroupParents maps the groups to their parents.
+func (r *Rotator) loadGroupParents() (map[int64]int64, error) {
+	groups, err := r.storage.Groups()
+	if err != nil {
+		return nil, err
+	}
+
+	parents := make(map[int64]int64, len(*groups))
+	for _, g := range *groups {
+		parents[g.ID] = g.ParentID
+	}
+
+	return parents, nil
+}
+
+// ancestors lists the parents of the group up to a top-level one.
+func ancestors(parents map[int64]int64, groupID int64) []int64 {
+	var result []int64
+	seen := map[int64]bool{groupID: true}
+	for id := parents[groupID]; id != 0 && !seen[id]; id = parents[id] {
+		seen[id] = true
+		result = append(result, id)
+	}
+
+	return result
+}
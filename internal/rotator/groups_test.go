@@ -0,0 +1,79 @@
+package rotator
+
+import (
+	"banners-rotator/internal/storage"
+	memorystorage "banners-rotator/internal/storage/memory"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotator_blendGroup(t *testing.T) {
+	s := memorystorage.NewStorage()
+	r := &Rotator{storage: s, groupMinViews: 100}
+
+	parent, _ := s.CreateGroup("parent")
+	child, _ := s.CreateGroup("child")
+	grandchild, _ := s.CreateGroup("grandchild")
+	other, _ := s.CreateGroup("other")
+	require.NoError(t, s.SetGroupParent(child.ID, parent.ID))
+	require.NoError(t, s.SetGroupParent(grandchild.ID, child.ID))
+
+	events := func(groupID int64, n int) []storage.ViewEvent {
+		views := make([]storage.ViewEvent, n)
+		for i := range views {
+			views[i] = storage.ViewEvent{BannerID: 1, GroupID: groupID}
+		}
+
+		return views
+	}
+	var views []storage.ViewEvent
+	views = append(views, events(parent.ID, 100)...)
+	views = append(views, events(other.ID, 100)...)
+	views = append(views, events(child.ID, 30)...)
+	views = append(views, events(grandchild.ID, 20)...)
+	rewards := []Reward{{BannerID: 1, GroupID: parent.ID, Value: 1}, {BannerID: 1, GroupID: grandchild.ID, Value: 1}}
+	priors := map[int64]storage.Prior{2: {Views: 10, Rewards: 1}}
+
+	t.Run("top-level group", func(t *testing.T) {
+		v, rw, p, err := r.blendGroup(parent.ID, views, rewards, priors)
+		require.NoError(t, err)
+		require.Len(t, v, len(views))
+		require.Len(t, rw, 2)
+		require.Equal(t, priors, p)
+	})
+
+	t.Run("child group", func(t *testing.T) {
+		// the subtree of the child has 50 views, the rest of the slot has 200
+		v, rw, p, err := r.blendGroup(child.ID, views, rewards, priors)
+		require.NoError(t, err)
+		require.Len(t, v, 50)
+		require.Len(t, rw, 1)
+		require.Equal(t, storage.Prior{Views: 100, Rewards: 0.5}, p[1])
+		require.Equal(t, priors[2], p[2])
+	})
+
+	t.Run("grandchild group", func(t *testing.T) {
+		// the child is scored by 50 own views with 1 reward and 100 borrowed
+		// views with 0.5 rewards, the grandchild borrows 80% of them without
+		// its own 20 views with 1 reward
+		v, rw, p, err := r.blendGroup(grandchild.ID, views, rewards, priors)
+		require.NoError(t, err)
+		require.Len(t, v, 20)
+		require.Len(t, rw, 1)
+		require.Equal(t, int64(104), p[1].Views)
+		require.InDelta(t, 0.4, p[1].Rewards, 1e-9)
+	})
+
+	t.Run("group with enough views", func(t *testing.T) {
+		r := &Rotator{storage: s, groupMinViews: 50}
+		_, _, p, err := r.blendGroup(child.ID, views, rewards, priors)
+		require.NoError(t, err)
+		require.Equal(t, priors, p)
+	})
+
+	t.Run("unknown group", func(t *testing.T) {
+		_, _, _, err := r.blendGroup(100, views, rewards, priors)
+		require.ErrorIs(t, err, storage.ErrGroupNotFound)
+	})
+}
@@ -0,0 +1,81 @@
+package rotator
+
+import (
+	"banners-rotator/internal/storage"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// DefaultInheritedViews is the weight of an inherited prior without views.
+const DefaultInheritedViews = 100
+
+var ErrInvalidPrior = errors.New("invalid prior, expected 0 <= rewards <= views")
+
+// Prior is the warm start of a banner added to a slot.
+type Prior struct {
+	Views   int64
+	Rewards float64
+	Inherit bool
+}
+
+func (r *Rotator) CreateRotation(slotID, bannerID int64, prior Prior) error {
+	p, err := r.rotationPrior(slotID, bannerID, prior)
+	if err != nil {
+		return fmt.Errorf("rotator -> create rotation -> %w", err)
+	}
+
+	if err = r.storage.CreateRotationWithPrior(slotID, bannerID, p); err != nil {
+		return fmt.Errorf("rotator -> create rotation -> %w", err)
+	}
+
+	return nil
+}
+
+// rotationPrior validates the prior and resolves the inherited one.
+func (r *Rotator) rotationPrior(slotID, bannerID int64, prior Prior) (storage.Prior, error) {
+	if prior.Views < 0 || prior.Rewards < 0 || math.IsNaN(prior.Rewards) || math.IsInf(prior.Rewards, 0) {
+		return storage.Prior{}, fmt.Errorf("%w (%d views, %v rewards)", ErrInvalidPrior, prior.Views, prior.Rewards)
+	}
+
+	if !prior.Inherit {
+		if prior.Rewards > float64(prior.Views) {
+			return storage.Prior{}, fmt.Errorf("%w (%d views, %v rewards)", ErrInvalidPrior, prior.Views, prior.Rewards)
+		}
+
+		return storage.Prior{Views: prior.Views, Rewards: prior.Rewards}, nil
+	}
+
+	stats, err := r.storage.BannerStats(bannerID, slotID)
+	if err != nil {
+		return storage.Prior{}, err
+	}
+	if stats.Views == 0 {
+		return storage.Prior{}, nil
+	}
+
+	views := prior.Views
+	if views == 0 {
+		views = DefaultInheritedViews
+	}
+	if stats.Views < views {
+		views = stats.Views
+	}
+	clicks := math.Min(float64(stats.Clicks), float64(stats.Views))
+
+	return storage.Prior{Views: views, Rewards: clicks * float64(views) / float64(stats.Views)}, nil
+}
+
+func (r *Rotator) slotPriors(slotID int64) (map[int64]storage.Prior, error) {
+	priors, err := r.storage.SlotPriors(slotID)
+	if err != nil {
+		return nil, fmt.Errorf("slot priors -> %w", err)
+	}
+
+	result := make(map[int64]storage.Prior, len(*priors))
+	for _, p := range *priors {
+		result[p.BannerID] = p.Prior
+	}
+
+	return result, nil
+}
@@ -0,0 +1,304 @@
+package rotator
+
+import (
+	"banners-rotator/internal/rmq"
+	"banners-rotator/internal/storage"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// posteriorGridSize is the number of points the posteriors are integrated on.
+const posteriorGridSize = 2000
+
+// MinPruningViews is the least views a banner needs to be compared.
+const MinPruningViews = 100
+
+var ErrInvalidPruningPolicy = errors.New(
+	"invalid pruning policy, expected 0.5 <= confidence < 1 and min views of 0 or at least 100",
+)
+
+// SetSlotPruningPolicy enables or disables the pruning of the slot.
+func (r *Rotator) SetSlotPruningPolicy(policy storage.PruningPolicy) error {
+	if policy.MinViews == 0 {
+		policy.MinViews = MinPruningViews
+	}
+	if policy.MinViews < MinPruningViews || math.IsNaN(policy.Confidence) ||
+		policy.Enabled && (policy.Confidence < 0.5 || policy.Confidence >= 1) {
+		return fmt.Errorf(
+			"rotator -> set slot pruning policy -> %w (%v confidence, %d min views)",
+			ErrInvalidPruningPolicy,
+			policy.Confidence,
+			policy.MinViews,
+		)
+	}
+
+	if err := r.storage.SetSlotPruningPolicy(policy); err != nil {
+		return fmt.Errorf("rotator -> set slot pruning policy -> %w", err)
+	}
+
+	return nil
+}
+
+// PruneSlots prunes every slot with an enabled policy.
+func (r *Rotator) PruneSlots() ([]storage.Pruning, error) {
+	policies, err := r.storage.PruningPolicies()
+	if err != nil {
+		return nil, fmt.Errorf("rotator -> prune slots -> %w", err)
+	}
+
+	var (
+		result   []storage.Pruning
+		firstErr error
+	)
+	for _, policy := range *policies {
+		prunings, err := r.prune(policy)
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("rotator -> prune slots -> slot %d -> %w", policy.SlotID, err)
+		}
+		result = append(result, prunings...)
+	}
+
+	return result, firstErr
+}
+
+// PruneSlot pauses the banners which are worse than the leader of the slot.
+func (r *Rotator) PruneSlot(slotID int64) ([]storage.Pruning, error) {
+	policy, err := r.storage.SlotPruningPolicy(slotID)
+	if err != nil {
+		return nil, fmt.Errorf("rotator -> prune slot -> %w", err)
+	}
+
+	prunings, err := r.prune(*policy)
+	if err != nil {
+		return nil, fmt.Errorf("rotator -> prune slot -> %w", err)
+	}
+
+	return prunings, nil
+}
+
+// PruningReport lists the paused rotations of the slot.
+func (r *Rotator) PruningReport(slotID int64) ([]storage.Pruning, error) {
+	prunings, err := r.storage.SlotPrunings(slotID)
+	if err != nil {
+		return nil, fmt.Errorf("rotator -> pruning report -> %w", err)
+	}
+
+	return *prunings, nil
+}
+
+// ResumeRotation returns the paused banner to the rotation of the slot.
+func (r *Rotator) ResumeRotation(slotID, bannerID int64) error {
+	if err := r.storage.ResumeRotation(slotID, bannerID); err != nil {
+		return fmt.Errorf("rotator -> resume rotation -> %w", err)
+	}
+
+	return nil
+}
+
+// arm is the views and the sum of the rewards of a banner with its prior.
+type arm struct {
+	bannerID int64
+	views    int64
+	rewards  float64
+	prior    storage.Prior
+}
+
+// posterior is the Beta posterior of the reward of the arm.
+func (a arm) posterior() (alpha, beta float64) {
+	views := float64(a.views + a.prior.Views)
+	rewards := math.Min(a.rewards+a.prior.Rewards, views)
+
+	return 1 + rewards, 1 + views - rewards
+}
+
+func (r *Rotator) prune(policy storage.PruningPolicy) ([]storage.Pruning, error) {
+	if !policy.Enabled {
+		return nil, nil
+	}
+
+	// the split of an experiment is fixed until it ends
+	_, err := r.storage.ActiveExperiment(policy.SlotID)
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, storage.ErrExperimentNotFound) {
+		return nil, err
+	}
+
+	arms, err := r.slotArms(policy)
+	if err != nil {
+		return nil, err
+	}
+	if len(arms) < 2 {
+		return nil, nil
+	}
+
+	leader := arms[0]
+	for _, a := range arms[1:] {
+		if mean(a) > mean(leader) {
+			leader = a
+		}
+	}
+
+	now := time.Now()
+	var prunings []storage.Pruning
+	for _, a := range arms {
+		if a.bannerID == leader.bannerID {
+			continue
+		}
+
+		la, lb := leader.posterior()
+		aa, ab := a.posterior()
+		probability := probabilityGreater(la, lb, aa, ab)
+		if probability < policy.Confidence {
+			continue
+		}
+
+		pruning := storage.Pruning{
+			SlotID:        policy.SlotID,
+			BannerID:      a.bannerID,
+			LeaderID:      leader.bannerID,
+			Views:         a.views,
+			Rewards:       a.rewards,
+			LeaderViews:   leader.views,
+			LeaderRewards: leader.rewards,
+			Probability:   probability,
+			Date:          now.Unix(),
+		}
+		err = r.storage.PauseRotation(pruning)
+		if errors.Is(err, storage.ErrRotationNotPaused) {
+			// paused by another instance or deleted meanwhile
+			continue
+		}
+		if err != nil {
+			return prunings, err
+		}
+		prunings = append(prunings, pruning)
+
+		err = r.p.Publish(rmq.QMessage{
+			Type:      rmq.EventPrune,
+			SlotID:    pruning.SlotID,
+			BannerID:  pruning.BannerID,
+			LeaderID:  pruning.LeaderID,
+			Value:     pruning.Probability,
+			Timestamp: now.UnixMilli(),
+		})
+		if err != nil {
+			return prunings, fmt.Errorf("publish prune event -> %w", err)
+		}
+	}
+
+	return prunings, nil
+}
+
+// slotArms counts the active banners of the slot with enough views.
+func (r *Rotator) slotArms(policy storage.PruningPolicy) ([]arm, error) {
+	banners, err := r.storage.SlotBanners(policy.SlotID)
+	if err != nil {
+		return nil, err
+	}
+	if len(*banners) < 2 {
+		return nil, nil
+	}
+
+	views, err := r.storage.SlotViews(policy.SlotID)
+	if err != nil {
+		return nil, err
+	}
+
+	rewards, err := r.slotRewards(policy.SlotID)
+	if err != nil {
+		return nil, err
+	}
+
+	priors, err := r.slotPriors(policy.SlotID)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[int64]*arm, len(*banners))
+	for _, banner := range *banners {
+		counts[banner.ID] = &arm{bannerID: banner.ID, prior: priors[banner.ID]}
+	}
+	for _, view := range *views {
+		if a, ok := counts[view.BannerID]; ok {
+			a.views++
+		}
+	}
+	for _, reward := range rewards {
+		if a, ok := counts[reward.BannerID]; ok {
+			a.rewards += reward.Value
+		}
+	}
+
+	// the policies saved before the minimum was enforced get it too
+	minViews := policy.MinViews
+	if minViews < MinPruningViews {
+		minViews = MinPruningViews
+	}
+
+	arms := make([]arm, 0, len(*banners))
+	for _, banner := range *banners {
+		if a := counts[banner.ID]; a.views >= minViews {
+			arms = append(arms, *a)
+		}
+	}
+
+	return arms, nil
+}
+
+func mean(a arm) float64 {
+	alpha, beta := a.posterior()
+
+	return alpha / (alpha + beta)
+}
+
+// probabilityGreater is P(X > Y) for X ~ Beta(a1, b1) and Y ~ Beta(a2, b2).
+func probabilityGreater(a1, b1, a2, b2 float64) float64 {
+	lo, hi := 1.0, 0.0
+	for _, d := range [][2]float64{{a1, b1}, {a2, b2}} {
+		a, b := d[0], d[1]
+		m := a / (a + b)
+		sd := math.Sqrt(a * b / ((a + b) * (a + b) * (a + b + 1)))
+		lo = math.Min(lo, m-8*sd)
+		hi = math.Max(hi, m+8*sd)
+	}
+	lo, hi = math.Max(lo, 0), math.Min(hi, 1)
+
+	x := betaWeights(a1, b1, lo, hi)
+	y := betaWeights(a2, b2, lo, hi)
+
+	var p, cdf float64
+	for i := range x {
+		p += x[i] * (cdf + y[i]/2)
+		cdf += y[i]
+	}
+
+	return math.Min(math.Max(p, 0), 1)
+}
+
+// betaWeights are the probabilities of the grid cells on [lo, hi] for Beta(a, b).
+func betaWeights(a, b, lo, hi float64) []float64 {
+	h := (hi - lo) / posteriorGridSize
+	w := make([]float64, posteriorGridSize)
+
+	top := math.Inf(-1)
+	for i := range w {
+		t := lo + (float64(i)+0.5)*h
+		w[i] = (a-1)*math.Log(t) + (b-1)*math.Log1p(-t)
+		top = math.Max(top, w[i])
+	}
+
+	var sum float64
+	for i := range w {
+		w[i] = math.Exp(w[i] - top)
+		sum += w[i]
+	}
+	for i := range w {
+		w[i] /= sum
+	}
+
+	return w
+}
@@ -0,0 +1,28 @@
+package rotator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProbabilityGreater(t *testing.T) {
+	tests := []struct {
+		name           string
+		a1, b1, a2, b2 float64
+		expected       float64
+	}{
+		{name: "equal", a1: 5, b1: 7, a2: 5, b2: 7, expected: 0.5},
+		{name: "uniform", a1: 2, b1: 1, a2: 1, b2: 1, expected: 2.0 / 3},
+		{name: "reversed", a1: 1, b1: 1, a2: 2, b2: 1, expected: 1.0 / 3},
+		{name: "sharp", a1: 5001, b1: 95001, a2: 4001, b2: 96001, expected: 1},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			p := probabilityGreater(tt.a1, tt.b1, tt.a2, tt.b2)
+			require.InDelta(t, tt.expected, p, 1e-3)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package rotator
+
+import (
+	"banners-rotator/internal/storage"
+	"errors"
+	"fmt"
+)
+
+const (
+	BucketHour = "hour"
+	BucketDay  = "day"
+	BucketWeek = "week"
+)
+
+// maxReportPoints limits the length of a report.
+const maxReportPoints = 10000
+
+// weekOffset aligns the week buckets to Monday, 1970-01-05 00:00 UTC.
+const weekOffset = 4 * 24 * 3600
+
+var (
+	ErrUnknownBucket = errors.New("unknown bucket, expected hour, day or week")
+	ErrInvalidRange  = errors.New("invalid time range")
+)
+
+var bucketSizes = map[string]int64{
+	BucketHour: 3600,
+	BucketDay:  24 * 3600,
+	BucketWeek: 7 * 24 * 3600,
+}
+
+// ReportFilter selects the events of the slot with From <= date < To, the
+// dates are unix seconds in UTC. Zero BannerID and GroupID select all banners
+// and groups.
+type ReportFilter struct {
+	SlotID   int64
+	BannerID int64
+	GroupID  int64
+	From     int64
+	To       int64
+	Bucket   string
+}
+
+// ReportPoint holds the events of the bucket starting at Time.
+type ReportPoint struct {
+	Time   int64   `json:"time"`
+	Views  int64   `json:"views"`
+	Clicks int64   `json:"clicks"`
+	CTR    float64 `json:"ctr"`
+}
+
+// CTRReport counts the views, clicks and CTR of every bucket of the range,
+// buckets without events are reported with zero counters.
+func (r *Rotator) CTRReport(filter ReportFilter) ([]ReportPoint, error) {
+	size, ok := bucketSizes[filter.Bucket]
+	if !ok {
+		return nil, fmt.Errorf("rotator -> ctr report -> %w (%s)", ErrUnknownBucket, filter.Bucket)
+	}
+
+	var offset int64
+	if filter.Bucket == BucketWeek {
+		offset = weekOffset
+	}
+
+	if filter.From < 0 || filter.To <= filter.From {
+		return nil, fmt.Errorf("rotator -> ctr report -> %w", ErrInvalidRange)
+	}
+
+	first := floorBucket(filter.From, size, offset)
+	count := (filter.To - first + size - 1) / size
+	if count > maxReportPoints {
+		return nil, fmt.Errorf("rotator -> ctr report -> %w (more than %d buckets)", ErrInvalidRange, maxReportPoints)
+	}
+
+	series, err := r.storage.SlotSeries(storage.SeriesFilter{
+		SlotID:       filter.SlotID,
+		BannerID:     filter.BannerID,
+		GroupID:      filter.GroupID,
+		From:         filter.From,
+		To:           filter.To,
+		BucketSize:   size,
+		BucketOffset: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("rotator -> ctr report -> %w", err)
+	}
+
+	byBucket := make(map[int64]storage.SeriesPoint, len(*series))
+	for _, p := range *series {
+		byBucket[p.Bucket] = p
+	}
+
+	result := make([]ReportPoint, 0, count)
+	for bucket := first; bucket < filter.To; bucket += size {
+		p := byBucket[bucket]
+		point := ReportPoint{Time: bucket, Views: p.Views, Clicks: p.Clicks}
+		if p.Views > 0 {
+			point.CTR = float64(p.Clicks) / float64(p.Views)
+		}
+		result = append(result, point)
+	}
+
+	return result, nil
+}
+
+func floorBucket(date, size, offset int64) int64 {
+	bucket := (date-offset)/size*size + offset
+	if bucket > date {
+		bucket -= size
+	}
+
+	return bucket
+}
@@ -0,0 +1,127 @@
+package rotator
+
+import (
+	"banners-rotator/internal/rmq"
+	"banners-rotator/internal/storage"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+var (
+	ErrUnknownRewardModel     = errors.New("unknown reward model, expected clicks, conversions or revenue")
+	ErrUnknownConversionType  = errors.New("unknown conversion type, expected conversion or purchase")
+	ErrInvalidConversionValue = errors.New("invalid conversion value")
+)
+
+var rewardModels = map[string]struct{}{
+	storage.RewardClicks:      {},
+	storage.RewardConversions: {},
+	storage.RewardRevenue:     {},
+}
+
+// CreateConversionEvent records a conversion after a click on the banner,
+// the value is the revenue of a purchase. The impression ID of the view is
+// passed to the event when it is known.
+func (r *Rotator) CreateConversionEvent(
+	slotID, bannerID, groupID int64,
+	conversionType string,
+	value float64,
+	impressionID string,
+) error {
+	if conversionType != storage.ConversionTypeConversion && conversionType != storage.ConversionTypePurchase {
+		return fmt.Errorf("rotator -> create conversion event -> %w (%s)", ErrUnknownConversionType, conversionType)
+	}
+	if value < 0 || math.IsNaN(value) || math.IsInf(value, 0) {
+		return fmt.Errorf("rotator -> create conversion event -> %w (%v)", ErrInvalidConversionValue, value)
+	}
+
+	now := time.Now()
+	err := r.storage.CreateConversionEvent(slotID, bannerID, groupID, conversionType, value, now.Unix())
+	if err != nil {
+		return fmt.Errorf("rotator -> create conversion event -> %w", err)
+	}
+
+	err = r.p.Publish(rmq.QMessage{
+		ImpressionID:   impressionID,
+		Type:           rmq.EventConversion,
+		SlotID:         slotID,
+		BannerID:       bannerID,
+		GroupID:        groupID,
+		ConversionType: conversionType,
+		Value:          value,
+		Timestamp:      now.UnixMilli(),
+	})
+	if err != nil {
+		return fmt.Errorf("rotator -> publish conversion event -> %w", err)
+	}
+
+	return nil
+}
+
+// SetSlotRewardModel makes the bandit of the slot to optimise the clicks,
+// the conversions or the revenue.
+func (r *Rotator) SetSlotRewardModel(slotID int64, model string) error {
+	if _, ok := rewardModels[model]; !ok {
+		return fmt.Errorf("rotator -> set slot reward model -> %w (%s)", ErrUnknownRewardModel, model)
+	}
+
+	if err := r.storage.SetSlotRewardModel(slotID, model); err != nil {
+		return fmt.Errorf("rotator -> set slot reward model -> %w", err)
+	}
+
+	return nil
+}
+
+// slotRewards lists the rewarded events of the slot by its reward model:
+// every click or every conversion and purchase is worth 1, for the revenue
+// a purchase is worth its value divided by the largest value of the slot.
+func (r *Rotator) slotRewards(slotID int64) ([]Reward, error) {
+	model, err := r.storage.SlotRewardModel(slotID)
+	if err != nil {
+		return nil, fmt.Errorf("slot rewards -> %w", err)
+	}
+
+	if model == storage.RewardClicks {
+		clicks, err := r.storage.SlotClicks(slotID)
+		if err != nil {
+			return nil, fmt.Errorf("slot rewards -> %w", err)
+		}
+
+		rewards := make([]Reward, 0, len(*clicks))
+		for _, c := range *clicks {
+			rewards = append(rewards, Reward{BannerID: c.BannerID, GroupID: c.GroupID, Value: 1})
+		}
+
+		return rewards, nil
+	}
+
+	conversions, err := r.storage.SlotConversions(slotID)
+	if err != nil {
+		return nil, fmt.Errorf("slot rewards -> %w", err)
+	}
+
+	if model == storage.RewardConversions {
+		rewards := make([]Reward, 0, len(*conversions))
+		for _, c := range *conversions {
+			rewards = append(rewards, Reward{BannerID: c.BannerID, GroupID: c.GroupID, Value: 1})
+		}
+
+		return rewards, nil
+	}
+
+	var scale float64
+	for _, c := range *conversions {
+		scale = math.Max(scale, c.Value)
+	}
+
+	rewards := make([]Reward, 0, len(*conversions))
+	for _, c := range *conversions {
+		if c.Value > 0 {
+			rewards = append(rewards, Reward{BannerID: c.BannerID, GroupID: c.GroupID, Value: c.Value / scale})
+		}
+	}
+
+	return rewards, nil
+}
@@ -4,9 +4,11 @@ import (
 	"banners-rotator/internal/rmq"
 	"banners-rotator/internal/storage"
 	"fmt"
+	"math"
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
@@ -14,17 +16,48 @@ type App interface {
 	CreateSlot(description string) (*storage.Slot, error)
 	CreateBanner(description string) (*storage.Banner, error)
 	CreateGroup(description string) (*storage.Group, error)
-	CreateRotation(slotID, bannerID int64) error
+	CreateRotation(slotID, bannerID int64, prior Prior) error
 	DeleteRotation(slotID, bannerID int64) error
 	CreateViewEvent(slotID, bannerID, groupID int64) error
-	CreateClickEvent(slotID, bannerID, groupID int64) error
+	CreateClickEvent(slotID, bannerID, groupID int64, impressionID string) error
 	BannerForSlot(slotID, groupID int64) (*storage.Banner, error)
+	SlotStats(slotID, from, to int64, byGroup bool) ([]BannerStats, error)
+	CTRReport(filter ReportFilter) ([]ReportPoint, error)
+	ExplainBannerForSlot(slotID, groupID int64, visitorID string) (*Explanation, error)
+	CreateConversionEvent(
+		slotID, bannerID, groupID int64,
+		conversionType string,
+		value float64,
+		impressionID string,
+	) error
+	SetSlotRewardModel(slotID int64, model string) error
+	ContextualBannerForSlot(slotID, groupID int64, features Features) (*storage.Banner, error)
+	CreateContextualClickEvent(slotID, bannerID, groupID int64, features Features, impressionID string) error
+	FlushModels() error
+	SetSlotPruningPolicy(policy storage.PruningPolicy) error
+	PruneSlot(slotID int64) ([]storage.Pruning, error)
+	PruneSlots() ([]storage.Pruning, error)
+	PruningReport(slotID int64) ([]storage.Pruning, error)
+	ResumeRotation(slotID, bannerID int64) error
+	BannerForVisitor(slotID, groupID int64, visitorID string, features Features) (*storage.Banner, error)
+	StartExperiment(slotID int64, arms []storage.ExperimentArm) (*storage.Experiment, error)
+	EndExperiment(experimentID int64) (*storage.Experiment, error)
+	ExperimentResults(experimentID int64) (*ExperimentResults, error)
+	CreateGroupWithParent(description string, parentID int64) (*storage.Group, error)
+	SetGroupParent(groupID, parentID int64) error
+	Group(groupID int64) (*storage.Group, error)
+	Groups() ([]storage.Group, error)
 }
 
 type Rotator struct {
-	storage Storage
-	p       *rmq.Producer
-	b       Bandit
+	storage       Storage
+	p             EventPublisher
+	b             Bandit
+	cb            ContextualBandit
+	models        *modelQueue
+	groups        groupCache
+	groupMinViews int64
+	selections    SelectionObserver
 }
 
 type Logger interface {
@@ -39,27 +72,179 @@ type Storage interface {
 	CreateSlot(description string) (*storage.Slot, error)
 	CreateBanner(description string) (*storage.Banner, error)
 	CreateGroup(description string) (*storage.Group, error)
+	// CreateGroupWithParent creates the group under the parent in one
+	// statement, zero parentID creates a top-level group. It returns
+	// storage.ErrGroupParentNotSet when the parent does not exist.
+	CreateGroupWithParent(description string, parentID int64) (*storage.Group, error)
+	// Group returns the group or storage.ErrGroupNotFound.
+	Group(id int64) (*storage.Group, error)
+	Groups() (*[]storage.Group, error)
+	// SetGroupParent replaces the parent of the group, zero parentID makes
+	// the group a top-level one.
+	SetGroupParent(groupID, parentID int64) error
 	CreateRotation(slotID, bannerID int64) error
+	// CreateRotationWithPrior creates the rotation with the prior at once, a
+	// failed write leaves neither of them.
+	CreateRotationWithPrior(slotID, bannerID int64, prior storage.Prior) error
+	// SetRotationPrior replaces the prior of the banner in the slot.
+	SetRotationPrior(slotID, bannerID int64, prior storage.Prior) error
 	DeleteRotation(slotID, bannerID int64) error
 	CreateViewEvent(slotID, bannerID, groupID, date int64) error
 	CreateClickEvent(slotID, bannerID, groupID, date int64) error
-	NotViewedBanners(slotID int64) (*[]storage.Banner, error)
+	CreateConversionEvent(slotID, bannerID, groupID int64, conversionType string, value float64, date int64) error
 	SlotBanners(slotID int64) (*[]storage.Banner, error)
+	// SlotPriors lists the banners of the slot with a prior.
+	SlotPriors(slotID int64) (*[]storage.RotationPrior, error)
+	// SlotViews lists the written views, a storage buffering the events
+	// returns the buffered ones after they are written.
 	SlotViews(slotID int64) (*[]storage.ViewEvent, error)
 	SlotClicks(slotID int64) (*[]storage.ClickEvent, error)
+	SlotConversions(slotID int64) (*[]storage.ConversionEvent, error)
+	// SlotRewardModel returns the reward model of the slot, storage.RewardClicks
+	// unless another one is set.
+	SlotRewardModel(slotID int64) (string, error)
+	SetSlotRewardModel(slotID int64, model string) error
+	// SlotStats counts the views and clicks of the slot with from <= date < to
+	// per banner, or per banner and group when byGroup is set.
+	SlotStats(slotID, from, to int64, byGroup bool) (*[]storage.BannerStats, error)
+	// BannerStats counts the views and clicks of the banner in all slots but
+	// exceptSlotID.
+	BannerStats(bannerID, exceptSlotID int64) (*storage.BannerStats, error)
+	// SlotSeries counts the views and clicks selected by the filter per bucket,
+	// buckets without events are omitted.
+	SlotSeries(filter storage.SeriesFilter) (*[]storage.SeriesPoint, error)
+	// SlotModel returns the contextual bandit model of the slot, a model with
+	// zero version when it has never been saved.
+	SlotModel(slotID int64) (*storage.SlotModel, error)
+	// SaveSlotModel saves the state and increments the version of the model
+	// if its saved version is still model.Version, otherwise it returns
+	// storage.ErrModelConflict.
+	SaveSlotModel(model storage.SlotModel) error
+	// SlotPruningPolicy returns the pruning policy of the slot, a disabled one
+	// when it has never been set.
+	SlotPruningPolicy(slotID int64) (*storage.PruningPolicy, error)
+	SetSlotPruningPolicy(policy storage.PruningPolicy) error
+	// PruningPolicies lists the enabled pruning policies.
+	PruningPolicies() (*[]storage.PruningPolicy, error)
+	// PauseRotation excludes the banner from the rotation of the slot and
+	// records the pruning, it returns storage.ErrRotationNotPaused when the
+	// rotation is missing or already paused.
+	PauseRotation(pruning storage.Pruning) error
+	// ResumeRotation returns the paused banner to the rotation of the slot,
+	// it returns storage.ErrRotationNotResumed when the rotation is missing or
+	// not paused.
+	ResumeRotation(slotID, bannerID int64) error
+	SlotPrunings(slotID int64) (*[]storage.Pruning, error)
+	// CreateExperiment saves the experiment and returns it with the ID, a
+	// slot has one active experiment at most.
+	CreateExperiment(experiment storage.Experiment) (*storage.Experiment, error)
+	// ActiveExperiment returns the active experiment of the slot or
+	// storage.ErrExperimentNotFound.
+	ActiveExperiment(slotID int64) (*storage.Experiment, error)
+	Experiment(id int64) (*storage.Experiment, error)
+	// EndExperiment ends the active experiment, an unknown or ended one is
+	// reported with storage.ErrExperimentNotEnded.
+	EndExperiment(id, date int64) error
+}
+
+type EventPublisher interface {
+	Publish(message rmq.QMessage) error
 }
 
+// Bandit selects the banner of the slot. The priors of the banners are
+// added to their views and rewards, a banner without views and prior is
+// shown before the others.
 type Bandit interface {
 	RandomBanner(banners []storage.Banner) (*storage.Banner, error)
 	TopRatedBanner(
 		banners []storage.Banner,
 		views []storage.ViewEvent,
-		clicks []storage.ClickEvent,
+		rewards []Reward,
+		priors map[int64]storage.Prior,
 	) (*storage.Banner, error)
+	// Score is the score of a banner with the views and the sum of the
+	// rewards among the totalViews views of the slot.
+	Score(views int64, rewards float64, totalViews int64) float64
+	// Scores explains the scores TopRatedBanner selects the banner by.
+	Scores(
+		banners []storage.Banner,
+		views []storage.ViewEvent,
+		rewards []Reward,
+		priors map[int64]storage.Prior,
+	) []BannerScore
+}
+
+// Reward is a rewarded event of the banner in the group, e.g. a click. Value
+// is in [0, 1].
+type Reward struct {
+	BannerID int64
+	GroupID  int64
+	Value    float64
+}
+
+// BannerScore is the score of a banner: the average reward plus the
+// exploration bonus. Rewarded is the number of the rewarded events of the
+// banner, Rewards is the sum of their values. Views and Rewards are the
+// logged ones, the average reward and the bonus include the prior.
+type BannerScore struct {
+	Banner   storage.Banner
+	Views    int64
+	Rewarded int64
+	Rewards  float64
+	Prior    storage.Prior
+	Reward   float64
+	Bonus    float64
+	Score    float64
 }
 
-func NewApp(s Storage, producer *rmq.Producer, bandit Bandit) App {
-	return &Rotator{storage: s, p: producer, b: bandit}
+// The paths a banner is selected by.
+const (
+	// PathTopRated is the selection of a banner with the top average reward.
+	PathTopRated = "top_rated"
+	// PathExploration is the selection of a banner for its exploration bonus.
+	PathExploration = "exploration"
+	// PathContextual is the selection by the contextual bandit.
+	PathContextual = "contextual"
+	// PathExperiment is the selection of the banner of an experiment arm.
+	PathExperiment = "experiment"
+)
+
+// SelectionObserver records the banners selected for the slots by the path
+// of the selection.
+type SelectionObserver interface {
+	ObserveSelection(slotID int64, path string)
+}
+
+type Option func(r *Rotator)
+
+// WithContextualBandit makes the requests with features to be served by the
+// contextual bandit.
+func WithContextualBandit(cb ContextualBandit) Option {
+	return func(r *Rotator) {
+		r.cb = cb
+	}
+}
+
+// WithSelectionObserver reports every selected banner to the observer.
+func WithSelectionObserver(observer SelectionObserver) Option {
+	return func(r *Rotator) {
+		r.selections = observer
+	}
+}
+
+func NewApp(s Storage, publisher EventPublisher, bandit Bandit, opts ...Option) App {
+	r := &Rotator{
+		storage:       s,
+		p:             publisher,
+		b:             bandit,
+		models:        newModelQueue(),
+		groupMinViews: DefaultGroupMinViews,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
 }
 
 func (r *Rotator) CreateSlot(description string) (*storage.Slot, error) {
@@ -74,48 +259,56 @@ func (r *Rotator) CreateGroup(description string) (*storage.Group, error) {
 	return r.storage.CreateGroup(strings.TrimSpace(description))
 }
 
-func (r *Rotator) CreateRotation(slotID, bannerID int64) error {
-	return r.storage.CreateRotation(slotID, bannerID)
-}
-
 func (r *Rotator) DeleteRotation(slotID, bannerID int64) error {
 	return r.storage.DeleteRotation(slotID, bannerID)
 }
 
 func (r *Rotator) CreateViewEvent(slotID, bannerID, groupID int64) error {
-	date := time.Now().Unix()
-	err := r.storage.CreateViewEvent(slotID, bannerID, groupID, date)
+	_, err := r.createViewEvent(slotID, bannerID, groupID)
+
+	return err
+}
+
+// createViewEvent records the view and returns the ID of the impression.
+func (r *Rotator) createViewEvent(slotID, bannerID, groupID int64) (string, error) {
+	now := time.Now()
+	err := r.storage.CreateViewEvent(slotID, bannerID, groupID, now.Unix())
 	if err != nil {
-		return fmt.Errorf("rotator -> create view event -> %w", err)
+		return "", fmt.Errorf("rotator -> create view event -> %w", err)
 	}
 
+	impressionID := uuid.NewString()
 	err = r.p.Publish(rmq.QMessage{
-		Type:     "view",
-		SlotID:   slotID,
-		BannerID: bannerID,
-		GroupID:  groupID,
-		Date:     date,
+		ImpressionID: impressionID,
+		Type:         rmq.EventView,
+		SlotID:       slotID,
+		BannerID:     bannerID,
+		GroupID:      groupID,
+		Timestamp:    now.UnixMilli(),
 	})
 	if err != nil {
-		return fmt.Errorf("rotator -> publish view event -> %w", err)
+		return "", fmt.Errorf("rotator -> publish view event -> %w", err)
 	}
 
-	return nil
+	return impressionID, nil
 }
 
-func (r *Rotator) CreateClickEvent(slotID, bannerID, groupID int64) error {
-	date := time.Now().Unix()
-	err := r.storage.CreateClickEvent(slotID, bannerID, groupID, date)
+// CreateClickEvent records the click, the impression ID of the view is
+// passed to the event when it is known.
+func (r *Rotator) CreateClickEvent(slotID, bannerID, groupID int64, impressionID string) error {
+	now := time.Now()
+	err := r.storage.CreateClickEvent(slotID, bannerID, groupID, now.Unix())
 	if err != nil {
-		return fmt.Errorf("rotator -> create view event -> %w", err)
+		return fmt.Errorf("rotator -> create click event -> %w", err)
 	}
 
 	err = r.p.Publish(rmq.QMessage{
-		Type:     "click",
-		SlotID:   slotID,
-		BannerID: bannerID,
-		GroupID:  groupID,
-		Date:     date,
+		ImpressionID: impressionID,
+		Type:         rmq.EventClick,
+		SlotID:       slotID,
+		BannerID:     bannerID,
+		GroupID:      groupID,
+		Timestamp:    now.UnixMilli(),
 	})
 	if err != nil {
 		return fmt.Errorf("rotator -> publish click event -> %w", err)
@@ -124,44 +317,73 @@ func (r *Rotator) CreateClickEvent(slotID, bannerID, groupID int64) error {
 	return nil
 }
 
+// BannerForSlot selects the banner with the bandit and records the view. The
+// events of a group with a parent are blended with the parent, see blendGroup.
 func (r *Rotator) BannerForSlot(slotID, groupID int64) (*storage.Banner, error) {
-	notViewed, err := r.storage.NotViewedBanners(slotID)
+	banners, err := r.storage.SlotBanners(slotID)
 	if err != nil {
 		return nil, fmt.Errorf("rotator -> banner for slot -> %w", err)
 	}
-	if banner, err := r.b.RandomBanner(*notViewed); err == nil && banner.ID > 0 {
-		err = r.CreateViewEvent(slotID, banner.ID, groupID)
-		if err != nil {
-			return nil, fmt.Errorf("rotator -> banner for slot -> %w", err)
-		}
 
-		return banner, nil
+	views, err := r.storage.SlotViews(slotID)
+	if err != nil {
+		return nil, fmt.Errorf("rotator -> banner for slot -> %w", err)
 	}
 
-	banners, err := r.storage.SlotBanners(slotID)
+	rewards, err := r.slotRewards(slotID)
 	if err != nil {
 		return nil, fmt.Errorf("rotator -> banner for slot -> %w", err)
 	}
 
-	views, err := r.storage.SlotViews(slotID)
+	priors, err := r.slotPriors(slotID)
 	if err != nil {
 		return nil, fmt.Errorf("rotator -> banner for slot -> %w", err)
 	}
 
-	clicks, err := r.storage.SlotClicks(slotID)
+	groupViews, rewards, priors, err := r.blendGroup(groupID, *views, rewards, priors)
 	if err != nil {
 		return nil, fmt.Errorf("rotator -> banner for slot -> %w", err)
 	}
 
-	banner, err := r.b.TopRatedBanner(*banners, *views, *clicks)
+	banner, err := r.b.TopRatedBanner(*banners, groupViews, rewards, priors)
 	if err != nil {
 		return nil, fmt.Errorf("rotator -> banner for slot -> %w", err)
 	}
 
-	err = r.CreateViewEvent(slotID, banner.ID, groupID)
+	banner.ImpressionID, err = r.createViewEvent(slotID, banner.ID, groupID)
 	if err != nil {
 		return nil, fmt.Errorf("rotator -> banner for slot -> %w", err)
 	}
 
+	if r.selections != nil {
+		path := selectionPath(banner.ID, r.b.Scores(*banners, groupViews, rewards, priors))
+		r.selections.ObserveSelection(slotID, path)
+	}
+
 	return banner, err
 }
+
+// selectionPath is PathTopRated when the banner has the top average reward
+// among the scored banners and PathExploration when it has been selected for
+// the exploration bonus, e.g. a banner without views.
+func selectionPath(bannerID int64, scores []BannerScore) string {
+	var (
+		top      = math.Inf(-1)
+		selected = math.Inf(-1)
+	)
+	for _, score := range scores {
+		if score.Views+score.Prior.Views == 0 {
+			continue
+		}
+		top = math.Max(top, score.Reward)
+		if score.Banner.ID == bannerID {
+			selected = score.Reward
+		}
+	}
+
+	if selected >= top && !math.IsInf(selected, -1) {
+		return PathTopRated
+	}
+
+	return PathExploration
+}
@@ -0,0 +1,853 @@
+package rotator_test
+
+import (
+	"banners-rotator/internal/bandit"
+	"banners-rotator/internal/bandit/linucb"
+	"banners-rotator/internal/publisher"
+	"banners-rotator/internal/rmq"
+	"banners-rotator/internal/rotator"
+	"banners-rotator/internal/storage"
+	memorystorage "banners-rotator/internal/storage/memory"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotator_BannerForSlot(t *testing.T) {
+	s := memorystorage.NewStorage()
+	app := rotator.NewApp(s, publisher.NewNopPublisher(), bandit.NewBandit())
+
+	slot, err := app.CreateSlot(" slot ")
+	require.NoError(t, err)
+	require.Equal(t, "slot", slot.Description)
+	group, err := app.CreateGroup("group")
+	require.NoError(t, err)
+
+	t.Run("slot without banners", func(t *testing.T) {
+		_, err := app.BannerForSlot(slot.ID, group.ID)
+		require.Error(t, err)
+	})
+
+	t.Run("show every banner first", func(t *testing.T) {
+		shown := make(map[int64]struct{})
+		for i := 0; i < 3; i++ {
+			banner, err := app.CreateBanner("banner")
+			require.NoError(t, err)
+			require.NoError(t, app.CreateRotation(slot.ID, banner.ID, rotator.Prior{}))
+		}
+
+		for i := 0; i < 3; i++ {
+			banner, err := app.BannerForSlot(slot.ID, group.ID)
+			require.NoError(t, err)
+			shown[banner.ID] = struct{}{}
+		}
+		require.Len(t, shown, 3)
+	})
+
+	t.Run("prefer clicked banner", func(t *testing.T) {
+		for i := 0; i < 50; i++ {
+			require.NoError(t, app.CreateClickEvent(slot.ID, 2, group.ID, ""))
+		}
+
+		counts := make(map[int64]int)
+		for i := 0; i < 100; i++ {
+			banner, err := app.BannerForSlot(slot.ID, group.ID)
+			require.NoError(t, err)
+			counts[banner.ID]++
+		}
+		require.Greater(t, counts[2], counts[1])
+		require.Greater(t, counts[2], counts[3])
+	})
+}
+
+func TestRotator_SlotStats(t *testing.T) {
+	s := memorystorage.NewStorage()
+	b := bandit.NewBandit()
+	app := rotator.NewApp(s, publisher.NewNopPublisher(), b)
+
+	slot, _ := s.CreateSlot("slot")
+	first, _ := s.CreateBanner("first")
+	second, _ := s.CreateBanner("second")
+	idle, _ := s.CreateBanner("idle")
+	group, _ := s.CreateGroup("group")
+	group2, _ := s.CreateGroup("group 2")
+	for _, banner := range []int64{first.ID, second.ID, idle.ID} {
+		require.NoError(t, s.CreateRotation(slot.ID, banner))
+	}
+
+	for i := int64(0); i < 4; i++ {
+		require.NoError(t, s.CreateViewEvent(slot.ID, first.ID, group.ID, 10+i))
+	}
+	require.NoError(t, s.CreateViewEvent(slot.ID, first.ID, group2.ID, 20))
+	require.NoError(t, s.CreateClickEvent(slot.ID, first.ID, group.ID, 11))
+	require.NoError(t, s.CreateViewEvent(slot.ID, second.ID, group.ID, 100))
+
+	t.Run("per banner", func(t *testing.T) {
+		stats, err := app.SlotStats(slot.ID, 0, 0, false)
+		require.NoError(t, err)
+		require.Len(t, stats, 3)
+
+		require.Equal(t, first.ID, stats[0].BannerID)
+		require.Equal(t, int64(5), stats[0].Views)
+		require.Equal(t, int64(1), stats[0].Clicks)
+		require.InDelta(t, 0.2, stats[0].CTR, 1e-9)
+		require.InDelta(t, 5.0/6, stats[0].Share, 1e-9)
+		require.Equal(t, b.Score(5, 1, 6), stats[0].Score)
+
+		require.Equal(t, idle.ID, stats[2].BannerID)
+		require.Zero(t, stats[2].Views)
+		require.Zero(t, stats[2].CTR)
+		require.True(t, math.IsInf(stats[2].Score, 1))
+	})
+
+	t.Run("time range", func(t *testing.T) {
+		stats, err := app.SlotStats(slot.ID, 50, 200, false)
+		require.NoError(t, err)
+		require.Len(t, stats, 3)
+		require.Zero(t, stats[0].Views)
+		require.Equal(t, int64(1), stats[1].Views)
+		require.Equal(t, 1.0, stats[1].Share)
+		// the score does not depend on the range
+		require.Equal(t, b.Score(5, 1, 6), stats[0].Score)
+	})
+
+	t.Run("per group", func(t *testing.T) {
+		stats, err := app.SlotStats(slot.ID, 0, 50, true)
+		require.NoError(t, err)
+		require.Len(t, stats, 2)
+		require.Equal(t, group.ID, stats[0].GroupID)
+		require.Equal(t, int64(4), stats[0].Views)
+		require.InDelta(t, 0.25, stats[0].CTR, 1e-9)
+		require.Equal(t, 1.0, stats[0].Share)
+		require.Equal(t, group2.ID, stats[1].GroupID)
+		require.Equal(t, 1.0, stats[1].Share)
+	})
+}
+
+func TestRotator_CTRReport(t *testing.T) {
+	s := memorystorage.NewStorage()
+	app := rotator.NewApp(s, publisher.NewNopPublisher(), bandit.NewBandit())
+
+	slot, _ := s.CreateSlot("slot")
+	banner, _ := s.CreateBanner("banner")
+	group, _ := s.CreateGroup("group")
+
+	// 2022-01-01 00:00 UTC is Saturday.
+	const day = int64(1640995200)
+	require.NoError(t, s.CreateViewEvent(slot.ID, banner.ID, group.ID, day+10))
+	require.NoError(t, s.CreateViewEvent(slot.ID, banner.ID, group.ID, day+3600))
+	require.NoError(t, s.CreateClickEvent(slot.ID, banner.ID, group.ID, day+3700))
+	require.NoError(t, s.CreateViewEvent(slot.ID, banner.ID, group.ID, day+2*86400))
+
+	t.Run("hourly buckets", func(t *testing.T) {
+		points, err := app.CTRReport(rotator.ReportFilter{
+			SlotID: slot.ID, From: day, To: day + 3*3600, Bucket: rotator.BucketHour,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []rotator.ReportPoint{
+			{Time: day, Views: 1},
+			{Time: day + 3600, Views: 1, Clicks: 1, CTR: 1},
+			{Time: day + 7200},
+		}, points)
+	})
+
+	t.Run("daily buckets", func(t *testing.T) {
+		points, err := app.CTRReport(rotator.ReportFilter{
+			SlotID: slot.ID, BannerID: banner.ID, From: day + 1800, To: day + 3*86400, Bucket: rotator.BucketDay,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []rotator.ReportPoint{
+			{Time: day, Views: 1, Clicks: 1, CTR: 1},
+			{Time: day + 86400},
+			{Time: day + 2*86400, Views: 1},
+		}, points)
+	})
+
+	t.Run("weekly buckets start on monday", func(t *testing.T) {
+		points, err := app.CTRReport(rotator.ReportFilter{
+			SlotID: slot.ID, From: day, To: day + 7*86400, Bucket: rotator.BucketWeek,
+		})
+		require.NoError(t, err)
+		require.Len(t, points, 2)
+		require.Equal(t, day-5*86400, points[0].Time)
+		require.Equal(t, int64(2), points[0].Views)
+		require.Equal(t, int64(1), points[1].Views)
+	})
+
+	t.Run("invalid report", func(t *testing.T) {
+		_, err := app.CTRReport(rotator.ReportFilter{SlotID: slot.ID, From: day, To: day + 1, Bucket: "month"})
+		require.ErrorIs(t, err, rotator.ErrUnknownBucket)
+
+		_, err = app.CTRReport(rotator.ReportFilter{SlotID: slot.ID, From: day, To: day, Bucket: rotator.BucketDay})
+		require.ErrorIs(t, err, rotator.ErrInvalidRange)
+
+		_, err = app.CTRReport(rotator.ReportFilter{SlotID: slot.ID, From: 0, To: day, Bucket: rotator.BucketHour})
+		require.ErrorIs(t, err, rotator.ErrInvalidRange)
+	})
+}
+
+func TestRotator_ExplainBannerForSlot(t *testing.T) {
+	s := memorystorage.NewStorage()
+	app := rotator.NewApp(s, publisher.NewNopPublisher(), bandit.NewBandit())
+
+	slot, _ := s.CreateSlot("slot")
+	first, _ := s.CreateBanner("first")
+	second, _ := s.CreateBanner("second")
+	group, _ := s.CreateGroup("group")
+	require.NoError(t, s.CreateRotation(slot.ID, first.ID))
+	require.NoError(t, s.CreateRotation(slot.ID, second.ID))
+	require.NoError(t, s.CreateViewEvent(slot.ID, first.ID, group.ID, 1))
+
+	t.Run("not viewed banner first", func(t *testing.T) {
+		explanation, err := app.ExplainBannerForSlot(slot.ID, 0, "")
+		require.NoError(t, err)
+		require.Equal(t, rotator.RuleNotViewed, explanation.Rule)
+		require.Equal(t, second.ID, explanation.Chosen.ID)
+		require.Len(t, explanation.Candidates, 2)
+		require.False(t, explanation.Candidates[0].NotViewed)
+		require.Equal(t, int64(1), explanation.Candidates[0].Views)
+		require.True(t, explanation.Candidates[1].NotViewed)
+
+		views, err := s.SlotViews(slot.ID)
+		require.NoError(t, err)
+		require.Len(t, *views, 1, "explain must not record a view")
+	})
+
+	t.Run("top rated banner", func(t *testing.T) {
+		require.NoError(t, s.CreateViewEvent(slot.ID, second.ID, group.ID, 2))
+		require.NoError(t, s.CreateViewEvent(slot.ID, second.ID, group.ID, 3))
+		require.NoError(t, s.CreateClickEvent(slot.ID, second.ID, group.ID, 3))
+
+		explanation, err := app.ExplainBannerForSlot(slot.ID, 0, "")
+		require.NoError(t, err)
+		require.Equal(t, rotator.RuleTopRated, explanation.Rule)
+		require.Equal(t, second.ID, explanation.Chosen.ID)
+		require.Equal(t, 0.5, explanation.Candidates[1].Reward)
+		require.Greater(t, explanation.Candidates[1].Score, explanation.Candidates[0].Score)
+	})
+}
+
+type failingViewBandit struct {
+	rotator.ContextualBandit
+}
+
+func (b failingViewBandit) View([]byte, int64, rotator.Features) ([]byte, error) {
+	return nil, fmt.Errorf("test error")
+}
+
+type failingModelStorage struct {
+	rotator.Storage
+	fail bool
+}
+
+func (s *failingModelStorage) SaveSlotModel(model storage.SlotModel) error {
+	if s.fail {
+		return fmt.Errorf("test error")
+	}
+
+	return s.Storage.SaveSlotModel(model)
+}
+
+func TestRotator_ContextualBannerForSlot(t *testing.T) {
+	s := memorystorage.NewStorage()
+	app := rotator.NewApp(s, publisher.NewNopPublisher(), bandit.NewBandit(),
+		rotator.WithContextualBandit(linucb.NewBandit(linucb.WithSeed(1))))
+
+	slot, err := app.CreateSlot("slot")
+	require.NoError(t, err)
+	group, err := app.CreateGroup("group")
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		banner, err := app.CreateBanner("banner")
+		require.NoError(t, err)
+		require.NoError(t, app.CreateRotation(slot.ID, banner.ID, rotator.Prior{}))
+	}
+	features := rotator.Features{"device": "mobile"}
+
+	t.Run("view and click update the model", func(t *testing.T) {
+		banner, err := app.ContextualBannerForSlot(slot.ID, group.ID, features)
+		require.NoError(t, err)
+		require.NoError(t, app.CreateContextualClickEvent(slot.ID, banner.ID, group.ID, features, banner.ImpressionID))
+
+		model, err := s.SlotModel(slot.ID)
+		require.NoError(t, err)
+		require.Zero(t, model.Version)
+
+		require.NoError(t, app.FlushModels())
+		model, err = s.SlotModel(slot.ID)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), model.Version)
+		require.NotEmpty(t, model.State)
+
+		views, err := s.SlotViews(slot.ID)
+		require.NoError(t, err)
+		require.Len(t, *views, 1)
+	})
+
+	t.Run("failed model update keeps the view", func(t *testing.T) {
+		app := rotator.NewApp(s, publisher.NewNopPublisher(), bandit.NewBandit(),
+			rotator.WithContextualBandit(failingViewBandit{linucb.NewBandit(linucb.WithSeed(1))}))
+
+		_, err := app.ContextualBannerForSlot(slot.ID, group.ID, features)
+		require.NoError(t, err)
+		err = app.FlushModels()
+		require.ErrorIs(t, err, rotator.ErrModelUpdatesDropped)
+
+		views, err := s.SlotViews(slot.ID)
+		require.NoError(t, err)
+		require.Len(t, *views, 2)
+	})
+
+	t.Run("without features", func(t *testing.T) {
+		banner, err := app.ContextualBannerForSlot(slot.ID, group.ID, nil)
+		require.NoError(t, err)
+		require.NoError(t, app.CreateContextualClickEvent(slot.ID, banner.ID, group.ID, nil, banner.ImpressionID))
+		require.NoError(t, app.FlushModels())
+
+		model, err := s.SlotModel(slot.ID)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), model.Version)
+
+		views, err := s.SlotViews(slot.ID)
+		require.NoError(t, err)
+		require.Len(t, *views, 3)
+	})
+
+	t.Run("failed save keeps the updates", func(t *testing.T) {
+		fs := &failingModelStorage{Storage: s, fail: true}
+		app := rotator.NewApp(fs, publisher.NewNopPublisher(), bandit.NewBandit(),
+			rotator.WithContextualBandit(linucb.NewBandit(linucb.WithSeed(1))))
+
+		_, err := app.ContextualBannerForSlot(slot.ID, group.ID, features)
+		require.NoError(t, err)
+		require.Error(t, app.FlushModels())
+
+		fs.fail = false
+		require.NoError(t, app.FlushModels())
+		model, err := s.SlotModel(slot.ID)
+		require.NoError(t, err)
+		require.Equal(t, int64(2), model.Version)
+	})
+}
+
+func TestRotator_RewardModel(t *testing.T) {
+	s := memorystorage.NewStorage()
+	app := rotator.NewApp(s, publisher.NewNopPublisher(), bandit.NewBandit())
+
+	slot, _ := s.CreateSlot("slot")
+	first, _ := s.CreateBanner("first")
+	second, _ := s.CreateBanner("second")
+	group, _ := s.CreateGroup("group")
+	require.NoError(t, s.CreateRotation(slot.ID, first.ID))
+	require.NoError(t, s.CreateRotation(slot.ID, second.ID))
+	require.NoError(t, s.CreateViewEvent(slot.ID, first.ID, group.ID, 1))
+	require.NoError(t, s.CreateViewEvent(slot.ID, second.ID, group.ID, 1))
+
+	require.NoError(t, app.CreateConversionEvent(slot.ID, first.ID, group.ID, storage.ConversionTypePurchase, 100, ""))
+	require.NoError(t, app.CreateConversionEvent(slot.ID, second.ID, group.ID, storage.ConversionTypeConversion, 0, ""))
+	require.NoError(t, app.CreateConversionEvent(slot.ID, second.ID, group.ID, storage.ConversionTypePurchase, 20, ""))
+
+	t.Run("conversions", func(t *testing.T) {
+		require.NoError(t, app.SetSlotRewardModel(slot.ID, storage.RewardConversions))
+
+		explanation, err := app.ExplainBannerForSlot(slot.ID, 0, "")
+		require.NoError(t, err)
+		require.Equal(t, second.ID, explanation.Chosen.ID)
+		require.Equal(t, int64(2), explanation.Candidates[1].Rewarded)
+	})
+
+	t.Run("revenue", func(t *testing.T) {
+		require.NoError(t, app.SetSlotRewardModel(slot.ID, storage.RewardRevenue))
+
+		explanation, err := app.ExplainBannerForSlot(slot.ID, 0, "")
+		require.NoError(t, err)
+		require.Equal(t, first.ID, explanation.Chosen.ID)
+		require.Equal(t, 1.0, explanation.Candidates[0].Rewards)
+		require.InDelta(t, 0.2, explanation.Candidates[1].Rewards, 1e-9)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		err := app.SetSlotRewardModel(slot.ID, "likes")
+		require.ErrorIs(t, err, rotator.ErrUnknownRewardModel)
+
+		err = app.CreateConversionEvent(slot.ID, first.ID, group.ID, "signup", 0, "")
+		require.ErrorIs(t, err, rotator.ErrUnknownConversionType)
+
+		err = app.CreateConversionEvent(slot.ID, first.ID, group.ID, storage.ConversionTypePurchase, -1, "")
+		require.ErrorIs(t, err, rotator.ErrInvalidConversionValue)
+	})
+}
+
+func TestRotator_Prior(t *testing.T) {
+	s := memorystorage.NewStorage()
+	app := rotator.NewApp(s, publisher.NewNopPublisher(), bandit.NewBandit())
+
+	slot, _ := s.CreateSlot("slot")
+	group, _ := s.CreateGroup("group")
+	for i := 0; i < 2; i++ {
+		banner, _ := s.CreateBanner("banner")
+		require.NoError(t, app.CreateRotation(slot.ID, banner.ID, rotator.Prior{}))
+		for j := 0; j < 10; j++ {
+			require.NoError(t, s.CreateViewEvent(slot.ID, banner.ID, group.ID, 1))
+		}
+		require.NoError(t, s.CreateClickEvent(slot.ID, banner.ID, group.ID, 1))
+	}
+
+	t.Run("banner with prior is not shown first", func(t *testing.T) {
+		banner, _ := s.CreateBanner("weak")
+		require.NoError(t, app.CreateRotation(slot.ID, banner.ID, rotator.Prior{Views: 100, Rewards: 1}))
+
+		explanation, err := app.ExplainBannerForSlot(slot.ID, 0, "")
+		require.NoError(t, err)
+		require.Equal(t, rotator.RuleTopRated, explanation.Rule)
+		require.NotEqual(t, banner.ID, explanation.Chosen.ID)
+
+		candidate := explanation.Candidates[2]
+		require.False(t, candidate.NotViewed)
+		require.Equal(t, storage.Prior{Views: 100, Rewards: 1}, candidate.Prior)
+		require.Equal(t, 0.01, candidate.Reward)
+	})
+
+	t.Run("inherit prior from other slots", func(t *testing.T) {
+		other, _ := s.CreateSlot("other")
+		banner, _ := s.CreateBanner("strong")
+		require.NoError(t, app.CreateRotation(other.ID, banner.ID, rotator.Prior{}))
+		for j := 0; j < 10; j++ {
+			require.NoError(t, s.CreateViewEvent(other.ID, banner.ID, group.ID, 1))
+		}
+		for j := 0; j < 9; j++ {
+			require.NoError(t, s.CreateClickEvent(other.ID, banner.ID, group.ID, 1))
+		}
+
+		require.NoError(t, app.CreateRotation(slot.ID, banner.ID, rotator.Prior{Inherit: true}))
+
+		explanation, err := app.ExplainBannerForSlot(slot.ID, 0, "")
+		require.NoError(t, err)
+		require.Equal(t, banner.ID, explanation.Chosen.ID)
+		require.Equal(t, storage.Prior{Views: 10, Rewards: 9}, explanation.Candidates[3].Prior)
+	})
+
+	t.Run("invalid prior", func(t *testing.T) {
+		banner, _ := s.CreateBanner("invalid")
+
+		err := app.CreateRotation(slot.ID, banner.ID, rotator.Prior{Views: 1, Rewards: 2})
+		require.ErrorIs(t, err, rotator.ErrInvalidPrior)
+
+		err = app.CreateRotation(slot.ID, banner.ID, rotator.Prior{Views: -1})
+		require.ErrorIs(t, err, rotator.ErrInvalidPrior)
+
+		banners, err := s.SlotBanners(slot.ID)
+		require.NoError(t, err)
+		require.Len(t, *banners, 4)
+	})
+}
+
+type recordingPublisher struct {
+	messages []rmq.QMessage
+}
+
+func (p *recordingPublisher) Publish(message rmq.QMessage) error {
+	p.messages = append(p.messages, message)
+
+	return nil
+}
+
+func TestRotator_ImpressionID(t *testing.T) {
+	s := memorystorage.NewStorage()
+	p := &recordingPublisher{}
+	app := rotator.NewApp(s, p, bandit.NewBandit())
+
+	slot, _ := s.CreateSlot("slot")
+	group, _ := s.CreateGroup("group")
+	banner, _ := s.CreateBanner("banner")
+	require.NoError(t, app.CreateRotation(slot.ID, banner.ID, rotator.Prior{}))
+
+	shown, err := app.BannerForSlot(slot.ID, group.ID)
+	require.NoError(t, err)
+	require.NotEmpty(t, shown.ImpressionID)
+
+	require.NoError(t, app.CreateClickEvent(slot.ID, shown.ID, group.ID, shown.ImpressionID))
+	require.NoError(t, app.CreateConversionEvent(
+		slot.ID, shown.ID, group.ID, storage.ConversionTypeConversion, 0, shown.ImpressionID,
+	))
+
+	require.Len(t, p.messages, 3)
+	for i, eventType := range []string{rmq.EventView, rmq.EventClick, rmq.EventConversion} {
+		require.Equal(t, eventType, p.messages[i].Type)
+		require.Equal(t, shown.ImpressionID, p.messages[i].ImpressionID)
+	}
+}
+
+func TestRotator_Pruning(t *testing.T) {
+	s := memorystorage.NewStorage()
+	p := &recordingPublisher{}
+	app := rotator.NewApp(s, p, bandit.NewBandit())
+
+	slot, _ := s.CreateSlot("slot")
+	group, _ := s.CreateGroup("group")
+	// clicks of the leader, the close banner, the loser and the banner with
+	// too few views
+	clicks := []int{75, 60, 5, 0}
+	views := []int{150, 150, 150, 50}
+	banners := make([]int64, 0, len(clicks))
+	for i := range clicks {
+		banner, _ := s.CreateBanner("banner")
+		banners = append(banners, banner.ID)
+		require.NoError(t, app.CreateRotation(slot.ID, banner.ID, rotator.Prior{}))
+		for j := 0; j < views[i]; j++ {
+			require.NoError(t, s.CreateViewEvent(slot.ID, banner.ID, group.ID, 1))
+		}
+		for j := 0; j < clicks[i]; j++ {
+			require.NoError(t, s.CreateClickEvent(slot.ID, banner.ID, group.ID, 1))
+		}
+	}
+
+	t.Run("disabled policy", func(t *testing.T) {
+		prunings, err := app.PruneSlot(slot.ID)
+		require.NoError(t, err)
+		require.Empty(t, prunings)
+	})
+
+	t.Run("invalid policy", func(t *testing.T) {
+		for _, policy := range []storage.PruningPolicy{
+			{SlotID: slot.ID, Enabled: true, Confidence: 0.4},
+			{SlotID: slot.ID, Enabled: true, Confidence: 1},
+			{SlotID: slot.ID, Enabled: true, Confidence: 0.9, MinViews: -1},
+			{SlotID: slot.ID, Enabled: true, Confidence: 0.9, MinViews: 1},
+			{SlotID: slot.ID, Enabled: true, Confidence: 0.9, MinViews: rotator.MinPruningViews - 1},
+		} {
+			require.ErrorIs(t, app.SetSlotPruningPolicy(policy), rotator.ErrInvalidPruningPolicy)
+		}
+	})
+
+	t.Run("prune loser", func(t *testing.T) {
+		require.NoError(t, app.SetSlotPruningPolicy(storage.PruningPolicy{
+			SlotID: slot.ID, Enabled: true, Confidence: 0.99,
+		}))
+		policy, err := s.SlotPruningPolicy(slot.ID)
+		require.NoError(t, err)
+		require.Equal(t, int64(rotator.MinPruningViews), policy.MinViews)
+
+		prunings, err := app.PruneSlots()
+		require.NoError(t, err)
+		require.Len(t, prunings, 1)
+		require.Equal(t, banners[2], prunings[0].BannerID)
+		require.Equal(t, banners[0], prunings[0].LeaderID)
+		require.Equal(t, int64(150), prunings[0].Views)
+		require.Equal(t, 5.0, prunings[0].Rewards)
+		require.Equal(t, int64(150), prunings[0].LeaderViews)
+		require.Equal(t, 75.0, prunings[0].LeaderRewards)
+		require.Greater(t, prunings[0].Probability, 0.99)
+
+		require.Len(t, p.messages, 1)
+		require.Equal(t, rmq.EventPrune, p.messages[0].Type)
+		require.Equal(t, banners[2], p.messages[0].BannerID)
+		require.Equal(t, banners[0], p.messages[0].LeaderID)
+
+		active, err := s.SlotBanners(slot.ID)
+		require.NoError(t, err)
+		require.Len(t, *active, 3)
+		for _, banner := range *active {
+			require.NotEqual(t, banners[2], banner.ID)
+		}
+	})
+
+	t.Run("prune once", func(t *testing.T) {
+		prunings, err := app.PruneSlot(slot.ID)
+		require.NoError(t, err)
+		require.Empty(t, prunings)
+
+		report, err := app.PruningReport(slot.ID)
+		require.NoError(t, err)
+		require.Len(t, report, 1)
+		require.Equal(t, banners[2], report[0].BannerID)
+	})
+
+	t.Run("resume rotation", func(t *testing.T) {
+		require.NoError(t, app.ResumeRotation(slot.ID, banners[2]))
+		active, err := s.SlotBanners(slot.ID)
+		require.NoError(t, err)
+		require.Len(t, *active, 4)
+
+		err = app.ResumeRotation(slot.ID, banners[2])
+		require.ErrorIs(t, err, storage.ErrRotationNotResumed)
+	})
+
+	t.Run("priors count in posterior", func(t *testing.T) {
+		slot, _ := s.CreateSlot("slot")
+		first, _ := s.CreateBanner("first")
+		second, _ := s.CreateBanner("second")
+		require.NoError(t, app.CreateRotation(slot.ID, first.ID, rotator.Prior{Views: 1000, Rewards: 500}))
+		require.NoError(t, app.CreateRotation(slot.ID, second.ID, rotator.Prior{}))
+		for _, banner := range []int64{first.ID, second.ID} {
+			for j := 0; j < 100; j++ {
+				require.NoError(t, s.CreateViewEvent(slot.ID, banner, group.ID, 1))
+			}
+			for j := 0; j < 10; j++ {
+				require.NoError(t, s.CreateClickEvent(slot.ID, banner, group.ID, 1))
+			}
+		}
+		require.NoError(t, app.SetSlotPruningPolicy(storage.PruningPolicy{
+			SlotID: slot.ID, Enabled: true, Confidence: 0.99,
+		}))
+
+		prunings, err := app.PruneSlot(slot.ID)
+		require.NoError(t, err)
+		require.Len(t, prunings, 1)
+		require.Equal(t, second.ID, prunings[0].BannerID)
+		require.Equal(t, first.ID, prunings[0].LeaderID)
+		require.Equal(t, int64(100), prunings[0].LeaderViews)
+	})
+}
+
+func TestRotator_Experiment(t *testing.T) {
+	s := memorystorage.NewStorage()
+	app := rotator.NewApp(s, publisher.NewNopPublisher(), bandit.NewBandit())
+
+	slot, _ := s.CreateSlot("slot")
+	group, _ := s.CreateGroup("group")
+	first, _ := s.CreateBanner("first")
+	second, _ := s.CreateBanner("second")
+	other, _ := s.CreateBanner("other")
+	for _, banner := range []*storage.Banner{first, second, other} {
+		require.NoError(t, app.CreateRotation(slot.ID, banner.ID, rotator.Prior{}))
+	}
+	arms := []storage.ExperimentArm{{BannerID: first.ID, Share: 50}, {BannerID: second.ID, Share: 50}}
+
+	t.Run("invalid experiment", func(t *testing.T) {
+		unrotated, _ := s.CreateBanner("unrotated")
+		for _, arms := range [][]storage.ExperimentArm{
+			{{BannerID: first.ID, Share: 100}},
+			{{BannerID: first.ID, Share: 50}, {BannerID: second.ID, Share: 40}},
+			{{BannerID: first.ID, Share: 50}, {BannerID: first.ID, Share: 50}},
+			{{BannerID: first.ID, Share: 110}, {BannerID: second.ID, Share: -10}},
+			{{BannerID: first.ID, Share: 50}, {BannerID: unrotated.ID, Share: 50}},
+		} {
+			_, err := app.StartExperiment(slot.ID, arms)
+			require.ErrorIs(t, err, rotator.ErrInvalidExperiment)
+		}
+	})
+
+	experiment, err := app.StartExperiment(slot.ID, arms)
+	require.NoError(t, err)
+
+	t.Run("one experiment per slot", func(t *testing.T) {
+		_, err := app.StartExperiment(slot.ID, arms)
+		require.ErrorIs(t, err, rotator.ErrExperimentRunning)
+	})
+
+	t.Run("sticky split", func(t *testing.T) {
+		shown := make(map[int64]int)
+		for i := 0; i < 200; i++ {
+			visitor := fmt.Sprintf("visitor-%d", i)
+			banner, err := app.BannerForVisitor(slot.ID, group.ID, visitor, nil)
+			require.NoError(t, err)
+			again, err := app.BannerForVisitor(slot.ID, group.ID, visitor, nil)
+			require.NoError(t, err)
+			require.Equal(t, banner.ID, again.ID)
+			shown[banner.ID]++
+		}
+
+		require.Zero(t, shown[other.ID])
+		require.InDelta(t, 100, shown[first.ID], 30)
+		require.Equal(t, 200, shown[first.ID]+shown[second.ID])
+	})
+
+	t.Run("results", func(t *testing.T) {
+		for i := 0; i < 60; i++ {
+			require.NoError(t, app.CreateClickEvent(slot.ID, first.ID, group.ID, ""))
+		}
+
+		results, err := app.ExperimentResults(experiment.ID)
+		require.NoError(t, err)
+		require.Equal(t, rotator.ExperimentConfidence, results.Confidence)
+		require.Len(t, results.Arms, 2)
+		require.Equal(t, first.ID, results.Arms[0].BannerID)
+		require.Equal(t, int64(60), results.Arms[0].Clicks)
+		require.Equal(t, int64(400), results.Arms[0].Views+results.Arms[1].Views)
+		require.Less(t, results.Arms[0].Low, results.Arms[0].CTR)
+		require.Greater(t, results.Arms[0].High, results.Arms[0].CTR)
+
+		require.Len(t, results.Comparisons, 1)
+		comparison := results.Comparisons[0]
+		require.Equal(t, second.ID, comparison.BannerID)
+		require.Equal(t, first.ID, comparison.OtherID)
+		require.Less(t, comparison.Difference, 0.0)
+		require.Less(t, comparison.High, 0.0)
+		require.Less(t, comparison.PValue, 0.001)
+	})
+
+	t.Run("explain experiment arm", func(t *testing.T) {
+		banner, err := app.BannerForVisitor(slot.ID, group.ID, "visitor-1", nil)
+		require.NoError(t, err)
+
+		explanation, err := app.ExplainBannerForSlot(slot.ID, 0, "visitor-1")
+		require.NoError(t, err)
+		require.Equal(t, rotator.RuleExperiment, explanation.Rule)
+		require.Equal(t, experiment.ID, explanation.ExperimentID)
+		require.Equal(t, banner.ID, explanation.Chosen.ID)
+		require.Len(t, explanation.Candidates, 3)
+
+		explanation, err = app.ExplainBannerForSlot(slot.ID, 0, "")
+		require.NoError(t, err)
+		require.Equal(t, rotator.RuleExperiment, explanation.Rule)
+		require.Equal(t, experiment.ID, explanation.ExperimentID)
+		require.Nil(t, explanation.Chosen)
+	})
+
+	t.Run("end experiment", func(t *testing.T) {
+		ended, err := app.EndExperiment(experiment.ID)
+		require.NoError(t, err)
+		require.NotZero(t, ended.EndedAt)
+
+		_, err = app.EndExperiment(experiment.ID)
+		require.ErrorIs(t, err, storage.ErrExperimentNotEnded)
+
+		shown := make(map[int64]bool)
+		for i := 0; i < 10; i++ {
+			banner, err := app.BannerForVisitor(slot.ID, group.ID, "visitor", nil)
+			require.NoError(t, err)
+			shown[banner.ID] = true
+		}
+		require.True(t, shown[other.ID])
+
+		explanation, err := app.ExplainBannerForSlot(slot.ID, 0, "visitor")
+		require.NoError(t, err)
+		require.Zero(t, explanation.ExperimentID)
+		require.NotEqual(t, rotator.RuleExperiment, explanation.Rule)
+	})
+}
+
+func TestRotator_Groups(t *testing.T) {
+	s := memorystorage.NewStorage()
+	app := rotator.NewApp(s, publisher.NewNopPublisher(), bandit.NewBandit(), rotator.WithGroupMinViews(20))
+
+	slot, _ := s.CreateSlot("slot")
+	first, _ := s.CreateBanner("first")
+	second, _ := s.CreateBanner("second")
+	require.NoError(t, app.CreateRotation(slot.ID, first.ID, rotator.Prior{}))
+	require.NoError(t, app.CreateRotation(slot.ID, second.ID, rotator.Prior{}))
+
+	women, _ := app.CreateGroup("women")
+	young, _ := app.CreateGroup("women 18-24")
+	teens, _ := app.CreateGroup("women 16-17")
+	require.NoError(t, app.SetGroupParent(young.ID, women.ID))
+	require.NoError(t, app.SetGroupParent(teens.ID, women.ID))
+
+	// the first banner wins among women, the second one among the young
+	for i := 0; i < 100; i++ {
+		require.NoError(t, s.CreateViewEvent(slot.ID, first.ID, women.ID, 1))
+		require.NoError(t, s.CreateViewEvent(slot.ID, second.ID, women.ID, 1))
+	}
+	for i := 0; i < 50; i++ {
+		require.NoError(t, s.CreateClickEvent(slot.ID, first.ID, women.ID, 1))
+	}
+	for i := 0; i < 15; i++ {
+		require.NoError(t, s.CreateViewEvent(slot.ID, first.ID, young.ID, 1))
+		require.NoError(t, s.CreateViewEvent(slot.ID, second.ID, young.ID, 1))
+		require.NoError(t, s.CreateClickEvent(slot.ID, second.ID, young.ID, 1))
+	}
+
+	t.Run("group without views borrows parent statistics", func(t *testing.T) {
+		banner, err := app.BannerForSlot(slot.ID, teens.ID)
+		require.NoError(t, err)
+		require.Equal(t, first.ID, banner.ID)
+	})
+
+	t.Run("group with enough views", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			banner, err := app.BannerForSlot(slot.ID, young.ID)
+			require.NoError(t, err)
+			require.Equal(t, second.ID, banner.ID)
+		}
+	})
+
+	t.Run("explain group", func(t *testing.T) {
+		explanation, err := app.ExplainBannerForSlot(slot.ID, young.ID, "")
+		require.NoError(t, err)
+		require.Equal(t, second.ID, explanation.Chosen.ID)
+
+		explanation, err = app.ExplainBannerForSlot(slot.ID, 0, "")
+		require.NoError(t, err)
+		require.Equal(t, first.ID, explanation.Chosen.ID)
+
+		_, err = app.ExplainBannerForSlot(slot.ID, 100, "")
+		require.ErrorIs(t, err, storage.ErrGroupNotFound)
+	})
+
+	t.Run("top-level group", func(t *testing.T) {
+		banner, err := app.BannerForSlot(slot.ID, women.ID)
+		require.NoError(t, err)
+		require.Equal(t, first.ID, banner.ID)
+	})
+
+	t.Run("invalid parent", func(t *testing.T) {
+		err := app.SetGroupParent(women.ID, young.ID)
+		require.ErrorIs(t, err, rotator.ErrInvalidGroupParent)
+
+		err = app.SetGroupParent(women.ID, women.ID)
+		require.ErrorIs(t, err, rotator.ErrInvalidGroupParent)
+
+		err = app.SetGroupParent(young.ID, 100)
+		require.ErrorIs(t, err, storage.ErrGroupParentNotSet)
+
+		groups, err := app.Groups()
+		require.NoError(t, err)
+		require.Len(t, groups, 3)
+		require.Zero(t, groups[0].ParentID)
+		require.Equal(t, women.ID, groups[1].ParentID)
+	})
+}
+
+type recordingSelections struct {
+	paths []string
+}
+
+func (o *recordingSelections) ObserveSelection(slotID int64, path string) {
+	o.paths = append(o.paths, path)
+}
+
+func TestRotator_SelectionObserver(t *testing.T) {
+	s := memorystorage.NewStorage()
+	o := &recordingSelections{}
+	app := rotator.NewApp(s, publisher.NewNopPublisher(), bandit.NewBandit(), rotator.WithSelectionObserver(o))
+
+	slot, _ := s.CreateSlot("slot")
+	group, _ := s.CreateGroup("group")
+	first, _ := s.CreateBanner("first")
+	second, _ := s.CreateBanner("second")
+	for _, banner := range []*storage.Banner{first, second} {
+		require.NoError(t, app.CreateRotation(slot.ID, banner.ID, rotator.Prior{}))
+	}
+
+	t.Run("not viewed banners are explored", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			_, err := app.BannerForSlot(slot.ID, group.ID)
+			require.NoError(t, err)
+		}
+		require.Equal(t, []string{rotator.PathExploration, rotator.PathExploration}, o.paths)
+	})
+
+	t.Run("clicked banner is top rated", func(t *testing.T) {
+		o.paths = nil
+		require.NoError(t, app.CreateClickEvent(slot.ID, first.ID, group.ID, ""))
+
+		banner, err := app.BannerForSlot(slot.ID, group.ID)
+		require.NoError(t, err)
+		require.Equal(t, first.ID, banner.ID)
+		require.Equal(t, []string{rotator.PathTopRated}, o.paths)
+	})
+
+	t.Run("experiment", func(t *testing.T) {
+		o.paths = nil
+		arms := []storage.ExperimentArm{{BannerID: first.ID, Share: 50}, {BannerID: second.ID, Share: 50}}
+		_, err := app.StartExperiment(slot.ID, arms)
+		require.NoError(t, err)
+
+		_, err = app.BannerForVisitor(slot.ID, group.ID, "visitor", nil)
+		require.NoError(t, err)
+		require.Equal(t, []string{rotator.PathExperiment}, o.paths)
+	})
+}
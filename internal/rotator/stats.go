@@ -0,0 +1,118 @@
+package rotator
+
+import (
+	"banners-rotator/internal/storage"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// BannerStats is the performance of a banner in a slot for a time range.
+// Score is the current score of the banner over all events of the slot by
+// the reward model of the slot and its prior,
+// Share is the part of the views of the slot, or of the group when the
+// stats are split by groups.
+type BannerStats struct {
+	storage.BannerStats
+	CTR   float64
+	Score float64
+	Share float64
+}
+
+// SlotStats reports the banners of the slot with events from <= date < to,
+// zero to selects all events since from. Rotated banners without events are
+// reported with zero counters unless the stats are split by groups.
+func (r *Rotator) SlotStats(slotID, from, to int64, byGroup bool) ([]BannerStats, error) {
+	if to == 0 {
+		to = math.MaxInt64
+	}
+
+	ranged, err := r.storage.SlotStats(slotID, from, to, byGroup)
+	if err != nil {
+		return nil, fmt.Errorf("rotator -> slot stats -> %w", err)
+	}
+
+	overall, err := r.storage.SlotStats(slotID, 0, math.MaxInt64, false)
+	if err != nil {
+		return nil, fmt.Errorf("rotator -> slot stats -> %w", err)
+	}
+
+	var totalViews int64
+	overallByBanner := make(map[int64]storage.BannerStats, len(*overall))
+	for _, s := range *overall {
+		totalViews += s.Views
+		overallByBanner[s.BannerID] = s
+	}
+
+	rewards, err := r.slotRewards(slotID)
+	if err != nil {
+		return nil, fmt.Errorf("rotator -> slot stats -> %w", err)
+	}
+
+	rewardsByBanner := make(map[int64]float64)
+	for _, reward := range rewards {
+		rewardsByBanner[reward.BannerID] += reward.Value
+	}
+
+	priors, err := r.slotPriors(slotID)
+	if err != nil {
+		return nil, fmt.Errorf("rotator -> slot stats -> %w", err)
+	}
+	for _, p := range priors {
+		totalViews += p.Views
+	}
+
+	rows := *ranged
+	if !byGroup {
+		banners, err := r.storage.SlotBanners(slotID)
+		if err != nil {
+			return nil, fmt.Errorf("rotator -> slot stats -> %w", err)
+		}
+
+		rows = withIdleBanners(rows, *banners)
+	}
+
+	groupViews := make(map[int64]int64)
+	for _, s := range rows {
+		groupViews[s.GroupID] += s.Views
+	}
+
+	result := make([]BannerStats, 0, len(rows))
+	for _, s := range rows {
+		o := overallByBanner[s.BannerID]
+		p := priors[s.BannerID]
+		bs := BannerStats{
+			BannerStats: s,
+			Score:       r.b.Score(o.Views+p.Views, rewardsByBanner[s.BannerID]+p.Rewards, totalViews),
+		}
+		if s.Views > 0 {
+			bs.CTR = float64(s.Clicks) / float64(s.Views)
+			bs.Share = float64(s.Views) / float64(groupViews[s.GroupID])
+		}
+		result = append(result, bs)
+	}
+
+	return result, nil
+}
+
+// withIdleBanners adds the zero counters of the banners without events to
+// the stats ordered by banner.
+func withIdleBanners(stats []storage.BannerStats, banners []storage.Banner) []storage.BannerStats {
+	seen := make(map[int64]struct{}, len(stats))
+	for _, s := range stats {
+		seen[s.BannerID] = struct{}{}
+	}
+
+	result := append([]storage.BannerStats(nil), stats...)
+	for _, banner := range banners {
+		if _, ok := seen[banner.ID]; !ok {
+			result = append(result, storage.BannerStats{BannerID: banner.ID})
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].BannerID < result[j].BannerID
+	})
+
+	return result
+}
@@ -29,6 +29,20 @@ type BannersRotatorClient interface {
 	DeleteRotation(ctx context.Context, in *Rotation, opts ...grpc.CallOption) (*Message, error)
 	CreateClickEvent(ctx context.Context, in *ClickEvent, opts ...grpc.CallOption) (*Message, error)
 	BannerForSlot(ctx context.Context, in *SlotRequest, opts ...grpc.CallOption) (*Banner, error)
+	GetSlotStats(ctx context.Context, in *SlotStatsRequest, opts ...grpc.CallOption) (*SlotStats, error)
+	GetCTRReport(ctx context.Context, in *CTRReportRequest, opts ...grpc.CallOption) (*CTRReport, error)
+	ExplainBannerForSlot(ctx context.Context, in *SlotRequest, opts ...grpc.CallOption) (*BannerExplanation, error)
+	CreateConversionEvent(ctx context.Context, in *ConversionEvent, opts ...grpc.CallOption) (*Message, error)
+	SetSlotRewardModel(ctx context.Context, in *SlotRewardModel, opts ...grpc.CallOption) (*Message, error)
+	SetSlotPruningPolicy(ctx context.Context, in *PruningPolicy, opts ...grpc.CallOption) (*Message, error)
+	PruneSlot(ctx context.Context, in *SlotRequest, opts ...grpc.CallOption) (*PruningReport, error)
+	GetPruningReport(ctx context.Context, in *SlotRequest, opts ...grpc.CallOption) (*PruningReport, error)
+	ResumeRotation(ctx context.Context, in *Rotation, opts ...grpc.CallOption) (*Message, error)
+	StartExperiment(ctx context.Context, in *Experiment, opts ...grpc.CallOption) (*Experiment, error)
+	EndExperiment(ctx context.Context, in *ExperimentRequest, opts ...grpc.CallOption) (*Experiment, error)
+	GetExperimentResults(ctx context.Context, in *ExperimentRequest, opts ...grpc.CallOption) (*ExperimentResults, error)
+	SetGroupParent(ctx context.Context, in *GroupParent, opts ...grpc.CallOption) (*Group, error)
+	ListGroups(ctx context.Context, in *ListGroupsRequest, opts ...grpc.CallOption) (*Groups, error)
 }
 
 type bannersRotatorClient struct {
@@ -102,6 +116,132 @@ func (c *bannersRotatorClient) BannerForSlot(ctx context.Context, in *SlotReques
 	return out, nil
 }
 
+func (c *bannersRotatorClient) GetSlotStats(ctx context.Context, in *SlotStatsRequest, opts ...grpc.CallOption) (*SlotStats, error) {
+	out := new(SlotStats)
+	err := c.cc.Invoke(ctx, "/bannersrotator.BannersRotator/GetSlotStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bannersRotatorClient) GetCTRReport(ctx context.Context, in *CTRReportRequest, opts ...grpc.CallOption) (*CTRReport, error) {
+	out := new(CTRReport)
+	err := c.cc.Invoke(ctx, "/bannersrotator.BannersRotator/GetCTRReport", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bannersRotatorClient) ExplainBannerForSlot(ctx context.Context, in *SlotRequest, opts ...grpc.CallOption) (*BannerExplanation, error) {
+	out := new(BannerExplanation)
+	err := c.cc.Invoke(ctx, "/bannersrotator.BannersRotator/ExplainBannerForSlot", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bannersRotatorClient) CreateConversionEvent(ctx context.Context, in *ConversionEvent, opts ...grpc.CallOption) (*Message, error) {
+	out := new(Message)
+	err := c.cc.Invoke(ctx, "/bannersrotator.BannersRotator/CreateConversionEvent", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bannersRotatorClient) SetSlotRewardModel(ctx context.Context, in *SlotRewardModel, opts ...grpc.CallOption) (*Message, error) {
+	out := new(Message)
+	err := c.cc.Invoke(ctx, "/bannersrotator.BannersRotator/SetSlotRewardModel", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bannersRotatorClient) SetSlotPruningPolicy(ctx context.Context, in *PruningPolicy, opts ...grpc.CallOption) (*Message, error) {
+	out := new(Message)
+	err := c.cc.Invoke(ctx, "/bannersrotator.BannersRotator/SetSlotPruningPolicy", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bannersRotatorClient) PruneSlot(ctx context.Context, in *SlotRequest, opts ...grpc.CallOption) (*PruningReport, error) {
+	out := new(PruningReport)
+	err := c.cc.Invoke(ctx, "/bannersrotator.BannersRotator/PruneSlot", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bannersRotatorClient) GetPruningReport(ctx context.Context, in *SlotRequest, opts ...grpc.CallOption) (*PruningReport, error) {
+	out := new(PruningReport)
+	err := c.cc.Invoke(ctx, "/bannersrotator.BannersRotator/GetPruningReport", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bannersRotatorClient) ResumeRotation(ctx context.Context, in *Rotation, opts ...grpc.CallOption) (*Message, error) {
+	out := new(Message)
+	err := c.cc.Invoke(ctx, "/bannersrotator.BannersRotator/ResumeRotation", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bannersRotatorClient) StartExperiment(ctx context.Context, in *Experiment, opts ...grpc.CallOption) (*Experiment, error) {
+	out := new(Experiment)
+	err := c.cc.Invoke(ctx, "/bannersrotator.BannersRotator/StartExperiment", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bannersRotatorClient) EndExperiment(ctx context.Context, in *ExperimentRequest, opts ...grpc.CallOption) (*Experiment, error) {
+	out := new(Experiment)
+	err := c.cc.Invoke(ctx, "/bannersrotator.BannersRotator/EndExperiment", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bannersRotatorClient) GetExperimentResults(ctx context.Context, in *ExperimentRequest, opts ...grpc.CallOption) (*ExperimentResults, error) {
+	out := new(ExperimentResults)
+	err := c.cc.Invoke(ctx, "/bannersrotator.BannersRotator/GetExperimentResults", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bannersRotatorClient) SetGroupParent(ctx context.Context, in *GroupParent, opts ...grpc.CallOption) (*Group, error) {
+	out := new(Group)
+	err := c.cc.Invoke(ctx, "/bannersrotator.BannersRotator/SetGroupParent", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bannersRotatorClient) ListGroups(ctx context.Context, in *ListGroupsRequest, opts ...grpc.CallOption) (*Groups, error) {
+	out := new(Groups)
+	err := c.cc.Invoke(ctx, "/bannersrotator.BannersRotator/ListGroups", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // BannersRotatorServer is the server API for BannersRotator service.
 // All implementations must embed UnimplementedBannersRotatorServer
 // for forward compatibility
@@ -113,6 +253,20 @@ type BannersRotatorServer interface {
 	DeleteRotation(context.Context, *Rotation) (*Message, error)
 	CreateClickEvent(context.Context, *ClickEvent) (*Message, error)
 	BannerForSlot(context.Context, *SlotRequest) (*Banner, error)
+	GetSlotStats(context.Context, *SlotStatsRequest) (*SlotStats, error)
+	GetCTRReport(context.Context, *CTRReportRequest) (*CTRReport, error)
+	ExplainBannerForSlot(context.Context, *SlotRequest) (*BannerExplanation, error)
+	CreateConversionEvent(context.Context, *ConversionEvent) (*Message, error)
+	SetSlotRewardModel(context.Context, *SlotRewardModel) (*Message, error)
+	SetSlotPruningPolicy(context.Context, *PruningPolicy) (*Message, error)
+	PruneSlot(context.Context, *SlotRequest) (*PruningReport, error)
+	GetPruningReport(context.Context, *SlotRequest) (*PruningReport, error)
+	ResumeRotation(context.Context, *Rotation) (*Message, error)
+	StartExperiment(context.Context, *Experiment) (*Experiment, error)
+	EndExperiment(context.Context, *ExperimentRequest) (*Experiment, error)
+	GetExperimentResults(context.Context, *ExperimentRequest) (*ExperimentResults, error)
+	SetGroupParent(context.Context, *GroupParent) (*Group, error)
+	ListGroups(context.Context, *ListGroupsRequest) (*Groups, error)
 	mustEmbedUnimplementedBannersRotatorServer()
 }
 
@@ -141,6 +295,48 @@ func (UnimplementedBannersRotatorServer) CreateClickEvent(context.Context, *Clic
 func (UnimplementedBannersRotatorServer) BannerForSlot(context.Context, *SlotRequest) (*Banner, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method BannerForSlot not implemented")
 }
+func (UnimplementedBannersRotatorServer) GetSlotStats(context.Context, *SlotStatsRequest) (*SlotStats, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSlotStats not implemented")
+}
+func (UnimplementedBannersRotatorServer) GetCTRReport(context.Context, *CTRReportRequest) (*CTRReport, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCTRReport not implemented")
+}
+func (UnimplementedBannersRotatorServer) ExplainBannerForSlot(context.Context, *SlotRequest) (*BannerExplanation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExplainBannerForSlot not implemented")
+}
+func (UnimplementedBannersRotatorServer) CreateConversionEvent(context.Context, *ConversionEvent) (*Message, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateConversionEvent not implemented")
+}
+func (UnimplementedBannersRotatorServer) SetSlotRewardModel(context.Context, *SlotRewardModel) (*Message, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetSlotRewardModel not implemented")
+}
+func (UnimplementedBannersRotatorServer) SetSlotPruningPolicy(context.Context, *PruningPolicy) (*Message, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetSlotPruningPolicy not implemented")
+}
+func (UnimplementedBannersRotatorServer) PruneSlot(context.Context, *SlotRequest) (*PruningReport, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PruneSlot not implemented")
+}
+func (UnimplementedBannersRotatorServer) GetPruningReport(context.Context, *SlotRequest) (*PruningReport, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPruningReport not implemented")
+}
+func (UnimplementedBannersRotatorServer) ResumeRotation(context.Context, *Rotation) (*Message, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResumeRotation not implemented")
+}
+func (UnimplementedBannersRotatorServer) StartExperiment(context.Context, *Experiment) (*Experiment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartExperiment not implemented")
+}
+func (UnimplementedBannersRotatorServer) EndExperiment(context.Context, *ExperimentRequest) (*Experiment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EndExperiment not implemented")
+}
+func (UnimplementedBannersRotatorServer) GetExperimentResults(context.Context, *ExperimentRequest) (*ExperimentResults, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetExperimentResults not implemented")
+}
+func (UnimplementedBannersRotatorServer) SetGroupParent(context.Context, *GroupParent) (*Group, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetGroupParent not implemented")
+}
+func (UnimplementedBannersRotatorServer) ListGroups(context.Context, *ListGroupsRequest) (*Groups, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListGroups not implemented")
+}
 func (UnimplementedBannersRotatorServer) mustEmbedUnimplementedBannersRotatorServer() {}
 
 // UnsafeBannersRotatorServer may be embedded to opt out of forward compatibility for this service.
@@ -280,6 +476,258 @@ func _BannersRotator_BannerForSlot_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _BannersRotator_GetSlotStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SlotStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BannersRotatorServer).GetSlotStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bannersrotator.BannersRotator/GetSlotStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BannersRotatorServer).GetSlotStats(ctx, req.(*SlotStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BannersRotator_GetCTRReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CTRReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BannersRotatorServer).GetCTRReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bannersrotator.BannersRotator/GetCTRReport",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BannersRotatorServer).GetCTRReport(ctx, req.(*CTRReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BannersRotator_ExplainBannerForSlot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SlotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BannersRotatorServer).ExplainBannerForSlot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bannersrotator.BannersRotator/ExplainBannerForSlot",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BannersRotatorServer).ExplainBannerForSlot(ctx, req.(*SlotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BannersRotator_CreateConversionEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConversionEvent)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BannersRotatorServer).CreateConversionEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bannersrotator.BannersRotator/CreateConversionEvent",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BannersRotatorServer).CreateConversionEvent(ctx, req.(*ConversionEvent))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BannersRotator_SetSlotRewardModel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SlotRewardModel)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BannersRotatorServer).SetSlotRewardModel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bannersrotator.BannersRotator/SetSlotRewardModel",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BannersRotatorServer).SetSlotRewardModel(ctx, req.(*SlotRewardModel))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BannersRotator_SetSlotPruningPolicy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PruningPolicy)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BannersRotatorServer).SetSlotPruningPolicy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bannersrotator.BannersRotator/SetSlotPruningPolicy",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BannersRotatorServer).SetSlotPruningPolicy(ctx, req.(*PruningPolicy))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BannersRotator_PruneSlot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SlotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BannersRotatorServer).PruneSlot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bannersrotator.BannersRotator/PruneSlot",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BannersRotatorServer).PruneSlot(ctx, req.(*SlotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BannersRotator_GetPruningReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SlotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BannersRotatorServer).GetPruningReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bannersrotator.BannersRotator/GetPruningReport",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BannersRotatorServer).GetPruningReport(ctx, req.(*SlotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BannersRotator_ResumeRotation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Rotation)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BannersRotatorServer).ResumeRotation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bannersrotator.BannersRotator/ResumeRotation",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BannersRotatorServer).ResumeRotation(ctx, req.(*Rotation))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BannersRotator_StartExperiment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Experiment)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BannersRotatorServer).StartExperiment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bannersrotator.BannersRotator/StartExperiment",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BannersRotatorServer).StartExperiment(ctx, req.(*Experiment))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BannersRotator_EndExperiment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExperimentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BannersRotatorServer).EndExperiment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bannersrotator.BannersRotator/EndExperiment",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BannersRotatorServer).EndExperiment(ctx, req.(*ExperimentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BannersRotator_GetExperimentResults_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExperimentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BannersRotatorServer).GetExperimentResults(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bannersrotator.BannersRotator/GetExperimentResults",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BannersRotatorServer).GetExperimentResults(ctx, req.(*ExperimentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BannersRotator_SetGroupParent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GroupParent)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BannersRotatorServer).SetGroupParent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bannersrotator.BannersRotator/SetGroupParent",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BannersRotatorServer).SetGroupParent(ctx, req.(*GroupParent))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BannersRotator_ListGroups_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListGroupsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BannersRotatorServer).ListGroups(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bannersrotator.BannersRotator/ListGroups",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BannersRotatorServer).ListGroups(ctx, req.(*ListGroupsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // BannersRotator_ServiceDesc is the grpc.ServiceDesc for BannersRotator service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -315,6 +763,62 @@ var BannersRotator_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "BannerForSlot",
 			Handler:    _BannersRotator_BannerForSlot_Handler,
 		},
+		{
+			MethodName: "GetSlotStats",
+			Handler:    _BannersRotator_GetSlotStats_Handler,
+		},
+		{
+			MethodName: "GetCTRReport",
+			Handler:    _BannersRotator_GetCTRReport_Handler,
+		},
+		{
+			MethodName: "ExplainBannerForSlot",
+			Handler:    _BannersRotator_ExplainBannerForSlot_Handler,
+		},
+		{
+			MethodName: "CreateConversionEvent",
+			Handler:    _BannersRotator_CreateConversionEvent_Handler,
+		},
+		{
+			MethodName: "SetSlotRewardModel",
+			Handler:    _BannersRotator_SetSlotRewardModel_Handler,
+		},
+		{
+			MethodName: "SetSlotPruningPolicy",
+			Handler:    _BannersRotator_SetSlotPruningPolicy_Handler,
+		},
+		{
+			MethodName: "PruneSlot",
+			Handler:    _BannersRotator_PruneSlot_Handler,
+		},
+		{
+			MethodName: "GetPruningReport",
+			Handler:    _BannersRotator_GetPruningReport_Handler,
+		},
+		{
+			MethodName: "ResumeRotation",
+			Handler:    _BannersRotator_ResumeRotation_Handler,
+		},
+		{
+			MethodName: "StartExperiment",
+			Handler:    _BannersRotator_StartExperiment_Handler,
+		},
+		{
+			MethodName: "EndExperiment",
+			Handler:    _BannersRotator_EndExperiment_Handler,
+		},
+		{
+			MethodName: "GetExperimentResults",
+			Handler:    _BannersRotator_GetExperimentResults_Handler,
+		},
+		{
+			MethodName: "SetGroupParent",
+			Handler:    _BannersRotator_SetGroupParent_Handler,
+		},
+		{
+			MethodName: "ListGroups",
+			Handler:    _BannersRotator_ListGroups_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "BannersRotatorService.proto",
@@ -7,6 +7,7 @@ package internalgrpc
 import (
 	"banners-rotator/internal/rotator"
 	gw "banners-rotator/internal/server/bannersrotatorpb"
+	"banners-rotator/internal/storage"
 	"context"
 	"errors"
 	"fmt"
@@ -29,12 +30,18 @@ type Server struct {
 	gw.UnimplementedBannersRotatorServer
 }
 
-func NewRPCServer(logger rotator.Logger, app rotator.App, host, port string) *Server {
+// NewRPCServer creates the server, the interceptors are chained after the
+// logging one, e.g. to collect the metrics of the requests.
+func NewRPCServer(
+	logger rotator.Logger,
+	app rotator.App,
+	host, port string,
+	interceptors ...grpc.UnaryServerInterceptor,
+) *Server {
+	chain := append([]grpc.UnaryServerInterceptor{grpc_zap.UnaryServerInterceptor(logger.Lgr())}, interceptors...)
 	s := grpc.NewServer(
 		grpc.UnaryInterceptor(
-			grpc_middleware.ChainUnaryServer(
-				grpc_zap.UnaryServerInterceptor(logger.Lgr()),
-			),
+			grpc_middleware.ChainUnaryServer(chain...),
 		),
 	)
 
@@ -50,7 +57,11 @@ func (s *Server) Start() error {
 		return fmt.Errorf("listen grpc endpoint -> %w", err)
 	}
 
-	if err = s.srv.Serve(l); err != nil {
+	return s.Serve(l)
+}
+
+func (s *Server) Serve(l net.Listener) error {
+	if err := s.srv.Serve(l); err != nil {
 		return fmt.Errorf("start serve grpc -> %w", err)
 	}
 
@@ -96,14 +107,21 @@ func (s *Server) CreateGroup(ctx context.Context, in *gw.Group) (*gw.Group, erro
 		return nil, status.Errorf(codes.InvalidArgument, "%s: incorrect description", ErrBadRequest)
 	}
 
-	group, err := s.app.CreateGroup(in.Description)
+	if in.ParentId < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "%s: incorrect parent id", ErrBadRequest)
+	}
+
+	group, err := s.app.CreateGroupWithParent(in.Description, in.ParentId)
+	if errors.Is(err, storage.ErrGroupParentNotSet) {
+		return nil, status.Errorf(codes.InvalidArgument, "%s: incorrect parent id", ErrBadRequest)
+	}
 	if err != nil {
 		s.logger.Error(fmt.Sprintf("create gorup handler -> %s", err))
 
 		return nil, status.Errorf(codes.Internal, "internal server error")
 	}
 
-	return &gw.Group{Id: group.ID, Description: group.Description}, nil
+	return &gw.Group{Id: group.ID, Description: group.Description, ParentId: group.ParentID}, nil
 }
 
 func (s *Server) CreateRotation(ctx context.Context, in *gw.Rotation) (*gw.Message, error) {
@@ -115,7 +133,17 @@ func (s *Server) CreateRotation(ctx context.Context, in *gw.Rotation) (*gw.Messa
 		return nil, status.Errorf(codes.InvalidArgument, "%s: incorrect banner id", ErrBadRequest)
 	}
 
-	err := s.app.CreateRotation(in.SlotId, in.BannerId)
+	err := s.app.CreateRotation(in.SlotId, in.BannerId, rotator.Prior{
+		Views:   in.PriorViews,
+		Rewards: in.PriorRewards,
+		Inherit: in.InheritPrior,
+	})
+	if errors.Is(err, rotator.ErrInvalidPrior) {
+		return nil, status.Errorf(codes.InvalidArgument, "%s: %s", ErrBadRequest, err)
+	}
+	if errors.Is(err, storage.ErrRotationExists) {
+		return nil, status.Errorf(codes.AlreadyExists, err.Error())
+	}
 	if err != nil {
 		s.logger.Error(fmt.Sprintf("create rotation handler -> %s", err))
 
@@ -157,7 +185,7 @@ func (s *Server) CreateClickEvent(ctx context.Context, in *gw.ClickEvent) (*gw.M
 		return nil, status.Errorf(codes.InvalidArgument, "%s: incorrect group id", ErrBadRequest)
 	}
 
-	err := s.app.CreateClickEvent(in.SlotId, in.BannerId, in.GroupId)
+	err := s.app.CreateContextualClickEvent(in.SlotId, in.BannerId, in.GroupId, in.Features, in.ImpressionId)
 	if err != nil {
 		s.logger.Error(fmt.Sprintf("create click event handler -> %s", err))
 
@@ -176,12 +204,411 @@ func (s *Server) BannerForSlot(ctx context.Context, in *gw.SlotRequest) (*gw.Ban
 		return nil, status.Errorf(codes.InvalidArgument, "%s: incorrect group id", ErrBadRequest)
 	}
 
-	banner, err := s.app.BannerForSlot(in.SlotId, in.GroupId)
+	banner, err := s.app.BannerForVisitor(in.SlotId, in.GroupId, in.VisitorId, in.Features)
 	if err != nil {
 		s.logger.Error(fmt.Sprintf("get banner for slot handler -> %s", err))
 
 		return nil, status.Errorf(codes.Internal, err.Error())
 	}
 
-	return &gw.Banner{Id: banner.ID, Description: banner.Description}, nil
+	return &gw.Banner{Id: banner.ID, Description: banner.Description, ImpressionId: banner.ImpressionID}, nil
+}
+
+func (s *Server) GetSlotStats(ctx context.Context, in *gw.SlotStatsRequest) (*gw.SlotStats, error) {
+	if in.SlotId <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "%s: incorrect slot id", ErrBadRequest)
+	}
+
+	if in.From < 0 || (in.To != 0 && in.To <= in.From) {
+		return nil, status.Errorf(codes.InvalidArgument, "%s: incorrect time range", ErrBadRequest)
+	}
+
+	stats, err := s.app.SlotStats(in.SlotId, in.From, in.To, in.ByGroup)
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("get slot stats handler -> %s", err))
+
+		return nil, status.Errorf(codes.Internal, err.Error())
+	}
+
+	result := &gw.SlotStats{SlotId: in.SlotId, Banners: make([]*gw.BannerStats, 0, len(stats))}
+	for _, bs := range stats {
+		result.Banners = append(result.Banners, &gw.BannerStats{
+			BannerId: bs.BannerID,
+			GroupId:  bs.GroupID,
+			Views:    bs.Views,
+			Clicks:   bs.Clicks,
+			Ctr:      bs.CTR,
+			Score:    bs.Score,
+			Share:    bs.Share,
+		})
+	}
+
+	return result, nil
+}
+
+func (s *Server) GetCTRReport(ctx context.Context, in *gw.CTRReportRequest) (*gw.CTRReport, error) {
+	if in.SlotId <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "%s: incorrect slot id", ErrBadRequest)
+	}
+
+	points, err := s.app.CTRReport(rotator.ReportFilter{
+		SlotID:   in.SlotId,
+		BannerID: in.BannerId,
+		GroupID:  in.GroupId,
+		From:     in.From,
+		To:       in.To,
+		Bucket:   in.Bucket,
+	})
+	if errors.Is(err, rotator.ErrUnknownBucket) || errors.Is(err, rotator.ErrInvalidRange) {
+		return nil, status.Errorf(codes.InvalidArgument, "%s: %s", ErrBadRequest, err)
+	}
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("get ctr report handler -> %s", err))
+
+		return nil, status.Errorf(codes.Internal, err.Error())
+	}
+
+	result := &gw.CTRReport{Points: make([]*gw.CTRPoint, 0, len(points))}
+	for _, p := range points {
+		result.Points = append(result.Points, &gw.CTRPoint{
+			Time:   p.Time,
+			Views:  p.Views,
+			Clicks: p.Clicks,
+			Ctr:    p.CTR,
+		})
+	}
+
+	return result, nil
+}
+
+func (s *Server) ExplainBannerForSlot(ctx context.Context, in *gw.SlotRequest) (*gw.BannerExplanation, error) {
+	if in.SlotId <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "%s: incorrect slot id", ErrBadRequest)
+	}
+
+	if in.GroupId < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "%s: incorrect group id", ErrBadRequest)
+	}
+
+	explanation, err := s.app.ExplainBannerForSlot(in.SlotId, in.GroupId, in.VisitorId)
+	if errors.Is(err, storage.ErrGroupNotFound) {
+		return nil, status.Errorf(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("explain banner for slot handler -> %s", err))
+
+		return nil, status.Errorf(codes.Internal, err.Error())
+	}
+
+	result := &gw.BannerExplanation{
+		Candidates:   make([]*gw.Candidate, 0, len(explanation.Candidates)),
+		Rule:         explanation.Rule,
+		ExperimentId: explanation.ExperimentID,
+	}
+	if explanation.Chosen != nil {
+		result.Chosen = &gw.Banner{Id: explanation.Chosen.ID, Description: explanation.Chosen.Description}
+	}
+	for _, c := range explanation.Candidates {
+		result.Candidates = append(result.Candidates, &gw.Candidate{
+			Banner:           &gw.Banner{Id: c.Banner.ID, Description: c.Banner.Description},
+			Views:            c.Views,
+			Clicks:           c.Rewarded,
+			AverageReward:    c.Reward,
+			ExplorationBonus: c.Bonus,
+			Score:            c.Score,
+			NotViewed:        c.NotViewed,
+			Rewards:          c.Rewards,
+			PriorViews:       c.Prior.Views,
+			PriorRewards:     c.Prior.Rewards,
+		})
+	}
+
+	return result, nil
+}
+
+func (s *Server) CreateConversionEvent(ctx context.Context, in *gw.ConversionEvent) (*gw.Message, error) {
+	if in.SlotId <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "%s: incorrect slot id", ErrBadRequest)
+	}
+
+	if in.BannerId <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "%s: incorrect banner id", ErrBadRequest)
+	}
+
+	if in.GroupId <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "%s: incorrect group id", ErrBadRequest)
+	}
+
+	err := s.app.CreateConversionEvent(in.SlotId, in.BannerId, in.GroupId, in.Type, in.Value, in.ImpressionId)
+	if errors.Is(err, rotator.ErrUnknownConversionType) || errors.Is(err, rotator.ErrInvalidConversionValue) {
+		return nil, status.Errorf(codes.InvalidArgument, "%s: %s", ErrBadRequest, err)
+	}
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("create conversion event handler -> %s", err))
+
+		return nil, status.Errorf(codes.Internal, err.Error())
+	}
+
+	return &gw.Message{Message: "Conversion event was registered"}, nil
+}
+
+func (s *Server) SetSlotRewardModel(ctx context.Context, in *gw.SlotRewardModel) (*gw.Message, error) {
+	if in.SlotId <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "%s: incorrect slot id", ErrBadRequest)
+	}
+
+	err := s.app.SetSlotRewardModel(in.SlotId, in.RewardModel)
+	if errors.Is(err, rotator.ErrUnknownRewardModel) {
+		return nil, status.Errorf(codes.InvalidArgument, "%s: %s", ErrBadRequest, err)
+	}
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("set slot reward model handler -> %s", err))
+
+		return nil, status.Errorf(codes.Internal, err.Error())
+	}
+
+	return &gw.Message{Message: "Reward model was set"}, nil
+}
+
+func (s *Server) SetSlotPruningPolicy(ctx context.Context, in *gw.PruningPolicy) (*gw.Message, error) {
+	if in.SlotId <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "%s: incorrect slot id", ErrBadRequest)
+	}
+
+	err := s.app.SetSlotPruningPolicy(storage.PruningPolicy{
+		SlotID:     in.SlotId,
+		Enabled:    in.Enabled,
+		Confidence: in.Confidence,
+		MinViews:   in.MinViews,
+	})
+	if errors.Is(err, rotator.ErrInvalidPruningPolicy) {
+		return nil, status.Errorf(codes.InvalidArgument, "%s: %s", ErrBadRequest, err)
+	}
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("set slot pruning policy handler -> %s", err))
+
+		return nil, status.Errorf(codes.Internal, err.Error())
+	}
+
+	return &gw.Message{Message: "Pruning policy was set"}, nil
+}
+
+func (s *Server) PruneSlot(ctx context.Context, in *gw.SlotRequest) (*gw.PruningReport, error) {
+	if in.SlotId <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "%s: incorrect slot id", ErrBadRequest)
+	}
+
+	prunings, err := s.app.PruneSlot(in.SlotId)
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("prune slot handler -> %s", err))
+
+		return nil, status.Errorf(codes.Internal, err.Error())
+	}
+
+	return pruningReport(in.SlotId, prunings), nil
+}
+
+func (s *Server) GetPruningReport(ctx context.Context, in *gw.SlotRequest) (*gw.PruningReport, error) {
+	if in.SlotId <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "%s: incorrect slot id", ErrBadRequest)
+	}
+
+	prunings, err := s.app.PruningReport(in.SlotId)
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("get pruning report handler -> %s", err))
+
+		return nil, status.Errorf(codes.Internal, err.Error())
+	}
+
+	return pruningReport(in.SlotId, prunings), nil
+}
+
+func (s *Server) ResumeRotation(ctx context.Context, in *gw.Rotation) (*gw.Message, error) {
+	if in.SlotId <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "%s: incorrect slot id", ErrBadRequest)
+	}
+
+	if in.BannerId <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "%s: incorrect banner id", ErrBadRequest)
+	}
+
+	err := s.app.ResumeRotation(in.SlotId, in.BannerId)
+	if errors.Is(err, storage.ErrRotationNotResumed) {
+		return nil, status.Errorf(codes.FailedPrecondition, err.Error())
+	}
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("resume rotation handler -> %s", err))
+
+		return nil, status.Errorf(codes.Internal, err.Error())
+	}
+
+	return &gw.Message{Message: "Rotation was resumed"}, nil
+}
+
+func pruningReport(slotID int64, prunings []storage.Pruning) *gw.PruningReport {
+	result := &gw.PruningReport{SlotId: slotID, Prunings: make([]*gw.Pruning, 0, len(prunings))}
+	for _, p := range prunings {
+		result.Prunings = append(result.Prunings, &gw.Pruning{
+			BannerId:      p.BannerID,
+			LeaderId:      p.LeaderID,
+			Views:         p.Views,
+			Rewards:       p.Rewards,
+			LeaderViews:   p.LeaderViews,
+			LeaderRewards: p.LeaderRewards,
+			Probability:   p.Probability,
+			Date:          p.Date,
+		})
+	}
+
+	return result
+}
+
+func (s *Server) StartExperiment(ctx context.Context, in *gw.Experiment) (*gw.Experiment, error) {
+	if in.SlotId <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "%s: incorrect slot id", ErrBadRequest)
+	}
+
+	arms := make([]storage.ExperimentArm, 0, len(in.Arms))
+	for _, arm := range in.Arms {
+		arms = append(arms, storage.ExperimentArm{BannerID: arm.BannerId, Share: arm.Share})
+	}
+
+	experiment, err := s.app.StartExperiment(in.SlotId, arms)
+	if errors.Is(err, rotator.ErrInvalidExperiment) {
+		return nil, status.Errorf(codes.InvalidArgument, "%s: %s", ErrBadRequest, err)
+	}
+	if errors.Is(err, rotator.ErrExperimentRunning) {
+		return nil, status.Errorf(codes.FailedPrecondition, err.Error())
+	}
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("start experiment handler -> %s", err))
+
+		return nil, status.Errorf(codes.Internal, err.Error())
+	}
+
+	return experimentMessage(experiment), nil
+}
+
+func (s *Server) EndExperiment(ctx context.Context, in *gw.ExperimentRequest) (*gw.Experiment, error) {
+	if in.ExperimentId <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "%s: incorrect experiment id", ErrBadRequest)
+	}
+
+	experiment, err := s.app.EndExperiment(in.ExperimentId)
+	if errors.Is(err, storage.ErrExperimentNotEnded) {
+		return nil, status.Errorf(codes.FailedPrecondition, err.Error())
+	}
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("end experiment handler -> %s", err))
+
+		return nil, status.Errorf(codes.Internal, err.Error())
+	}
+
+	return experimentMessage(experiment), nil
+}
+
+func (s *Server) GetExperimentResults(ctx context.Context, in *gw.ExperimentRequest) (*gw.ExperimentResults, error) {
+	if in.ExperimentId <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "%s: incorrect experiment id", ErrBadRequest)
+	}
+
+	results, err := s.app.ExperimentResults(in.ExperimentId)
+	if errors.Is(err, storage.ErrExperimentNotFound) {
+		return nil, status.Errorf(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("get experiment results handler -> %s", err))
+
+		return nil, status.Errorf(codes.Internal, err.Error())
+	}
+
+	result := &gw.ExperimentResults{
+		Experiment:  experimentMessage(&results.Experiment),
+		Confidence:  results.Confidence,
+		Arms:        make([]*gw.ArmResult, 0, len(results.Arms)),
+		Comparisons: make([]*gw.Comparison, 0, len(results.Comparisons)),
+	}
+	for _, a := range results.Arms {
+		result.Arms = append(result.Arms, &gw.ArmResult{
+			BannerId: a.BannerID,
+			Share:    a.Share,
+			Views:    a.Views,
+			Clicks:   a.Clicks,
+			Ctr:      a.CTR,
+			CtrLow:   a.Low,
+			CtrHigh:  a.High,
+		})
+	}
+	for _, c := range results.Comparisons {
+		result.Comparisons = append(result.Comparisons, &gw.Comparison{
+			BannerId:       c.BannerID,
+			OtherId:        c.OtherID,
+			Difference:     c.Difference,
+			DifferenceLow:  c.Low,
+			DifferenceHigh: c.High,
+			PValue:         c.PValue,
+		})
+	}
+
+	return result, nil
+}
+
+func experimentMessage(e *storage.Experiment) *gw.Experiment {
+	result := &gw.Experiment{
+		Id:        e.ID,
+		SlotId:    e.SlotID,
+		Arms:      make([]*gw.ExperimentArm, 0, len(e.Arms)),
+		StartedAt: e.StartedAt,
+		EndedAt:   e.EndedAt,
+	}
+	for _, arm := range e.Arms {
+		result.Arms = append(result.Arms, &gw.ExperimentArm{BannerId: arm.BannerID, Share: arm.Share})
+	}
+
+	return result
+}
+
+func (s *Server) SetGroupParent(ctx context.Context, in *gw.GroupParent) (*gw.Group, error) {
+	if in.GroupId <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "%s: incorrect group id", ErrBadRequest)
+	}
+
+	if in.ParentId < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "%s: incorrect parent id", ErrBadRequest)
+	}
+
+	err := s.app.SetGroupParent(in.GroupId, in.ParentId)
+	if errors.Is(err, rotator.ErrInvalidGroupParent) || errors.Is(err, storage.ErrGroupParentNotSet) {
+		return nil, status.Errorf(codes.InvalidArgument, "%s: %s", ErrBadRequest, err)
+	}
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("set group parent handler -> %s", err))
+
+		return nil, status.Errorf(codes.Internal, err.Error())
+	}
+
+	group, err := s.app.Group(in.GroupId)
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("set group parent handler -> %s", err))
+
+		return nil, status.Errorf(codes.Internal, err.Error())
+	}
+
+	return &gw.Group{Id: group.ID, Description: group.Description, ParentId: group.ParentID}, nil
+}
+
+func (s *Server) ListGroups(ctx context.Context, in *gw.ListGroupsRequest) (*gw.Groups, error) {
+	groups, err := s.app.Groups()
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("list groups handler -> %s", err))
+
+		return nil, status.Errorf(codes.Internal, err.Error())
+	}
+
+	result := &gw.Groups{Groups: make([]*gw.Group, 0, len(groups))}
+	for _, g := range groups {
+		result.Groups = append(result.Groups, &gw.Group{Id: g.ID, Description: g.Description, ParentId: g.ParentID})
+	}
+
+	return result, nil
 }
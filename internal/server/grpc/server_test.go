@@ -0,0 +1,408 @@
+package internalgrpc
+
+import (
+	"banners-rotator/internal/bandit"
+	"banners-rotator/internal/bandit/linucb"
+	"banners-rotator/internal/logger"
+	"banners-rotator/internal/publisher"
+	"banners-rotator/internal/rotator"
+	gw "banners-rotator/internal/server/bannersrotatorpb"
+	memorystorage "banners-rotator/internal/storage/memory"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func getClient(t *testing.T) gw.BannersRotatorClient {
+	t.Helper()
+
+	logg, err := logger.NewLogger("error", []string{"stderr"})
+	require.NoError(t, err)
+
+	app := rotator.NewApp(
+		memorystorage.NewStorage(),
+		publisher.NewNopPublisher(),
+		bandit.NewBandit(),
+		rotator.WithContextualBandit(linucb.NewBandit(linucb.WithSeed(1))),
+	)
+	srv := NewRPCServer(logg, app, "", "")
+
+	l := bufconn.Listen(1024 * 1024)
+	go func() {
+		_ = srv.Serve(l)
+	}()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.Dial(
+		"bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
+			return l.Dial()
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		conn.Close()
+	})
+
+	return gw.NewBannersRotatorClient(conn)
+}
+
+func TestServer(t *testing.T) {
+	client := getClient(t)
+
+	t.Run("rotate banners", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		slot, err := client.CreateSlot(ctx, &gw.Slot{Description: "slot"})
+		require.NoError(t, err)
+		banner, err := client.CreateBanner(ctx, &gw.Banner{Description: "banner"})
+		require.NoError(t, err)
+		group, err := client.CreateGroup(ctx, &gw.Group{Description: "group"})
+		require.NoError(t, err)
+
+		_, err = client.CreateRotation(ctx, &gw.Rotation{SlotId: slot.Id, BannerId: banner.Id})
+		require.NoError(t, err)
+		_, err = client.CreateRotation(ctx, &gw.Rotation{SlotId: slot.Id, BannerId: banner.Id})
+		require.Equal(t, codes.AlreadyExists, status.Code(err))
+
+		explanation, err := client.ExplainBannerForSlot(ctx, &gw.SlotRequest{SlotId: slot.Id})
+		require.NoError(t, err)
+		require.Equal(t, "not_viewed", explanation.Rule)
+		require.Equal(t, banner.Id, explanation.Chosen.Id)
+		require.Len(t, explanation.Candidates, 1)
+		require.True(t, explanation.Candidates[0].NotViewed)
+
+		result, err := client.BannerForSlot(ctx, &gw.SlotRequest{SlotId: slot.Id, GroupId: group.Id})
+		require.NoError(t, err)
+		require.Equal(t, banner.Id, result.Id)
+		require.NotEmpty(t, result.ImpressionId)
+
+		msg, err := client.CreateClickEvent(ctx, &gw.ClickEvent{
+			SlotId:       slot.Id,
+			BannerId:     banner.Id,
+			GroupId:      group.Id,
+			ImpressionId: result.ImpressionId,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "Click event was registered", msg.Message)
+
+		stats, err := client.GetSlotStats(ctx, &gw.SlotStatsRequest{SlotId: slot.Id})
+		require.NoError(t, err)
+		require.Len(t, stats.Banners, 1)
+		require.Equal(t, banner.Id, stats.Banners[0].BannerId)
+		require.Equal(t, int64(1), stats.Banners[0].Views)
+		require.Equal(t, int64(1), stats.Banners[0].Clicks)
+		require.Equal(t, 1.0, stats.Banners[0].Ctr)
+
+		now := time.Now().Unix()
+		report, err := client.GetCTRReport(ctx, &gw.CTRReportRequest{
+			SlotId: slot.Id, From: now - 3600, To: now + 1, Bucket: "hour",
+		})
+		require.NoError(t, err)
+		var views int64
+		for _, p := range report.Points {
+			views += p.Views
+		}
+		require.Equal(t, int64(1), views)
+
+		msg, err = client.DeleteRotation(ctx, &gw.Rotation{SlotId: slot.Id, BannerId: banner.Id})
+		require.NoError(t, err)
+		require.Equal(t, "Rotation was deleted", msg.Message)
+	})
+
+	t.Run("rotate banners by features", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		slot, err := client.CreateSlot(ctx, &gw.Slot{Description: "slot"})
+		require.NoError(t, err)
+		banner, err := client.CreateBanner(ctx, &gw.Banner{Description: "banner"})
+		require.NoError(t, err)
+		group, err := client.CreateGroup(ctx, &gw.Group{Description: "group"})
+		require.NoError(t, err)
+		_, err = client.CreateRotation(ctx, &gw.Rotation{SlotId: slot.Id, BannerId: banner.Id})
+		require.NoError(t, err)
+
+		features := map[string]string{"device": "mobile", "country": "ru"}
+		result, err := client.BannerForSlot(ctx, &gw.SlotRequest{SlotId: slot.Id, GroupId: group.Id, Features: features})
+		require.NoError(t, err)
+		require.Equal(t, banner.Id, result.Id)
+
+		_, err = client.CreateClickEvent(ctx, &gw.ClickEvent{
+			SlotId: slot.Id, BannerId: banner.Id, GroupId: group.Id, Features: features,
+		})
+		require.NoError(t, err)
+
+		stats, err := client.GetSlotStats(ctx, &gw.SlotStatsRequest{SlotId: slot.Id})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), stats.Banners[0].Views)
+		require.Equal(t, int64(1), stats.Banners[0].Clicks)
+	})
+
+	t.Run("rotate banners by revenue", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		slot, err := client.CreateSlot(ctx, &gw.Slot{Description: "slot"})
+		require.NoError(t, err)
+		banner, err := client.CreateBanner(ctx, &gw.Banner{Description: "banner"})
+		require.NoError(t, err)
+		group, err := client.CreateGroup(ctx, &gw.Group{Description: "group"})
+		require.NoError(t, err)
+		_, err = client.CreateRotation(ctx, &gw.Rotation{SlotId: slot.Id, BannerId: banner.Id})
+		require.NoError(t, err)
+
+		msg, err := client.SetSlotRewardModel(ctx, &gw.SlotRewardModel{SlotId: slot.Id, RewardModel: "revenue"})
+		require.NoError(t, err)
+		require.Equal(t, "Reward model was set", msg.Message)
+
+		_, err = client.BannerForSlot(ctx, &gw.SlotRequest{SlotId: slot.Id, GroupId: group.Id})
+		require.NoError(t, err)
+
+		msg, err = client.CreateConversionEvent(ctx, &gw.ConversionEvent{
+			SlotId: slot.Id, BannerId: banner.Id, GroupId: group.Id, Type: "purchase", Value: 250,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "Conversion event was registered", msg.Message)
+
+		explanation, err := client.ExplainBannerForSlot(ctx, &gw.SlotRequest{SlotId: slot.Id, GroupId: group.Id})
+		require.NoError(t, err)
+		require.Equal(t, 1.0, explanation.Candidates[0].Rewards)
+	})
+
+	t.Run("rotate banner with prior", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		slot, err := client.CreateSlot(ctx, &gw.Slot{Description: "slot"})
+		require.NoError(t, err)
+		banner, err := client.CreateBanner(ctx, &gw.Banner{Description: "banner"})
+		require.NoError(t, err)
+		group, err := client.CreateGroup(ctx, &gw.Group{Description: "group"})
+		require.NoError(t, err)
+
+		_, err = client.CreateRotation(ctx, &gw.Rotation{
+			SlotId: slot.Id, BannerId: banner.Id, PriorViews: 20, PriorRewards: 5,
+		})
+		require.NoError(t, err)
+		_, err = client.CreateClickEvent(ctx, &gw.ClickEvent{SlotId: slot.Id, BannerId: banner.Id, GroupId: group.Id})
+		require.NoError(t, err)
+
+		explanation, err := client.ExplainBannerForSlot(ctx, &gw.SlotRequest{SlotId: slot.Id, GroupId: group.Id})
+		require.NoError(t, err)
+		require.False(t, explanation.Candidates[0].NotViewed)
+		require.Equal(t, int64(20), explanation.Candidates[0].PriorViews)
+		require.Equal(t, 5.0, explanation.Candidates[0].PriorRewards)
+		require.Equal(t, 0.3, explanation.Candidates[0].AverageReward)
+	})
+
+	t.Run("prune losing banner", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		slot, err := client.CreateSlot(ctx, &gw.Slot{Description: "slot"})
+		require.NoError(t, err)
+		winner, err := client.CreateBanner(ctx, &gw.Banner{Description: "winner"})
+		require.NoError(t, err)
+		loser, err := client.CreateBanner(ctx, &gw.Banner{Description: "loser"})
+		require.NoError(t, err)
+		group, err := client.CreateGroup(ctx, &gw.Group{Description: "group"})
+		require.NoError(t, err)
+		for _, banner := range []*gw.Banner{winner, loser} {
+			_, err = client.CreateRotation(ctx, &gw.Rotation{SlotId: slot.Id, BannerId: banner.Id})
+			require.NoError(t, err)
+		}
+
+		msg, err := client.SetSlotPruningPolicy(ctx, &gw.PruningPolicy{SlotId: slot.Id, Enabled: true, Confidence: 0.95})
+		require.NoError(t, err)
+		require.Equal(t, "Pruning policy was set", msg.Message)
+
+		for i := 0; i < 240; i++ {
+			_, err = client.BannerForSlot(ctx, &gw.SlotRequest{SlotId: slot.Id, GroupId: group.Id})
+			require.NoError(t, err)
+		}
+		for i := 0; i < 30; i++ {
+			_, err = client.CreateClickEvent(ctx, &gw.ClickEvent{SlotId: slot.Id, BannerId: winner.Id, GroupId: group.Id})
+			require.NoError(t, err)
+		}
+
+		report, err := client.PruneSlot(ctx, &gw.SlotRequest{SlotId: slot.Id})
+		require.NoError(t, err)
+		require.Len(t, report.Prunings, 1)
+		require.Equal(t, loser.Id, report.Prunings[0].BannerId)
+		require.Equal(t, winner.Id, report.Prunings[0].LeaderId)
+		require.Greater(t, report.Prunings[0].Probability, 0.95)
+
+		for i := 0; i < 5; i++ {
+			banner, err := client.BannerForSlot(ctx, &gw.SlotRequest{SlotId: slot.Id, GroupId: group.Id})
+			require.NoError(t, err)
+			require.Equal(t, winner.Id, banner.Id)
+		}
+
+		report, err = client.GetPruningReport(ctx, &gw.SlotRequest{SlotId: slot.Id})
+		require.NoError(t, err)
+		require.Len(t, report.Prunings, 1)
+		require.Equal(t, loser.Id, report.Prunings[0].BannerId)
+
+		msg, err = client.ResumeRotation(ctx, &gw.Rotation{SlotId: slot.Id, BannerId: loser.Id})
+		require.NoError(t, err)
+		require.Equal(t, "Rotation was resumed", msg.Message)
+		_, err = client.ResumeRotation(ctx, &gw.Rotation{SlotId: slot.Id, BannerId: loser.Id})
+		require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+		_, err = client.SetSlotPruningPolicy(ctx, &gw.PruningPolicy{
+			SlotId: slot.Id, Enabled: true, Confidence: 0.95, MinViews: 10,
+		})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("experiment", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		slot, err := client.CreateSlot(ctx, &gw.Slot{Description: "slot"})
+		require.NoError(t, err)
+		group, err := client.CreateGroup(ctx, &gw.Group{Description: "group"})
+		require.NoError(t, err)
+		arms := make([]*gw.ExperimentArm, 0, 2)
+		for i := 0; i < 2; i++ {
+			banner, err := client.CreateBanner(ctx, &gw.Banner{Description: "banner"})
+			require.NoError(t, err)
+			_, err = client.CreateRotation(ctx, &gw.Rotation{SlotId: slot.Id, BannerId: banner.Id})
+			require.NoError(t, err)
+			arms = append(arms, &gw.ExperimentArm{BannerId: banner.Id, Share: 50})
+		}
+
+		experiment, err := client.StartExperiment(ctx, &gw.Experiment{SlotId: slot.Id, Arms: arms})
+		require.NoError(t, err)
+		require.NotZero(t, experiment.Id)
+		require.Len(t, experiment.Arms, 2)
+
+		_, err = client.StartExperiment(ctx, &gw.Experiment{SlotId: slot.Id, Arms: arms})
+		require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+		request := &gw.SlotRequest{SlotId: slot.Id, GroupId: group.Id, VisitorId: "visitor"}
+		banner, err := client.BannerForSlot(ctx, request)
+		require.NoError(t, err)
+		for i := 0; i < 5; i++ {
+			again, err := client.BannerForSlot(ctx, request)
+			require.NoError(t, err)
+			require.Equal(t, banner.Id, again.Id)
+		}
+
+		explanation, err := client.ExplainBannerForSlot(ctx, request)
+		require.NoError(t, err)
+		require.Equal(t, "experiment", explanation.Rule)
+		require.Equal(t, experiment.Id, explanation.ExperimentId)
+		require.Equal(t, banner.Id, explanation.Chosen.Id)
+
+		results, err := client.GetExperimentResults(ctx, &gw.ExperimentRequest{ExperimentId: experiment.Id})
+		require.NoError(t, err)
+		require.Equal(t, 0.95, results.Confidence)
+		require.Len(t, results.Arms, 2)
+		require.Equal(t, int64(6), results.Arms[0].Views+results.Arms[1].Views)
+		require.Len(t, results.Comparisons, 1)
+
+		ended, err := client.EndExperiment(ctx, &gw.ExperimentRequest{ExperimentId: experiment.Id})
+		require.NoError(t, err)
+		require.NotZero(t, ended.EndedAt)
+
+		_, err = client.EndExperiment(ctx, &gw.ExperimentRequest{ExperimentId: experiment.Id})
+		require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+		_, err = client.GetExperimentResults(ctx, &gw.ExperimentRequest{ExperimentId: experiment.Id + 100})
+		require.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("group hierarchy", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		women, err := client.CreateGroup(ctx, &gw.Group{Description: "women"})
+		require.NoError(t, err)
+		young, err := client.CreateGroup(ctx, &gw.Group{Description: "women 18-24", ParentId: women.Id})
+		require.NoError(t, err)
+		require.Equal(t, women.Id, young.ParentId)
+		_, err = client.CreateGroup(ctx, &gw.Group{Description: "orphans", ParentId: 1000})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+		adults, err := client.CreateGroup(ctx, &gw.Group{Description: "adults"})
+		require.NoError(t, err)
+
+		group, err := client.SetGroupParent(ctx, &gw.GroupParent{GroupId: women.Id, ParentId: adults.Id})
+		require.NoError(t, err)
+		require.Equal(t, women.Id, group.Id)
+		require.Equal(t, "women", group.Description)
+		require.Equal(t, adults.Id, group.ParentId)
+
+		_, err = client.SetGroupParent(ctx, &gw.GroupParent{GroupId: adults.Id, ParentId: young.Id})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		groups, err := client.ListGroups(ctx, &gw.ListGroupsRequest{})
+		require.NoError(t, err)
+		parents := make(map[int64]int64)
+		for _, g := range groups.Groups {
+			parents[g.Id] = g.ParentId
+		}
+		require.Equal(t, adults.Id, parents[women.Id])
+		require.Equal(t, women.Id, parents[young.Id])
+		require.Zero(t, parents[adults.Id])
+	})
+
+	t.Run("bad request", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		_, err := client.CreateSlot(ctx, &gw.Slot{})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = client.BannerForSlot(ctx, &gw.SlotRequest{SlotId: 1, GroupId: -1})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = client.GetSlotStats(ctx, &gw.SlotStatsRequest{SlotId: 1, From: 10, To: 5})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = client.GetCTRReport(ctx, &gw.CTRReportRequest{SlotId: 1, From: 0, To: 3600, Bucket: "month"})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = client.CreateConversionEvent(ctx, &gw.ConversionEvent{SlotId: 1, BannerId: 1, GroupId: 1, Type: "signup"})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = client.CreateConversionEvent(ctx, &gw.ConversionEvent{
+			SlotId: 1, BannerId: 1, GroupId: 1, Type: "purchase", Value: -1,
+		})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = client.SetSlotRewardModel(ctx, &gw.SlotRewardModel{SlotId: 1, RewardModel: "likes"})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = client.CreateRotation(ctx, &gw.Rotation{SlotId: 1, BannerId: 1, PriorViews: 1, PriorRewards: 2})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = client.SetSlotPruningPolicy(ctx, &gw.PruningPolicy{SlotId: 1, Enabled: true, Confidence: 1.5})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = client.StartExperiment(ctx, &gw.Experiment{
+			SlotId: 1, Arms: []*gw.ExperimentArm{{BannerId: 1, Share: 100}},
+		})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = client.EndExperiment(ctx, &gw.ExperimentRequest{})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = client.CreateGroup(ctx, &gw.Group{Description: "group", ParentId: 1000})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = client.SetGroupParent(ctx, &gw.GroupParent{GroupId: 1, ParentId: 1000})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
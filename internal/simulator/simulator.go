@@ -0,0 +1,271 @@
+// Package simulator runs a rotator.Bandit against synthetic banners with
+// known click probabilities and measures how well it performs.
+package simulator
+
+import (
+	"banners-rotator/internal/publisher"
+	"banners-rotator/internal/rotator"
+	memorystorage "banners-rotator/internal/storage/memory"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+)
+
+const (
+	defaultWindow    = 500
+	defaultThreshold = 0.9
+)
+
+var ErrInvalidScenario = errors.New("invalid scenario")
+
+// Scenario describes the simulated traffic. Each step a user of a group,
+// drawn by the group weights, is shown a banner of the slot and clicks it
+// with the true CTR of the banner for the group.
+type Scenario struct {
+	Steps   int      `json:"steps"`
+	Seed    int64    `json:"seed"`
+	Groups  []Group  `json:"groups"`
+	Banners []Banner `json:"banners"`
+	// Window and Threshold define the convergence: the share of optimal
+	// choices over the last Window steps stays at least Threshold.
+	Window    int     `json:"window"`
+	Threshold float64 `json:"threshold"`
+}
+
+type Group struct {
+	Name   string  `json:"name"`
+	Weight float64 `json:"weight"`
+}
+
+// Banner holds the true CTR per group, in the order of the scenario groups.
+// Drifts replace the CTRs starting at their steps.
+type Banner struct {
+	Name   string    `json:"name"`
+	CTR    []float64 `json:"ctr"`
+	Drifts []Drift   `json:"drifts"`
+}
+
+type Drift struct {
+	Step int       `json:"step"`
+	CTR  []float64 `json:"ctr"`
+}
+
+type Result struct {
+	Steps  int     `json:"steps"`
+	Clicks int64   `json:"clicks"`
+	Regret float64 `json:"regret"`
+	// Failures counts the steps the bandit failed to choose a banner at,
+	// nothing is shown and the full best CTR is added to the regret.
+	Failures int `json:"failures"`
+	// ConvergenceStep is the step since which the choices stay optimal,
+	// -1 when the bandit has not converged.
+	ConvergenceStep int            `json:"convergenceStep"`
+	Banners         []BannerResult `json:"banners"`
+}
+
+type BannerResult struct {
+	Name        string  `json:"name"`
+	Impressions int64   `json:"impressions"`
+	Clicks      int64   `json:"clicks"`
+	Share       float64 `json:"share"`
+	CTR         float64 `json:"ctr"`
+}
+
+func (sc *Scenario) validate() error {
+	if sc.Steps <= 0 {
+		return fmt.Errorf("%w: steps must be positive", ErrInvalidScenario)
+	}
+	if len(sc.Groups) == 0 || len(sc.Banners) == 0 {
+		return fmt.Errorf("%w: groups and banners are required", ErrInvalidScenario)
+	}
+	for _, g := range sc.Groups {
+		if g.Weight < 0 {
+			return fmt.Errorf("%w: negative weight of group %s", ErrInvalidScenario, g.Name)
+		}
+	}
+
+	valid := func(ctr []float64) bool {
+		if len(ctr) != len(sc.Groups) {
+			return false
+		}
+		for _, p := range ctr {
+			if p < 0 || p > 1 {
+				return false
+			}
+		}
+
+		return true
+	}
+	for _, b := range sc.Banners {
+		if !valid(b.CTR) {
+			return fmt.Errorf("%w: banner %s needs a CTR in [0, 1] per group", ErrInvalidScenario, b.Name)
+		}
+		for _, d := range b.Drifts {
+			if !valid(d.CTR) {
+				return fmt.Errorf("%w: drift of banner %s needs a CTR in [0, 1] per group", ErrInvalidScenario, b.Name)
+			}
+		}
+	}
+
+	if sc.Window <= 0 {
+		sc.Window = defaultWindow
+	}
+	if sc.Threshold <= 0 {
+		sc.Threshold = defaultThreshold
+	}
+
+	return nil
+}
+
+// ctr is the true CTR of the banner for the group at the step.
+func (b Banner) ctr(group, step int) float64 {
+	ctr := b.CTR
+	for _, d := range b.Drifts {
+		if step >= d.Step {
+			ctr = d.CTR
+		}
+	}
+
+	return ctr[group]
+}
+
+// Run simulates the scenario with a rotator using the bandit and in-memory
+// storage, so the bandit is driven the same way as in the service.
+func Run(ctx context.Context, b rotator.Bandit, sc Scenario) (*Result, error) {
+	if err := sc.validate(); err != nil {
+		return nil, err
+	}
+
+	s := memorystorage.NewStorage()
+	app := rotator.NewApp(s, publisher.NewNopPublisher(), b)
+	rnd := rand.New(rand.NewSource(sc.Seed))
+
+	slot, err := app.CreateSlot("simulation")
+	if err != nil {
+		return nil, fmt.Errorf("simulate -> %w", err)
+	}
+
+	groupIDs := make([]int64, len(sc.Groups))
+	for i, g := range sc.Groups {
+		group, err := app.CreateGroup(g.Name)
+		if err != nil {
+			return nil, fmt.Errorf("simulate -> %w", err)
+		}
+		groupIDs[i] = group.ID
+	}
+
+	bannerIndex := make(map[int64]int, len(sc.Banners))
+	for i, b := range sc.Banners {
+		banner, err := app.CreateBanner(b.Name)
+		if err != nil {
+			return nil, fmt.Errorf("simulate -> %w", err)
+		}
+		if err = app.CreateRotation(slot.ID, banner.ID, rotator.Prior{}); err != nil {
+			return nil, fmt.Errorf("simulate -> %w", err)
+		}
+		bannerIndex[banner.ID] = i
+	}
+
+	result := &Result{Steps: sc.Steps, Banners: make([]BannerResult, len(sc.Banners))}
+	for i, b := range sc.Banners {
+		result.Banners[i].Name = b.Name
+	}
+	optimal := make([]bool, sc.Steps)
+
+	for step := 0; step < sc.Steps; step++ {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("simulate -> %w", err)
+		}
+
+		group := pickGroup(rnd, sc.Groups)
+		best := 0.0
+		for _, b := range sc.Banners {
+			if ctr := b.ctr(group, step); ctr > best {
+				best = ctr
+			}
+		}
+
+		banner, err := app.BannerForSlot(slot.ID, groupIDs[group])
+		if err != nil {
+			result.Failures++
+			result.Regret += best
+			continue
+		}
+
+		chosen := bannerIndex[banner.ID]
+		ctr := sc.Banners[chosen].ctr(group, step)
+		result.Regret += best - ctr
+		optimal[step] = ctr == best
+
+		result.Banners[chosen].Impressions++
+		if rnd.Float64() < ctr {
+			if err = app.CreateClickEvent(slot.ID, banner.ID, groupIDs[group], banner.ImpressionID); err != nil {
+				return nil, fmt.Errorf("simulate -> step %d -> %w", step, err)
+			}
+			result.Banners[chosen].Clicks++
+			result.Clicks++
+		}
+	}
+
+	for i := range result.Banners {
+		br := &result.Banners[i]
+		br.Share = float64(br.Impressions) / float64(sc.Steps)
+		if br.Impressions > 0 {
+			br.CTR = float64(br.Clicks) / float64(br.Impressions)
+		}
+	}
+	result.ConvergenceStep = convergenceStep(optimal, sc.Window, sc.Threshold)
+
+	return result, nil
+}
+
+func pickGroup(rnd *rand.Rand, groups []Group) int {
+	var total float64
+	for _, g := range groups {
+		total += g.Weight
+	}
+	if total == 0 {
+		return rnd.Intn(len(groups))
+	}
+
+	x := rnd.Float64() * total
+	for i, g := range groups {
+		x -= g.Weight
+		if x < 0 {
+			return i
+		}
+	}
+
+	return len(groups) - 1
+}
+
+// convergenceStep finds the step since which the share of optimal choices
+// over the trailing window stays at least the threshold.
+func convergenceStep(optimal []bool, window int, threshold float64) int {
+	if window > len(optimal) {
+		window = len(optimal)
+	}
+
+	converged := -1
+	count := 0
+	for i, ok := range optimal {
+		if ok {
+			count++
+		}
+		if i >= window && optimal[i-window] {
+			count--
+		}
+		if i < window-1 {
+			continue
+		}
+
+		if float64(count)/float64(window) < threshold {
+			converged = -1
+		} else if converged == -1 {
+			converged = i - window + 1
+		}
+	}
+
+	return converged
+}
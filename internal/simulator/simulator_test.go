@@ -0,0 +1,73 @@
+package simulator
+
+import (
+	"banners-rotator/internal/bandit"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	sc := Scenario{
+		Steps:  2000,
+		Seed:   1,
+		Groups: []Group{{Name: "all", Weight: 1}},
+		Banners: []Banner{
+			{Name: "good", CTR: []float64{0.5}},
+			{Name: "bad", CTR: []float64{0.05}},
+		},
+		Window: 200,
+	}
+
+	t.Run("finds the best banner", func(t *testing.T) {
+		result, err := Run(context.Background(), bandit.NewBandit(bandit.WithSeed(1)), sc)
+		require.NoError(t, err)
+		require.Equal(t, sc.Steps, result.Steps)
+		require.Greater(t, result.Banners[0].Share, 0.8)
+		require.Zero(t, result.Failures)
+		require.InDelta(t, 1, result.Banners[0].Share+result.Banners[1].Share, 1e-9)
+		require.InDelta(t, 0.45*float64(result.Banners[1].Impressions), result.Regret, 1e-6)
+		require.GreaterOrEqual(t, result.ConvergenceStep, 0)
+	})
+
+	t.Run("is reproducible", func(t *testing.T) {
+		first, err := Run(context.Background(), bandit.NewBandit(bandit.WithSeed(7)), sc)
+		require.NoError(t, err)
+		second, err := Run(context.Background(), bandit.NewBandit(bandit.WithSeed(7)), sc)
+		require.NoError(t, err)
+		require.Equal(t, first, second)
+	})
+
+	t.Run("drifting ctr", func(t *testing.T) {
+		drifting := sc
+		drifting.Banners = []Banner{
+			{Name: "good", CTR: []float64{0.5}, Drifts: []Drift{{Step: 1000, CTR: []float64{0}}}},
+			{Name: "bad", CTR: []float64{0.05}},
+		}
+
+		result, err := Run(context.Background(), bandit.NewBandit(bandit.WithSeed(1)), drifting)
+		require.NoError(t, err)
+		require.Greater(t, result.Regret, 0.0)
+	})
+
+	t.Run("invalid scenario", func(t *testing.T) {
+		invalid := sc
+		invalid.Banners = []Banner{{Name: "no ctr"}}
+
+		_, err := Run(context.Background(), bandit.NewBandit(), invalid)
+		require.ErrorIs(t, err, ErrInvalidScenario)
+	})
+}
+
+func TestConvergenceStep(t *testing.T) {
+	t.Run("converged", func(t *testing.T) {
+		optimal := []bool{false, true, false, true, true, true, true}
+		require.Equal(t, 3, convergenceStep(optimal, 2, 1))
+	})
+
+	t.Run("not converged", func(t *testing.T) {
+		optimal := []bool{true, true, true, false}
+		require.Equal(t, -1, convergenceStep(optimal, 2, 1))
+	})
+}
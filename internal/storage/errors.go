@@ -7,7 +7,23 @@ var (
 	ErrBannerNotCreated     = errors.New("banner not created")
 	ErrGroupNotCreated      = errors.New("group not created")
 	ErrRotationNotCreated   = errors.New("rotation not created")
+	ErrRotationExists       = errors.New("rotation already exists")
 	ErrRotationNotDeleted   = errors.New("rotation not deleted")
 	ErrViewEventNotCreated  = errors.New("view event not created")
 	ErrClickEventNotCreated = errors.New("click event not created")
+	ErrStatsNotSaved        = errors.New("stats not saved")
+	ErrMessageProcessed     = errors.New("message already processed")
+	ErrModelNotSaved        = errors.New("model not saved")
+	ErrModelConflict        = errors.New("model changed concurrently")
+	ErrConversionNotCreated = errors.New("conversion event not created")
+	ErrRewardModelNotSet    = errors.New("reward model not set")
+	ErrPriorNotSet          = errors.New("rotation prior not set")
+	ErrPolicyNotSet         = errors.New("pruning policy not set")
+	ErrRotationNotPaused    = errors.New("rotation not paused")
+	ErrRotationNotResumed   = errors.New("rotation not resumed")
+	ErrExperimentNotCreated = errors.New("experiment not created")
+	ErrExperimentNotFound   = errors.New("experiment not found")
+	ErrExperimentNotEnded   = errors.New("experiment not ended")
+	ErrGroupNotFound        = errors.New("group not found")
+	ErrGroupParentNotSet    = errors.New("group parent not set")
 )
@@ -0,0 +1,698 @@
+package memorystorage
+
+import (
+	"banners-rotator/internal/storage"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var (
+	errSlotNotFound   = errors.New("slot not found")
+	errBannerNotFound = errors.New("banner not found")
+	errGroupNotFound  = errors.New("group not found")
+)
+
+// rotation is a banner of a slot, pausedAt is the date the rotation is paused
+// at, zero for an active rotation.
+type rotation struct {
+	prior    storage.Prior
+	pausedAt int64
+}
+
+// Storage keeps all data in memory. It follows the constraints of the SQL
+// schema, e.g. events and rotations may only refer to existing entities.
+type Storage struct {
+	mu        sync.RWMutex
+	slots     map[int64]storage.Slot
+	banners   map[int64]storage.Banner
+	groups    map[int64]storage.Group
+	rotations map[int64]map[int64]rotation
+	views     map[int64][]storage.ViewEvent
+	clicks    map[int64][]storage.ClickEvent
+	convs     map[int64][]storage.ConversionEvent
+	rewards   map[int64]string
+	models    map[int64]storage.SlotModel
+	policies  map[int64]storage.PruningPolicy
+	prunings  map[int64][]storage.Pruning
+	exps      map[int64]storage.Experiment
+	slotID    int64
+	bannerID  int64
+	groupID   int64
+	expID     int64
+}
+
+func NewStorage() *Storage {
+	return &Storage{
+		slots:     make(map[int64]storage.Slot),
+		banners:   make(map[int64]storage.Banner),
+		groups:    make(map[int64]storage.Group),
+		rotations: make(map[int64]map[int64]rotation),
+		views:     make(map[int64][]storage.ViewEvent),
+		clicks:    make(map[int64][]storage.ClickEvent),
+		convs:     make(map[int64][]storage.ConversionEvent),
+		rewards:   make(map[int64]string),
+		models:    make(map[int64]storage.SlotModel),
+		policies:  make(map[int64]storage.PruningPolicy),
+		prunings:  make(map[int64][]storage.Pruning),
+		exps:      make(map[int64]storage.Experiment),
+	}
+}
+
+func (s *Storage) Close() error {
+	return nil
+}
+
+func (s *Storage) CreateSlot(description string) (*storage.Slot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.slotID++
+	slot := storage.Slot{ID: s.slotID, Description: description}
+	s.slots[slot.ID] = slot
+
+	return &slot, nil
+}
+
+func (s *Storage) CreateBanner(description string) (*storage.Banner, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.bannerID++
+	banner := storage.Banner{ID: s.bannerID, Description: description}
+	s.banners[banner.ID] = banner
+
+	return &banner, nil
+}
+
+func (s *Storage) CreateGroup(description string) (*storage.Group, error) {
+	return s.CreateGroupWithParent(description, 0)
+}
+
+func (s *Storage) CreateGroupWithParent(description string, parentID int64) (*storage.Group, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.groups[parentID]; parentID != 0 && !ok {
+		return nil, fmt.Errorf("storage -> create group with parent -> %w (%s)", storage.ErrGroupParentNotSet, errGroupNotFound)
+	}
+
+	s.groupID++
+	group := storage.Group{ID: s.groupID, Description: description, ParentID: parentID}
+	s.groups[group.ID] = group
+
+	return &group, nil
+}
+
+func (s *Storage) Group(id int64) (*storage.Group, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	g, ok := s.groups[id]
+	if !ok {
+		return nil, fmt.Errorf("storage -> group -> %w (%d)", storage.ErrGroupNotFound, id)
+	}
+
+	return &g, nil
+}
+
+func (s *Storage) Groups() (*[]storage.Group, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	g := make([]storage.Group, 0, len(s.groups))
+	for _, group := range s.groups {
+		g = append(g, group)
+	}
+	sort.Slice(g, func(i, j int) bool {
+		return g[i].ID < g[j].ID
+	})
+
+	return &g, nil
+}
+
+func (s *Storage) SetGroupParent(groupID, parentID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g, ok := s.groups[groupID]
+	if !ok {
+		return fmt.Errorf("storage -> set group parent -> %w (%s)", storage.ErrGroupParentNotSet, errGroupNotFound)
+	}
+	if _, ok = s.groups[parentID]; parentID != 0 && !ok {
+		return fmt.Errorf("storage -> set group parent -> %w (%s)", storage.ErrGroupParentNotSet, errGroupNotFound)
+	}
+	g.ParentID = parentID
+	s.groups[groupID] = g
+
+	return nil
+}
+
+func (s *Storage) CreateRotation(slotID, bannerID int64) error {
+	return s.CreateRotationWithPrior(slotID, bannerID, storage.Prior{})
+}
+
+func (s *Storage) CreateRotationWithPrior(slotID, bannerID int64, prior storage.Prior) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.slots[slotID]; !ok {
+		return fmt.Errorf("storage -> create rotation -> %w (%s)", storage.ErrRotationNotCreated, errSlotNotFound)
+	}
+	if _, ok := s.banners[bannerID]; !ok {
+		return fmt.Errorf("storage -> create rotation -> %w (%s)", storage.ErrRotationNotCreated, errBannerNotFound)
+	}
+
+	if _, ok := s.rotations[slotID][bannerID]; ok {
+		return fmt.Errorf("storage -> create rotation -> %w (%d, %d)", storage.ErrRotationExists, slotID, bannerID)
+	}
+
+	if s.rotations[slotID] == nil {
+		s.rotations[slotID] = make(map[int64]rotation)
+	}
+	s.rotations[slotID][bannerID] = rotation{prior: prior}
+
+	return nil
+}
+
+func (s *Storage) SetRotationPrior(slotID, bannerID int64, prior storage.Prior) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.rotations[slotID][bannerID]
+	if !ok {
+		return fmt.Errorf("storage -> set rotation prior -> %w (rotation not found)", storage.ErrPriorNotSet)
+	}
+	r.prior = prior
+	s.rotations[slotID][bannerID] = r
+
+	return nil
+}
+
+func (s *Storage) DeleteRotation(slotID, bannerID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.rotations[slotID][bannerID]; !ok {
+		return fmt.Errorf("storage -> delete rotation -> %w (not found)", storage.ErrRotationNotDeleted)
+	}
+	delete(s.rotations[slotID], bannerID)
+
+	return nil
+}
+
+func (s *Storage) CreateViewEvent(slotID, bannerID, groupID, date int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkEvent(slotID, bannerID, groupID); err != nil {
+		return fmt.Errorf("storage -> create view event -> %w (%s)", storage.ErrViewEventNotCreated, err)
+	}
+
+	s.views[slotID] = append(s.views[slotID], storage.ViewEvent{
+		SlotID:   slotID,
+		BannerID: bannerID,
+		GroupID:  groupID,
+		Date:     date,
+	})
+
+	return nil
+}
+
+func (s *Storage) CreateClickEvent(slotID, bannerID, groupID, date int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkEvent(slotID, bannerID, groupID); err != nil {
+		return fmt.Errorf("storage -> create click event -> %w (%s)", storage.ErrClickEventNotCreated, err)
+	}
+
+	s.clicks[slotID] = append(s.clicks[slotID], storage.ClickEvent{
+		SlotID:   slotID,
+		BannerID: bannerID,
+		GroupID:  groupID,
+		Date:     date,
+	})
+
+	return nil
+}
+
+func (s *Storage) CreateConversionEvent(
+	slotID, bannerID, groupID int64,
+	conversionType string,
+	value float64,
+	date int64,
+) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkEvent(slotID, bannerID, groupID); err != nil {
+		return fmt.Errorf("storage -> create conversion event -> %w (%s)", storage.ErrConversionNotCreated, err)
+	}
+
+	s.convs[slotID] = append(s.convs[slotID], storage.ConversionEvent{
+		SlotID:   slotID,
+		BannerID: bannerID,
+		GroupID:  groupID,
+		Type:     conversionType,
+		Value:    value,
+		Date:     date,
+	})
+
+	return nil
+}
+
+func (s *Storage) SlotBanners(slotID int64) (*[]storage.Banner, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	b := s.slotBanners(slotID)
+
+	return &b, nil
+}
+
+func (s *Storage) SlotPriors(slotID int64) (*[]storage.RotationPrior, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	p := make([]storage.RotationPrior, 0)
+	for bannerID, r := range s.rotations[slotID] {
+		if r.prior.Views > 0 {
+			p = append(p, storage.RotationPrior{BannerID: bannerID, Prior: r.prior})
+		}
+	}
+	sort.Slice(p, func(i, j int) bool {
+		return p[i].BannerID < p[j].BannerID
+	})
+
+	return &p, nil
+}
+
+func (s *Storage) SlotViews(slotID int64) (*[]storage.ViewEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ve := make([]storage.ViewEvent, len(s.views[slotID]))
+	copy(ve, s.views[slotID])
+
+	return &ve, nil
+}
+
+func (s *Storage) SlotClicks(slotID int64) (*[]storage.ClickEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ce := make([]storage.ClickEvent, len(s.clicks[slotID]))
+	copy(ce, s.clicks[slotID])
+
+	return &ce, nil
+}
+
+func (s *Storage) SlotConversions(slotID int64) (*[]storage.ConversionEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ce := make([]storage.ConversionEvent, len(s.convs[slotID]))
+	copy(ce, s.convs[slotID])
+
+	return &ce, nil
+}
+
+func (s *Storage) SlotRewardModel(slotID int64) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.slots[slotID]; !ok {
+		return "", fmt.Errorf("storage -> slot reward model -> %w", errSlotNotFound)
+	}
+	if model, ok := s.rewards[slotID]; ok {
+		return model, nil
+	}
+
+	return storage.RewardClicks, nil
+}
+
+func (s *Storage) SetSlotRewardModel(slotID int64, model string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.slots[slotID]; !ok {
+		return fmt.Errorf("storage -> set slot reward model -> %w (%s)", storage.ErrRewardModelNotSet, errSlotNotFound)
+	}
+	s.rewards[slotID] = model
+
+	return nil
+}
+
+func (s *Storage) BannerStats(bannerID, exceptSlotID int64) (*storage.BannerStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	bs := storage.BannerStats{BannerID: bannerID}
+	for slotID, views := range s.views {
+		if slotID == exceptSlotID {
+			continue
+		}
+		for _, view := range views {
+			if view.BannerID == bannerID {
+				bs.Views++
+			}
+		}
+	}
+	for slotID, clicks := range s.clicks {
+		if slotID == exceptSlotID {
+			continue
+		}
+		for _, click := range clicks {
+			if click.BannerID == bannerID {
+				bs.Clicks++
+			}
+		}
+	}
+
+	return &bs, nil
+}
+
+func (s *Storage) SlotStats(slotID, from, to int64, byGroup bool) (*[]storage.BannerStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	type key struct {
+		bannerID int64
+		groupID  int64
+	}
+	counters := make(map[key]*storage.BannerStats)
+	counter := func(bannerID, groupID int64) *storage.BannerStats {
+		if !byGroup {
+			groupID = 0
+		}
+		k := key{bannerID, groupID}
+		if _, ok := counters[k]; !ok {
+			counters[k] = &storage.BannerStats{BannerID: bannerID, GroupID: groupID}
+		}
+
+		return counters[k]
+	}
+
+	for _, view := range s.views[slotID] {
+		if view.Date >= from && view.Date < to {
+			counter(view.BannerID, view.GroupID).Views++
+		}
+	}
+	for _, click := range s.clicks[slotID] {
+		if click.Date >= from && click.Date < to {
+			counter(click.BannerID, click.GroupID).Clicks++
+		}
+	}
+
+	bs := make([]storage.BannerStats, 0, len(counters))
+	for _, c := range counters {
+		bs = append(bs, *c)
+	}
+	sort.Slice(bs, func(i, j int) bool {
+		if bs[i].BannerID != bs[j].BannerID {
+			return bs[i].BannerID < bs[j].BannerID
+		}
+
+		return bs[i].GroupID < bs[j].GroupID
+	})
+
+	return &bs, nil
+}
+
+func (s *Storage) SlotSeries(filter storage.SeriesFilter) (*[]storage.SeriesPoint, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	match := func(bannerID, groupID, date int64) bool {
+		return date >= filter.From && date < filter.To &&
+			(filter.BannerID == 0 || bannerID == filter.BannerID) &&
+			(filter.GroupID == 0 || groupID == filter.GroupID)
+	}
+	bucket := func(date int64) int64 {
+		return (date-filter.BucketOffset)/filter.BucketSize*filter.BucketSize + filter.BucketOffset
+	}
+
+	points := make(map[int64]*storage.SeriesPoint)
+	point := func(date int64) *storage.SeriesPoint {
+		b := bucket(date)
+		if _, ok := points[b]; !ok {
+			points[b] = &storage.SeriesPoint{Bucket: b}
+		}
+
+		return points[b]
+	}
+
+	for _, view := range s.views[filter.SlotID] {
+		if match(view.BannerID, view.GroupID, view.Date) {
+			point(view.Date).Views++
+		}
+	}
+	for _, click := range s.clicks[filter.SlotID] {
+		if match(click.BannerID, click.GroupID, click.Date) {
+			point(click.Date).Clicks++
+		}
+	}
+
+	sp := make([]storage.SeriesPoint, 0, len(points))
+	for _, p := range points {
+		sp = append(sp, *p)
+	}
+	sort.Slice(sp, func(i, j int) bool {
+		return sp[i].Bucket < sp[j].Bucket
+	})
+
+	return &sp, nil
+}
+
+func (s *Storage) SlotModel(slotID int64) (*storage.SlotModel, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	m, ok := s.models[slotID]
+	if !ok {
+		return &storage.SlotModel{SlotID: slotID}, nil
+	}
+	m.State = append([]byte(nil), m.State...)
+
+	return &m, nil
+}
+
+func (s *Storage) SaveSlotModel(model storage.SlotModel) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.slots[model.SlotID]; !ok {
+		return fmt.Errorf("storage -> save slot model -> %w (%s)", storage.ErrModelNotSaved, errSlotNotFound)
+	}
+	if s.models[model.SlotID].Version != model.Version {
+		return fmt.Errorf("storage -> save slot model -> %w (version %d)", storage.ErrModelConflict, model.Version)
+	}
+
+	s.models[model.SlotID] = storage.SlotModel{
+		SlotID:  model.SlotID,
+		Version: model.Version + 1,
+		State:   append([]byte(nil), model.State...),
+	}
+
+	return nil
+}
+
+func (s *Storage) SlotPruningPolicy(slotID int64) (*storage.PruningPolicy, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	p, ok := s.policies[slotID]
+	if !ok {
+		p = storage.PruningPolicy{SlotID: slotID}
+	}
+
+	return &p, nil
+}
+
+func (s *Storage) SetSlotPruningPolicy(policy storage.PruningPolicy) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.slots[policy.SlotID]; !ok {
+		return fmt.Errorf("storage -> set slot pruning policy -> %w (%s)", storage.ErrPolicyNotSet, errSlotNotFound)
+	}
+	s.policies[policy.SlotID] = policy
+
+	return nil
+}
+
+func (s *Storage) PruningPolicies() (*[]storage.PruningPolicy, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	p := make([]storage.PruningPolicy, 0, len(s.policies))
+	for _, policy := range s.policies {
+		if policy.Enabled {
+			p = append(p, policy)
+		}
+	}
+	sort.Slice(p, func(i, j int) bool {
+		return p[i].SlotID < p[j].SlotID
+	})
+
+	return &p, nil
+}
+
+func (s *Storage) PauseRotation(pruning storage.Pruning) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.rotations[pruning.SlotID][pruning.BannerID]
+	if !ok || r.pausedAt != 0 {
+		return fmt.Errorf("storage -> pause rotation -> %w (not found or paused)", storage.ErrRotationNotPaused)
+	}
+	r.pausedAt = pruning.Date
+	s.rotations[pruning.SlotID][pruning.BannerID] = r
+	s.prunings[pruning.SlotID] = append(s.prunings[pruning.SlotID], pruning)
+
+	return nil
+}
+
+func (s *Storage) ResumeRotation(slotID, bannerID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.rotations[slotID][bannerID]
+	if !ok || r.pausedAt == 0 {
+		return fmt.Errorf("storage -> resume rotation -> %w (not found or not paused)", storage.ErrRotationNotResumed)
+	}
+	r.pausedAt = 0
+	s.rotations[slotID][bannerID] = r
+
+	return nil
+}
+
+func (s *Storage) SlotPrunings(slotID int64) (*[]storage.Pruning, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	p := make([]storage.Pruning, len(s.prunings[slotID]))
+	copy(p, s.prunings[slotID])
+	sort.SliceStable(p, func(i, j int) bool {
+		if p[i].Date != p[j].Date {
+			return p[i].Date < p[j].Date
+		}
+
+		return p[i].BannerID < p[j].BannerID
+	})
+
+	return &p, nil
+}
+
+func (s *Storage) CreateExperiment(experiment storage.Experiment) (*storage.Experiment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.slots[experiment.SlotID]; !ok {
+		return nil, fmt.Errorf("storage -> create experiment -> %w (%s)", storage.ErrExperimentNotCreated, errSlotNotFound)
+	}
+	if _, ok := s.activeExperiment(experiment.SlotID); ok {
+		return nil, fmt.Errorf("storage -> create experiment -> %w (active experiment exists)", storage.ErrExperimentNotCreated)
+	}
+	for _, arm := range experiment.Arms {
+		if _, ok := s.banners[arm.BannerID]; !ok {
+			return nil, fmt.Errorf("storage -> create experiment -> %w (%s)", storage.ErrExperimentNotCreated, errBannerNotFound)
+		}
+	}
+
+	s.expID++
+	experiment.ID = s.expID
+	experiment.EndedAt = 0
+	experiment.Arms = append([]storage.ExperimentArm(nil), experiment.Arms...)
+	sort.Slice(experiment.Arms, func(i, j int) bool {
+		return experiment.Arms[i].BannerID < experiment.Arms[j].BannerID
+	})
+	s.exps[experiment.ID] = experiment
+
+	return copyExperiment(experiment), nil
+}
+
+func (s *Storage) ActiveExperiment(slotID int64) (*storage.Experiment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	e, ok := s.activeExperiment(slotID)
+	if !ok {
+		return nil, fmt.Errorf("storage -> active experiment -> %w (slot %d)", storage.ErrExperimentNotFound, slotID)
+	}
+
+	return copyExperiment(e), nil
+}
+
+func (s *Storage) Experiment(id int64) (*storage.Experiment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	e, ok := s.exps[id]
+	if !ok {
+		return nil, fmt.Errorf("storage -> experiment -> %w (%d)", storage.ErrExperimentNotFound, id)
+	}
+
+	return copyExperiment(e), nil
+}
+
+func (s *Storage) EndExperiment(id, date int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.exps[id]
+	if !ok || e.EndedAt != 0 {
+		return fmt.Errorf("storage -> end experiment -> %w (not found or ended)", storage.ErrExperimentNotEnded)
+	}
+	e.EndedAt = date
+	s.exps[id] = e
+
+	return nil
+}
+
+func (s *Storage) activeExperiment(slotID int64) (storage.Experiment, bool) {
+	for _, e := range s.exps {
+		if e.SlotID == slotID && e.EndedAt == 0 {
+			return e, true
+		}
+	}
+
+	return storage.Experiment{}, false
+}
+
+func copyExperiment(e storage.Experiment) *storage.Experiment {
+	e.Arms = append([]storage.ExperimentArm(nil), e.Arms...)
+
+	return &e
+}
+
+func (s *Storage) slotBanners(slotID int64) []storage.Banner {
+	b := make([]storage.Banner, 0, len(s.rotations[slotID]))
+	for bannerID, r := range s.rotations[slotID] {
+		if r.pausedAt == 0 {
+			b = append(b, s.banners[bannerID])
+		}
+	}
+
+	sort.Slice(b, func(i, j int) bool {
+		return b[i].ID < b[j].ID
+	})
+
+	return b
+}
+
+func (s *Storage) checkEvent(slotID, bannerID, groupID int64) error {
+	if _, ok := s.slots[slotID]; !ok {
+		return errSlotNotFound
+	}
+	if _, ok := s.banners[bannerID]; !ok {
+		return errBannerNotFound
+	}
+	if _, ok := s.groups[groupID]; !ok {
+		return errGroupNotFound
+	}
+
+	return nil
+}
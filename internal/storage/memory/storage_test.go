@@ -0,0 +1,114 @@
+package memorystorage
+
+import (
+	"banners-rotator/internal/rotator"
+	"banners-rotator/internal/storage"
+	"banners-rotator/internal/storage/storagetest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorage_Conformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) rotator.Storage {
+		return NewStorage()
+	})
+}
+
+func TestStorage_Create(t *testing.T) {
+	s := NewStorage()
+
+	t.Run("create entities", func(t *testing.T) {
+		slot, err := s.CreateSlot("slot")
+		require.NoError(t, err)
+		require.Equal(t, int64(1), slot.ID)
+		require.Equal(t, "slot", slot.Description)
+
+		banner, err := s.CreateBanner("banner")
+		require.NoError(t, err)
+		require.Equal(t, int64(1), banner.ID)
+
+		group, err := s.CreateGroup("group")
+		require.NoError(t, err)
+		require.Equal(t, int64(1), group.ID)
+	})
+}
+
+func TestStorage_Rotation(t *testing.T) {
+	s := NewStorage()
+	slot, _ := s.CreateSlot("slot")
+	banner, _ := s.CreateBanner("banner")
+
+	t.Run("create and delete rotation", func(t *testing.T) {
+		require.NoError(t, s.CreateRotation(slot.ID, banner.ID))
+
+		banners, err := s.SlotBanners(slot.ID)
+		require.NoError(t, err)
+		require.Equal(t, []storage.Banner{*banner}, *banners)
+
+		require.NoError(t, s.DeleteRotation(slot.ID, banner.ID))
+		banners, err = s.SlotBanners(slot.ID)
+		require.NoError(t, err)
+		require.Empty(t, *banners)
+	})
+
+	t.Run("rotation errors", func(t *testing.T) {
+		err := s.CreateRotation(100, banner.ID)
+		require.ErrorIs(t, err, storage.ErrRotationNotCreated)
+
+		err = s.CreateRotation(slot.ID, 100)
+		require.ErrorIs(t, err, storage.ErrRotationNotCreated)
+
+		err = s.DeleteRotation(slot.ID, banner.ID)
+		require.ErrorIs(t, err, storage.ErrRotationNotDeleted)
+	})
+}
+
+func TestStorage_Events(t *testing.T) {
+	s := NewStorage()
+	slot, _ := s.CreateSlot("slot")
+	first, _ := s.CreateBanner("first")
+	second, _ := s.CreateBanner("second")
+	group, _ := s.CreateGroup("group")
+	require.NoError(t, s.CreateRotation(slot.ID, first.ID))
+	require.NoError(t, s.CreateRotation(slot.ID, second.ID))
+
+	t.Run("views and clicks", func(t *testing.T) {
+		require.NoError(t, s.CreateViewEvent(slot.ID, first.ID, group.ID, 1))
+		require.NoError(t, s.CreateClickEvent(slot.ID, first.ID, group.ID, 2))
+
+		views, err := s.SlotViews(slot.ID)
+		require.NoError(t, err)
+		require.Equal(t, []storage.ViewEvent{{SlotID: slot.ID, BannerID: first.ID, GroupID: group.ID, Date: 1}}, *views)
+
+		clicks, err := s.SlotClicks(slot.ID)
+		require.NoError(t, err)
+		require.Len(t, *clicks, 1)
+	})
+
+	t.Run("event errors", func(t *testing.T) {
+		err := s.CreateViewEvent(slot.ID, first.ID, 100, 1)
+		require.ErrorIs(t, err, storage.ErrViewEventNotCreated)
+
+		err = s.CreateClickEvent(100, first.ID, group.ID, 1)
+		require.ErrorIs(t, err, storage.ErrClickEventNotCreated)
+	})
+
+	t.Run("concurrent events", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 100; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_ = s.CreateViewEvent(slot.ID, second.ID, group.ID, 1)
+				_, _ = s.SlotViews(slot.ID)
+			}()
+		}
+		wg.Wait()
+
+		views, err := s.SlotViews(slot.ID)
+		require.NoError(t, err)
+		require.Len(t, *views, 101)
+	})
+}
@@ -8,11 +8,17 @@ type Slot struct {
 type Banner struct {
 	ID          int64  `db:"id" json:"id"`
 	Description string `db:"description" json:"description"`
+	// ImpressionID identifies the view of the banner selected for a slot,
+	// the clicks and conversions of the view refer to it. It is not stored.
+	ImpressionID string `db:"-" json:"impression_id,omitempty"`
 }
 
+// Group is a group of users, ParentID is the broader group it belongs to,
+// zero for a top-level group.
 type Group struct {
 	ID          int64  `db:"id" json:"id"`
 	Description string `db:"description" json:"description"`
+	ParentID    int64  `db:"parent_id" json:"parent_id"`
 }
 
 type ViewEvent struct {
@@ -28,3 +34,143 @@ type ClickEvent struct {
 	GroupID  int64 `db:"group_id" json:"group_id"`
 	Date     int64 `db:"date" json:"date"`
 }
+
+type HourlyStats struct {
+	SlotID   int64 `db:"slot_id" json:"slot_id"`
+	BannerID int64 `db:"banner_id" json:"banner_id"`
+	GroupID  int64 `db:"group_id" json:"group_id"`
+	Hour     int64 `db:"hour" json:"hour"`
+	Views    int64 `db:"views" json:"views"`
+	Clicks   int64 `db:"clicks" json:"clicks"`
+}
+
+// BannerStats holds the event counters of a banner in a slot. GroupID is zero
+// unless the counters are split by groups.
+type BannerStats struct {
+	BannerID int64 `db:"banner_id" json:"banner_id"`
+	GroupID  int64 `db:"group_id" json:"group_id"`
+	Views    int64 `db:"views" json:"views"`
+	Clicks   int64 `db:"clicks" json:"clicks"`
+}
+
+// SeriesFilter selects the events of the slot with From <= date < To, zero
+// BannerID and GroupID select all banners and groups. The events are counted
+// in buckets of BucketSize seconds starting at BucketOffset.
+type SeriesFilter struct {
+	SlotID       int64
+	BannerID     int64
+	GroupID      int64
+	From         int64
+	To           int64
+	BucketSize   int64
+	BucketOffset int64
+}
+
+// SeriesPoint holds the event counters of the bucket starting at Bucket.
+type SeriesPoint struct {
+	Bucket int64 `db:"bucket" json:"bucket"`
+	Views  int64 `db:"views" json:"views"`
+	Clicks int64 `db:"clicks" json:"clicks"`
+}
+
+const (
+	EventView  = "view"
+	EventClick = "click"
+)
+
+// Event is a view or click event of the event log.
+type Event struct {
+	Type     string `db:"type" json:"type"`
+	SlotID   int64  `db:"slot_id" json:"slot_id"`
+	BannerID int64  `db:"banner_id" json:"banner_id"`
+	GroupID  int64  `db:"group_id" json:"group_id"`
+	Date     int64  `db:"date" json:"date"`
+}
+
+// SlotModel is the state of the contextual bandit of a slot. Version grows
+// with every save, zero is the version of a model never saved.
+type SlotModel struct {
+	SlotID  int64  `db:"slot_id" json:"slot_id"`
+	Version int64  `db:"version" json:"version"`
+	State   []byte `db:"state" json:"state"`
+}
+
+const (
+	ConversionTypeConversion = "conversion"
+	ConversionTypePurchase   = "purchase"
+)
+
+// ConversionEvent is a conversion after a click on the banner, Value is the
+// revenue of a purchase.
+type ConversionEvent struct {
+	SlotID   int64   `db:"slot_id" json:"slot_id"`
+	BannerID int64   `db:"banner_id" json:"banner_id"`
+	GroupID  int64   `db:"group_id" json:"group_id"`
+	Type     string  `db:"type" json:"type"`
+	Value    float64 `db:"value" json:"value"`
+	Date     int64   `db:"date" json:"date"`
+}
+
+// The reward models of a slot: the bandit optimises the clicks, the
+// conversions or the revenue of the purchases.
+const (
+	RewardClicks      = "clicks"
+	RewardConversions = "conversions"
+	RewardRevenue     = "revenue"
+)
+
+// Prior is the warm start of a banner in a slot: the bandit scores the banner
+// as if it already had Views views with Rewards rewards.
+type Prior struct {
+	Views   int64   `db:"prior_views" json:"prior_views"`
+	Rewards float64 `db:"prior_rewards" json:"prior_rewards"`
+}
+
+// RotationPrior is the prior of a banner of the slot.
+type RotationPrior struct {
+	BannerID int64 `db:"banner_id" json:"banner_id"`
+	Prior
+}
+
+// PruningPolicy makes the rotation of a banner of the slot to be paused once
+// the banner is worse than the leader with the Confidence. Only the banners
+// with at least MinViews views are compared.
+type PruningPolicy struct {
+	SlotID     int64   `db:"slot_id" json:"slot_id"`
+	Enabled    bool    `db:"enabled" json:"enabled"`
+	Confidence float64 `db:"confidence" json:"confidence"`
+	MinViews   int64   `db:"min_views" json:"min_views"`
+}
+
+// Pruning is a paused rotation with the views and rewards of the banner and
+// of the leader of the slot at the Date, Probability is the probability
+// the leader is better.
+type Pruning struct {
+	SlotID        int64   `db:"slot_id" json:"slot_id"`
+	BannerID      int64   `db:"banner_id" json:"banner_id"`
+	LeaderID      int64   `db:"leader_id" json:"leader_id"`
+	Views         int64   `db:"views" json:"views"`
+	Rewards       float64 `db:"rewards" json:"rewards"`
+	LeaderViews   int64   `db:"leader_views" json:"leader_views"`
+	LeaderRewards float64 `db:"leader_rewards" json:"leader_rewards"`
+	Probability   float64 `db:"probability" json:"probability"`
+	Date          int64   `db:"date" json:"date"`
+}
+
+// ExperimentArm is a banner of an experiment with the Share of the traffic
+// of the slot in percent.
+type ExperimentArm struct {
+	BannerID int64 `db:"banner_id" json:"banner_id"`
+	Share    int64 `db:"share" json:"share"`
+}
+
+// Experiment splits the traffic of the slot between the banners of the arms
+// by fixed shares instead of the bandit. EndedAt is zero for the active
+// experiment of the slot.
+type Experiment struct {
+	ID        int64           `db:"id" json:"id"`
+	SlotID    int64           `db:"slot_id" json:"slot_id"`
+	StartedAt int64           `db:"started_at" json:"started_at"`
+	EndedAt   int64           `db:"ended_at" json:"ended_at"`
+	Arms      []ExperimentArm `db:"-" json:"arms"`
+}
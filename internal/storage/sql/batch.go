@@ -0,0 +1,321 @@
+package sqlstorage
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// maxRowsPerStatement keeps multi-row inserts below the Postgres limit
+// of 65535 bind parameters.
+const maxRowsPerStatement = 1000
+
+// maxPendingBatches limits the rows kept in memory while the database
+// is unavailable, in batches of the configured size.
+const maxPendingBatches = 10
+
+var (
+	errPendingLimit = errors.New("pending rows limit exceeded")
+	errRowsDropped  = errors.New("rows dropped")
+	errUnknownRefs  = errors.New("unknown slot, banner or group")
+)
+
+type eventRow struct {
+	slotID   int64
+	bannerID int64
+	groupID  int64
+	date     int64
+}
+
+// batchWriter accumulates event rows of a table and writes them with
+// multi-row inserts. Rows which failed to be written for a transient error,
+// e.g. a lost connection, are kept and retried with the next flush. A failed
+// insert is retried row by row, the rows failing on their own, e.g. with a
+// foreign key violation, are dropped and counted.
+type batchWriter struct {
+	dropped uint64
+	mu      sync.Mutex
+	flushMu sync.Mutex
+	store   *sqlx.DB
+	table   string
+	size    int
+	rows    []eventRow
+	lastErr error
+	notify  chan struct{}
+}
+
+func newBatchWriter(store *sqlx.DB, table string, size int, notify chan struct{}) *batchWriter {
+	return &batchWriter{
+		store:  store,
+		table:  table,
+		size:   size,
+		rows:   make([]eventRow, 0, size),
+		notify: notify,
+	}
+}
+
+func (w *batchWriter) add(row eventRow) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.rows) >= w.size*maxPendingBatches {
+		return fmt.Errorf("%w (%v)", errPendingLimit, w.lastErr)
+	}
+
+	w.rows = append(w.rows, row)
+	if len(w.rows) >= w.size {
+		select {
+		case w.notify <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+func (w *batchWriter) flush() error {
+	w.flushMu.Lock()
+	defer w.flushMu.Unlock()
+
+	w.mu.Lock()
+	rows := w.rows
+	w.rows = make([]eventRow, 0, w.size)
+	w.mu.Unlock()
+
+	var dropped int
+	var dropErr error
+	for len(rows) > 0 {
+		n := len(rows)
+		if n > maxRowsPerStatement {
+			n = maxRowsPerStatement
+		}
+
+		err := w.insert(rows[:n])
+		if err != nil && !isTransient(err) {
+			var kept []eventRow
+			kept, err = w.insertRows(rows[:n], func(rowErr error) {
+				dropped++
+				dropErr = rowErr
+			})
+			rows = append(append([]eventRow{}, kept...), rows[n:]...)
+			n = len(kept)
+		}
+		if err != nil {
+			w.keep(rows, err)
+
+			return fmt.Errorf("flush %s -> %w", w.table, err)
+		}
+		rows = rows[n:]
+	}
+
+	if dropped > 0 {
+		atomic.AddUint64(&w.dropped, uint64(dropped))
+
+		return fmt.Errorf("flush %s -> %w (%d rows, %s)", w.table, errRowsDropped, dropped, dropErr)
+	}
+
+	return nil
+}
+
+// insertRows inserts the rows one by one and drops the failed ones. On a
+// transient error it stops and returns the rows which are not inserted yet
+// with the error.
+func (w *batchWriter) insertRows(rows []eventRow, drop func(err error)) ([]eventRow, error) {
+	for i, row := range rows {
+		err := w.insert([]eventRow{row})
+		if err == nil {
+			continue
+		}
+		if isTransient(err) {
+			return rows[i:], err
+		}
+		drop(err)
+	}
+
+	return nil, nil
+}
+
+// keep puts the rows back in front of the rows added meanwhile.
+func (w *batchWriter) keep(rows []eventRow, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.rows = append(rows, w.rows...)
+	w.lastErr = err
+}
+
+// isTransient reports the errors of the connection or of the database
+// state, which are expected to pass on a retry. The other errors are caused
+// by the rows, e.g. a foreign key violation or a missing partition.
+func isTransient(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code.Class() {
+		// connection exception, transaction rollback, insufficient resources,
+		// operator intervention
+		case "08", "40", "53", "57":
+			return true
+		}
+	}
+
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		switch sqliteErr.Code() & 0xff {
+		case sqlite3.SQLITE_BUSY, sqlite3.SQLITE_LOCKED, sqlite3.SQLITE_IOERR, sqlite3.SQLITE_FULL:
+			return true
+		}
+	}
+
+	return false
+}
+
+func (w *batchWriter) insert(rows []eventRow) error {
+	var sb strings.Builder
+	args := make([]interface{}, 0, len(rows)*4)
+
+	sb.WriteString("INSERT INTO ")
+	sb.WriteString(w.table)
+	sb.WriteString(" (slot_id, banner_id, group_id, date) VALUES ")
+	for i, row := range rows {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		fmt.Fprintf(&sb, "($%d, $%d, $%d, $%d)", i*4+1, i*4+2, i*4+3, i*4+4)
+		args = append(args, row.slotID, row.bannerID, row.groupID, row.date)
+	}
+	sb.WriteString(";")
+
+	_, err := w.store.Exec(sb.String(), args...)
+
+	return err
+}
+
+// eventRefs remembers the slots, banners and groups known to exist, they are
+// never deleted.
+type eventRefs struct {
+	mu      sync.RWMutex
+	slots   map[int64]struct{}
+	banners map[int64]struct{}
+	groups  map[int64]struct{}
+}
+
+func newEventRefs() *eventRefs {
+	return &eventRefs{
+		slots:   make(map[int64]struct{}),
+		banners: make(map[int64]struct{}),
+		groups:  make(map[int64]struct{}),
+	}
+}
+
+func (r *eventRefs) known(row eventRow) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, slot := r.slots[row.slotID]
+	_, banner := r.banners[row.bannerID]
+	_, group := r.groups[row.groupID]
+
+	return slot && banner && group
+}
+
+func (r *eventRefs) remember(row eventRow) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.slots[row.slotID] = struct{}{}
+	r.banners[row.bannerID] = struct{}{}
+	r.groups[row.groupID] = struct{}{}
+}
+
+type batchFlusher struct {
+	views    *batchWriter
+	clicks   *batchWriter
+	refs     *eventRefs
+	interval time.Duration
+	notify   chan struct{}
+	done     chan struct{}
+	wg       sync.WaitGroup
+}
+
+func newBatchFlusher(store *sqlx.DB, size int, interval time.Duration) *batchFlusher {
+	notify := make(chan struct{}, 1)
+	f := &batchFlusher{
+		views:    newBatchWriter(store, "views", size, notify),
+		clicks:   newBatchWriter(store, "clicks", size, notify),
+		refs:     newEventRefs(),
+		interval: interval,
+		notify:   notify,
+		done:     make(chan struct{}),
+	}
+
+	f.wg.Add(1)
+	go f.run()
+
+	return f
+}
+
+func (f *batchFlusher) run() {
+	defer f.wg.Done()
+
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-f.done:
+			return
+		case <-ticker.C:
+		case <-f.notify:
+		}
+
+		// errors are kept by the writers and reported when the pending limit is reached
+		_ = f.flush()
+	}
+}
+
+// dropped counts the rows dropped for failing on their own.
+func (f *batchFlusher) dropped() uint64 {
+	return atomic.LoadUint64(&f.views.dropped) + atomic.LoadUint64(&f.clicks.dropped)
+}
+
+func (f *batchFlusher) flush() error {
+	viewsErr := f.views.flush()
+	clicksErr := f.clicks.flush()
+	if viewsErr != nil {
+		return viewsErr
+	}
+
+	return clicksErr
+}
+
+// stop terminates the background flushing and writes the pending rows.
+func (f *batchFlusher) stop() error {
+	close(f.done)
+	f.wg.Wait()
+
+	return f.flush()
+}
@@ -0,0 +1,144 @@
+package sqlstorage
+
+import (
+	"banners-rotator/internal/storage"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+)
+
+func expectEventRefs(mock sqlmock.Sqlmock, slotID, bannerID, groupID int64, exists bool) {
+	mock.
+		ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM slots WHERE id = $1)`)).
+		WithArgs(slotID, bannerID, groupID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(exists))
+}
+
+func TestStorage_BatchFlushBySize(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	s := Storage{store: sqlxDB, batch: newBatchFlusher(sqlxDB, 2, time.Hour)}
+
+	t.Run("flush views by size", func(t *testing.T) {
+		expectEventRefs(mock, 1, 1, 1, true)
+		expectEventRefs(mock, 1, 2, 1, true)
+		mock.
+			ExpectExec(regexp.QuoteMeta(
+				`INSERT INTO views (slot_id, banner_id, group_id, date) VALUES ($1, $2, $3, $4), ($5, $6, $7, $8);`,
+			)).
+			WithArgs(1, 1, 1, 1, 1, 2, 1, 2).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+
+		require.NoError(t, s.CreateViewEvent(1, 1, 1, 1))
+		require.NoError(t, s.CreateViewEvent(1, 2, 1, 2))
+		require.Eventually(t, func() bool {
+			return mock.ExpectationsWereMet() == nil
+		}, time.Second, time.Millisecond)
+	})
+
+	mock.ExpectClose()
+	require.NoError(t, s.Close())
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestStorage_BatchFlushOnClose(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	s := Storage{store: sqlxDB, batch: newBatchFlusher(sqlxDB, 100, time.Hour)}
+
+	t.Run("flush pending rows on close", func(t *testing.T) {
+		expectEventRefs(mock, 1, 1, 1, true)
+		require.NoError(t, s.CreateViewEvent(1, 1, 1, 1))
+		require.NoError(t, s.CreateClickEvent(1, 1, 1, 2))
+
+		mock.
+			ExpectExec(regexp.QuoteMeta(
+				`INSERT INTO views (slot_id, banner_id, group_id, date) VALUES ($1, $2, $3, $4);`,
+			)).
+			WithArgs(1, 1, 1, 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.
+			ExpectExec(regexp.QuoteMeta(
+				`INSERT INTO clicks (slot_id, banner_id, group_id, date) VALUES ($1, $2, $3, $4);`,
+			)).
+			WithArgs(1, 1, 1, 2).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectClose()
+
+		require.NoError(t, s.Close())
+	})
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestStorage_BatchRetry(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	s := Storage{store: sqlxDB, batch: newBatchFlusher(sqlxDB, 1, time.Hour)}
+	require.NoError(t, s.batch.stop())
+
+	query := regexp.QuoteMeta(`INSERT INTO clicks (slot_id, banner_id, group_id, date) VALUES ($1, $2, $3, $4);`)
+
+	t.Run("keep rows after failed flush", func(t *testing.T) {
+		expectEventRefs(mock, 1, 1, 1, true)
+		require.NoError(t, s.CreateClickEvent(1, 1, 1, 1))
+
+		mock.ExpectExec(query).WithArgs(1, 1, 1, 1).WillReturnError(&net.OpError{Op: "read", Err: io.ErrUnexpectedEOF})
+		require.Error(t, s.Flush())
+
+		mock.ExpectExec(query).WithArgs(1, 1, 1, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+		require.NoError(t, s.Flush())
+		require.Zero(t, s.DroppedEvents())
+	})
+
+	t.Run("reject unknown refs", func(t *testing.T) {
+		expectEventRefs(mock, 1, 1, 3, false)
+		err = s.CreateClickEvent(1, 1, 3, 1)
+		require.ErrorIs(t, err, storage.ErrClickEventNotCreated)
+		require.NoError(t, s.Flush())
+	})
+
+	t.Run("drop failing rows", func(t *testing.T) {
+		expectEventRefs(mock, 1, 1, 2, true)
+		require.NoError(t, s.CreateClickEvent(1, 1, 2, 1))
+
+		mock.ExpectExec(query).WithArgs(1, 1, 2, 1).WillReturnError(fmt.Errorf("foreign key violation"))
+		mock.ExpectExec(query).WithArgs(1, 1, 2, 1).WillReturnError(fmt.Errorf("foreign key violation"))
+		require.ErrorIs(t, s.Flush(), errRowsDropped)
+		require.Equal(t, uint64(1), s.DroppedEvents())
+
+		require.NoError(t, s.Flush())
+	})
+
+	t.Run("pending rows limit", func(t *testing.T) {
+		for i := 0; i < maxPendingBatches; i++ {
+			require.NoError(t, s.CreateClickEvent(1, 1, 1, 1))
+		}
+
+		err = s.CreateClickEvent(1, 1, 1, 1)
+		require.ErrorIs(t, err, storage.ErrClickEventNotCreated)
+	})
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
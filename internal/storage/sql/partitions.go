@@ -0,0 +1,274 @@
+package sqlstorage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	PartitionDaily   = "daily"
+	PartitionMonthly = "monthly"
+)
+
+const (
+	dailySuffixLayout   = "20060102"
+	monthlySuffixLayout = "200601"
+)
+
+var (
+	ErrUnknownPartitionInterval = errors.New("unknown partition interval")
+	ErrPartitionsNotSupported   = errors.New("partitions are not supported by the driver")
+)
+
+var partitionedTables = []string{"views", "clicks"}
+
+// PartitionPolicy describes the partitions of the event tables: Ahead future
+// partitions of the Interval are kept created, partitions which end before
+// now minus Retention are dropped. Zero Retention keeps all partitions.
+type PartitionPolicy struct {
+	Interval  string
+	Ahead     int
+	Retention time.Duration
+}
+
+type partition struct {
+	name  string
+	start time.Time
+	end   time.Time
+}
+
+// MaintainPartitions creates the missing partitions of the views and clicks
+// tables and drops the expired ones.
+func (s *Storage) MaintainPartitions(ctx context.Context, now time.Time, policy PartitionPolicy) error {
+	if s.store.DriverName() == sqliteDriver {
+		return fmt.Errorf("storage -> maintain partitions -> %w (%s)", ErrPartitionsNotSupported, sqliteDriver)
+	}
+
+	if policy.Interval != PartitionDaily && policy.Interval != PartitionMonthly {
+		return fmt.Errorf(
+			"storage -> maintain partitions -> %w (%s)",
+			ErrUnknownPartitionInterval,
+			policy.Interval,
+		)
+	}
+
+	for _, table := range partitionedTables {
+		existing, err := s.partitions(ctx, table)
+		if err != nil {
+			return fmt.Errorf("storage -> maintain partitions -> %w", err)
+		}
+
+		if err = s.createPartitions(ctx, table, existing, now, policy); err != nil {
+			return fmt.Errorf("storage -> maintain partitions -> %w", err)
+		}
+
+		if policy.Retention > 0 {
+			if err = s.dropPartitions(ctx, existing, now.Add(-policy.Retention)); err != nil {
+				return fmt.Errorf("storage -> maintain partitions -> %w", err)
+			}
+			if err = s.purgeDefaultPartition(ctx, table, now.Add(-policy.Retention)); err != nil {
+				return fmt.Errorf("storage -> maintain partitions -> %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *Storage) partitions(ctx context.Context, table string) (map[string]partition, error) {
+	var names []string
+	err := s.store.SelectContext(
+		ctx,
+		&names,
+		`SELECT c.relname
+				FROM pg_inherits i
+					JOIN pg_class c ON c.oid = i.inhrelid
+					JOIN pg_class p ON p.oid = i.inhparent
+				WHERE p.relname = $1`,
+		table,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list %s partitions -> %w", table, err)
+	}
+
+	result := make(map[string]partition)
+	for _, name := range names {
+		if p, ok := parsePartition(table, name); ok {
+			result[name] = p
+		}
+	}
+
+	return result, nil
+}
+
+func (s *Storage) createPartitions(
+	ctx context.Context,
+	table string,
+	existing map[string]partition,
+	now time.Time,
+	policy PartitionPolicy,
+) error {
+	start := periodStart(now, policy.Interval)
+	for i := 0; i <= policy.Ahead; i++ {
+		end := nextPeriod(start, policy.Interval)
+		name := partitionName(table, start, policy.Interval)
+
+		if !covered(existing, start, end) {
+			if err := s.createPartition(ctx, table, name, start, end); err != nil {
+				return fmt.Errorf("create partition %s -> %w", name, err)
+			}
+		}
+
+		start = end
+	}
+
+	return nil
+}
+
+// createPartition creates the partition. The partitions are created ahead,
+// so the default partition normally holds no events of the period, otherwise
+// they are moved into the new partition, see movePartitionRows.
+func (s *Storage) createPartition(ctx context.Context, table, name string, start, end time.Time) error {
+	var exists bool
+	err := s.store.GetContext(ctx, &exists, fmt.Sprintf(
+		"SELECT EXISTS (SELECT 1 FROM %s_default WHERE date >= %d AND date < %d);",
+		table, start.Unix(), end.Unix(),
+	))
+	if err != nil {
+		return err
+	}
+	if exists {
+		return s.movePartitionRows(ctx, table, name, start, end)
+	}
+
+	_, err = s.store.ExecContext(ctx, fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s PARTITION OF %s FOR VALUES FROM (%d) TO (%d);",
+		name, table, start.Unix(), end.Unix(),
+	))
+
+	return err
+}
+
+// movePartitionRows creates the partition in one transaction with the default
+// partition detached and moves the events of the period from the default
+// partition into it, postgres refuses to create the partition while the
+// default one holds its rows. The transaction blocks the writes of the table.
+func (s *Storage) movePartitionRows(ctx context.Context, table, name string, start, end time.Time) error {
+	defaultName := table + "_default"
+
+	tx, err := s.store.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	for _, query := range []string{
+		fmt.Sprintf("ALTER TABLE %s DETACH PARTITION %s;", table, defaultName),
+		fmt.Sprintf(
+			"CREATE TABLE IF NOT EXISTS %s PARTITION OF %s FOR VALUES FROM (%d) TO (%d);",
+			name, table, start.Unix(), end.Unix(),
+		),
+		fmt.Sprintf(
+			"INSERT INTO %s SELECT * FROM %s WHERE date >= %d AND date < %d;",
+			name, defaultName, start.Unix(), end.Unix(),
+		),
+		fmt.Sprintf("DELETE FROM %s WHERE date >= %d AND date < %d;", defaultName, start.Unix(), end.Unix()),
+		fmt.Sprintf("ALTER TABLE %s ATTACH PARTITION %s DEFAULT;", table, defaultName),
+	} {
+		if _, err = tx.ExecContext(ctx, query); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *Storage) dropPartitions(ctx context.Context, existing map[string]partition, before time.Time) error {
+	for name, p := range existing {
+		if p.end.After(before) {
+			continue
+		}
+
+		if _, err := s.store.ExecContext(ctx, fmt.Sprintf("DROP TABLE IF EXISTS %s;", name)); err != nil {
+			return fmt.Errorf("drop partition %s -> %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// purgeDefaultPartition deletes the expired events of the default partition,
+// they are not dropped with the partitions.
+func (s *Storage) purgeDefaultPartition(ctx context.Context, table string, before time.Time) error {
+	_, err := s.store.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s_default WHERE date < %d;", table, before.Unix()))
+	if err != nil {
+		return fmt.Errorf("purge %s_default -> %w", table, err)
+	}
+
+	return nil
+}
+
+// covered reports whether the period overlaps an existing partition, which
+// may have another interval, e.g. after the monthly partitions of a migration.
+func covered(existing map[string]partition, start, end time.Time) bool {
+	for _, p := range existing {
+		if p.start.Before(end) && start.Before(p.end) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func periodStart(t time.Time, interval string) time.Time {
+	t = t.UTC()
+	if interval == PartitionMonthly {
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func nextPeriod(start time.Time, interval string) time.Time {
+	if interval == PartitionMonthly {
+		return start.AddDate(0, 1, 0)
+	}
+
+	return start.AddDate(0, 0, 1)
+}
+
+func partitionName(table string, start time.Time, interval string) string {
+	if interval == PartitionMonthly {
+		return table + "_p" + start.Format(monthlySuffixLayout)
+	}
+
+	return table + "_p" + start.Format(dailySuffixLayout)
+}
+
+// parsePartition restores the bounds of a partition from its name,
+// the default partition and foreign tables are skipped.
+func parsePartition(table, name string) (partition, bool) {
+	suffix := strings.TrimPrefix(name, table+"_p")
+	if suffix == name {
+		return partition{}, false
+	}
+
+	interval := PartitionDaily
+	layout := dailySuffixLayout
+	if len(suffix) == len(monthlySuffixLayout) {
+		interval = PartitionMonthly
+		layout = monthlySuffixLayout
+	}
+
+	start, err := time.Parse(layout, suffix)
+	if err != nil {
+		return partition{}, false
+	}
+
+	return partition{name: name, start: start, end: nextPeriod(start, interval)}, true
+}
@@ -0,0 +1,156 @@
+package sqlstorage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorage_MaintainPartitions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	s := Storage{store: sqlxDB}
+
+	listQuery := regexp.QuoteMeta(`SELECT c.relname
+				FROM pg_inherits i`)
+	now := time.Date(2022, 1, 19, 12, 0, 0, 0, time.UTC)
+
+	t.Run("maintain daily partitions", func(t *testing.T) {
+		mock.
+			ExpectQuery(listQuery).
+			WithArgs("views").
+			WillReturnRows(
+				sqlmock.NewRows([]string{"relname"}).
+					AddRow("views_default").
+					AddRow("views_p20211231").
+					AddRow("views_p202201"),
+			)
+		expectCreatePartition(mock, "views", "views_p20220201", 1643673600, 1643760000)
+		mock.
+			ExpectExec(regexp.QuoteMeta(`DROP TABLE IF EXISTS views_p20211231;`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.
+			ExpectExec(regexp.QuoteMeta(`DELETE FROM views_default WHERE date < 1641988800;`)).
+			WillReturnResult(sqlmock.NewResult(0, 3))
+
+		mock.
+			ExpectQuery(listQuery).
+			WithArgs("clicks").
+			WillReturnRows(sqlmock.NewRows([]string{"relname"}).AddRow("clicks_default").AddRow("clicks_p202201"))
+		expectMovePartitionRows(mock, "clicks", "clicks_p20220201", 1643673600, 1643760000)
+		mock.
+			ExpectExec(regexp.QuoteMeta(`DELETE FROM clicks_default WHERE date < 1641988800;`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err = s.MaintainPartitions(context.Background(), now, PartitionPolicy{
+			Interval:  PartitionDaily,
+			Ahead:     13,
+			Retention: 7 * 24 * time.Hour,
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("roll back failed partition", func(t *testing.T) {
+		mock.
+			ExpectQuery(listQuery).
+			WithArgs("views").
+			WillReturnRows(sqlmock.NewRows([]string{"relname"}).AddRow("views_default"))
+		expectDefaultRows(mock, "views", 1642550400, 1642636800, true)
+		mock.ExpectBegin()
+		mock.
+			ExpectExec(regexp.QuoteMeta(`ALTER TABLE views DETACH PARTITION views_default;`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.
+			ExpectExec(regexp.QuoteMeta(`CREATE TABLE IF NOT EXISTS views_p20220119 PARTITION OF views`)).
+			WillReturnError(errors.New("lock timeout"))
+		mock.ExpectRollback()
+
+		err = s.MaintainPartitions(context.Background(), now, PartitionPolicy{Interval: PartitionDaily})
+		require.Error(t, err)
+	})
+
+	t.Run("unknown partition interval", func(t *testing.T) {
+		err = s.MaintainPartitions(context.Background(), now, PartitionPolicy{Interval: "weekly"})
+		require.ErrorIs(t, err, ErrUnknownPartitionInterval)
+	})
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func expectDefaultRows(mock sqlmock.Sqlmock, table string, from, to int64, exists bool) {
+	mock.
+		ExpectQuery(regexp.QuoteMeta(fmt.Sprintf(
+			"SELECT EXISTS (SELECT 1 FROM %s_default WHERE date >= %d AND date < %d);", table, from, to,
+		))).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(exists))
+}
+
+// expectCreatePartition expects the partition to be created while the
+// default partition holds no rows of its period.
+func expectCreatePartition(mock sqlmock.Sqlmock, table, name string, from, to int64) {
+	expectDefaultRows(mock, table, from, to, false)
+	mock.
+		ExpectExec(regexp.QuoteMeta(fmt.Sprintf(
+			"CREATE TABLE IF NOT EXISTS %s PARTITION OF %s FOR VALUES FROM (%d) TO (%d);", name, table, from, to,
+		))).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+// expectMovePartitionRows expects the partition to be created with the rows
+// of its period moved from the detached default partition.
+func expectMovePartitionRows(mock sqlmock.Sqlmock, table, name string, from, to int64) {
+	expectDefaultRows(mock, table, from, to, true)
+	mock.ExpectBegin()
+	mock.
+		ExpectExec(regexp.QuoteMeta(fmt.Sprintf("ALTER TABLE %s DETACH PARTITION %s_default;", table, table))).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.
+		ExpectExec(regexp.QuoteMeta(fmt.Sprintf(
+			"CREATE TABLE IF NOT EXISTS %s PARTITION OF %s FOR VALUES FROM (%d) TO (%d);", name, table, from, to,
+		))).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.
+		ExpectExec(regexp.QuoteMeta(fmt.Sprintf(
+			"INSERT INTO %s SELECT * FROM %s_default WHERE date >= %d AND date < %d;", name, table, from, to,
+		))).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.
+		ExpectExec(regexp.QuoteMeta(fmt.Sprintf(
+			"DELETE FROM %s_default WHERE date >= %d AND date < %d;", table, from, to,
+		))).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.
+		ExpectExec(regexp.QuoteMeta(fmt.Sprintf("ALTER TABLE %s ATTACH PARTITION %s_default DEFAULT;", table, table))).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+}
+
+func TestStorage_parsePartition(t *testing.T) {
+	t.Run("parse partition names", func(t *testing.T) {
+		p, ok := parsePartition("views", "views_p20220131")
+		require.True(t, ok)
+		require.Equal(t, time.Date(2022, 2, 1, 0, 0, 0, 0, time.UTC), p.end)
+
+		p, ok = parsePartition("views", "views_p202212")
+		require.True(t, ok)
+		require.Equal(t, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), p.end)
+
+		_, ok = parsePartition("views", "views_default")
+		require.False(t, ok)
+
+		_, ok = parsePartition("views", "clicks_p20220131")
+		require.False(t, ok)
+	})
+}
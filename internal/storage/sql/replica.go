@@ -0,0 +1,108 @@
+package sqlstorage
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// replicaPingTimeout limits a single health check of the replica.
+const replicaPingTimeout = time.Second
+
+// replica is a read-only copy of the database. It is used while its health
+// checks pass, the primary serves the reads otherwise.
+type replica struct {
+	db       *sqlx.DB
+	healthy  int32
+	interval time.Duration
+	done     chan struct{}
+	wg       sync.WaitGroup
+}
+
+func newReplica(db *sqlx.DB, interval time.Duration) *replica {
+	r := &replica{
+		db:       db,
+		interval: interval,
+		done:     make(chan struct{}),
+	}
+	r.check()
+
+	r.wg.Add(1)
+	go r.run()
+
+	return r
+}
+
+func (r *replica) run() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+			r.check()
+		}
+	}
+}
+
+// check pings the replica and records the result.
+func (r *replica) check() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), replicaPingTimeout)
+	defer cancel()
+
+	if err := r.db.PingContext(ctx); err != nil {
+		atomic.StoreInt32(&r.healthy, 0)
+		return false
+	}
+
+	atomic.StoreInt32(&r.healthy, 1)
+
+	return true
+}
+
+func (r *replica) isHealthy() bool {
+	return atomic.LoadInt32(&r.healthy) == 1
+}
+
+func (r *replica) close() error {
+	close(r.done)
+	r.wg.Wait()
+
+	return r.db.Close()
+}
+
+// readDB is the replica when it is healthy, otherwise the primary.
+func (s *Storage) readDB() *sqlx.DB {
+	if s.replica != nil && s.replica.isHealthy() {
+		return s.replica.db
+	}
+
+	return s.store
+}
+
+// selectRead runs the read-only query on the replica when it is healthy.
+// A failed query makes the replica to be checked at once, and the query is
+// repeated on the primary.
+func (s *Storage) selectRead(dest interface{}, query string, args ...interface{}) error {
+	if s.replica != nil && s.replica.isHealthy() {
+		err := s.replica.db.Select(dest, query, args...)
+		if err == nil {
+			return nil
+		}
+
+		s.replica.check()
+		// rows scanned before the failure are discarded
+		v := reflect.ValueOf(dest).Elem()
+		v.Set(reflect.Zero(v.Type()))
+	}
+
+	return s.store.Select(dest, query, args...)
+}
@@ -0,0 +1,102 @@
+package sqlstorage
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+)
+
+const slotBannersQuery = `SELECT *
+				FROM banners
+				WHERE id IN (
+					SELECT banner_id
+					FROM rotations
+					WHERE slot_id = $1 AND paused_at = 0
+				)`
+
+func TestStorage_Replica(t *testing.T) {
+	primaryDB, primary, err := sqlmock.New()
+	require.NoError(t, err)
+	defer primaryDB.Close()
+
+	replicaDB, replicaMock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	require.NoError(t, err)
+
+	replicaMock.ExpectPing()
+	s := Storage{
+		store:   sqlx.NewDb(primaryDB, "sqlmock"),
+		replica: newReplica(sqlx.NewDb(replicaDB, "sqlmock"), time.Hour),
+	}
+	require.True(t, s.replica.isHealthy())
+
+	t.Run("reads go to healthy replica", func(t *testing.T) {
+		replicaMock.
+			ExpectQuery(regexp.QuoteMeta(slotBannersQuery)).
+			WithArgs(1).
+			WillReturnRows(replicaMock.NewRows([]string{"id", "description"}).AddRow(1, "replica"))
+
+		banners, err := s.SlotBanners(1)
+		require.NoError(t, err)
+		require.Len(t, *banners, 1)
+		require.Equal(t, "replica", (*banners)[0].Description)
+	})
+
+	t.Run("writes go to primary", func(t *testing.T) {
+		primary.
+			ExpectExec(regexp.QuoteMeta(`INSERT INTO rotations (slot_id, banner_id, prior_views, prior_rewards)`)).
+			WithArgs(1, 2, 0, 0.0).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		require.NoError(t, s.CreateRotation(1, 2))
+	})
+
+	t.Run("failed replica falls back to primary", func(t *testing.T) {
+		replicaMock.
+			ExpectQuery(regexp.QuoteMeta(`SELECT * FROM views WHERE slot_id= $1`)).
+			WithArgs(1).
+			WillReturnError(errors.New("connection refused"))
+		replicaMock.ExpectPing().WillReturnError(errors.New("connection refused"))
+		primary.
+			ExpectQuery(regexp.QuoteMeta(`SELECT * FROM views WHERE slot_id= $1`)).
+			WithArgs(1).
+			WillReturnRows(primary.NewRows([]string{"slot_id", "banner_id", "group_id", "date"}).AddRow(1, 1, 1, 1))
+
+		views, err := s.SlotViews(1)
+		require.NoError(t, err)
+		require.Len(t, *views, 1)
+		require.False(t, s.replica.isHealthy())
+	})
+
+	t.Run("unhealthy replica is skipped", func(t *testing.T) {
+		primary.
+			ExpectQuery(regexp.QuoteMeta(`SELECT * FROM clicks WHERE slot_id= $1`)).
+			WithArgs(1).
+			WillReturnRows(primary.NewRows([]string{"slot_id", "banner_id", "group_id", "date"}))
+
+		_, err := s.SlotClicks(1)
+		require.NoError(t, err)
+	})
+
+	t.Run("recovered replica is used again", func(t *testing.T) {
+		replicaMock.ExpectPing()
+		require.True(t, s.replica.check())
+
+		replicaMock.
+			ExpectQuery(regexp.QuoteMeta(slotBannersQuery)).
+			WithArgs(1).
+			WillReturnRows(replicaMock.NewRows([]string{"id", "description"}))
+
+		_, err := s.SlotBanners(1)
+		require.NoError(t, err)
+	})
+
+	replicaMock.ExpectClose()
+	require.NoError(t, s.replica.close())
+	require.NoError(t, primary.ExpectationsWereMet())
+	require.NoError(t, replicaMock.ExpectationsWereMet())
+}
@@ -0,0 +1,46 @@
+package sqlstorage
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/jmoiron/sqlx"
+
+	// database/sql implementation of SQLite without cgo.
+	_ "modernc.org/sqlite"
+)
+
+const sqliteDriver = "sqlite"
+
+// sqlitePragmas enables the foreign keys, which SQLite ignores by default,
+// and makes a locked database to be waited for instead of failing at once.
+var sqlitePragmas = []string{"foreign_keys(1)", "busy_timeout(5000)", "journal_mode(WAL)"}
+
+// OpenSQLite opens the SQLite database file at path. The pool is limited to
+// a single connection, as SQLite serializes writes anyway.
+func OpenSQLite(ctx context.Context, path string) (*sqlx.DB, error) {
+	query := url.Values{}
+	for _, pragma := range sqlitePragmas {
+		query.Add("_pragma", pragma)
+	}
+
+	db, err := sqlx.ConnectContext(ctx, sqliteDriver, "file:"+path+"?"+query.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite -> %w", err)
+	}
+	db.SetMaxOpenConns(1)
+
+	return db, nil
+}
+
+// NewSQLiteStorage creates the storage on top of the SQLite database file at
+// path. The schema is expected to be applied by the SQLite migrations.
+func NewSQLiteStorage(ctx context.Context, path string, opts ...Option) (*Storage, error) {
+	store, err := OpenSQLite(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("new storage -> %w", err)
+	}
+
+	return newStorage(store, opts...), nil
+}
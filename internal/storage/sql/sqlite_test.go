@@ -0,0 +1,157 @@
+package sqlstorage
+
+import (
+	"banners-rotator/internal/migrator"
+	"banners-rotator/internal/rotator"
+	"banners-rotator/internal/storage"
+	"banners-rotator/internal/storage/storagetest"
+	"banners-rotator/migrations"
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newSQLiteStorage(t *testing.T, opts ...Option) *Storage {
+	t.Helper()
+
+	ctx := context.Background()
+	s, err := NewSQLiteStorage(ctx, filepath.Join(t.TempDir(), "rotator.db"), opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = s.Close()
+	})
+
+	m, err := migrator.NewMigrator(s.store, migrations.SQLiteFS)
+	require.NoError(t, err)
+	_, err = m.Up(ctx)
+	require.NoError(t, err)
+
+	return s
+}
+
+func TestSQLiteStorage_Conformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) rotator.Storage {
+		return newSQLiteStorage(t)
+	})
+}
+
+func TestSQLiteStorage_AddHourlyStats(t *testing.T) {
+	s := newSQLiteStorage(t)
+	stats := storage.HourlyStats{SlotID: 1, BannerID: 2, GroupID: 3, Hour: 3600, Views: 1}
+
+	require.NoError(t, s.AddHourlyStats("first", stats))
+	require.NoError(t, s.AddHourlyStats("second", stats))
+
+	err := s.AddHourlyStats("first", stats)
+	require.ErrorIs(t, err, storage.ErrMessageProcessed)
+
+	var views int64
+	err = s.store.Get(&views, `SELECT views FROM hourly_stats WHERE slot_id = $1 AND hour = $2`, stats.SlotID, stats.Hour)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), views)
+
+	count, err := s.PurgeProcessedMessages(time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	count, err = s.PurgeProcessedMessages(time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+	require.NoError(t, s.AddHourlyStats("first", stats))
+}
+
+func TestSQLiteStorage_Batch(t *testing.T) {
+	s := newSQLiteStorage(t, WithBatch(10, time.Hour))
+
+	slot, err := s.CreateSlot("slot")
+	require.NoError(t, err)
+	banner, err := s.CreateBanner("banner")
+	require.NoError(t, err)
+	group, err := s.CreateGroup("group")
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, s.CreateViewEvent(slot.ID, banner.ID, group.ID, int64(i)))
+	}
+	require.NoError(t, s.Flush())
+
+	views, err := s.SlotViews(slot.ID)
+	require.NoError(t, err)
+	require.Len(t, *views, 3)
+}
+
+func TestSQLiteStorage_BatchRejectsUnknownRefs(t *testing.T) {
+	s := newSQLiteStorage(t, WithBatch(10, time.Hour))
+
+	slot, err := s.CreateSlot("slot")
+	require.NoError(t, err)
+	banner, err := s.CreateBanner("banner")
+	require.NoError(t, err)
+	group, err := s.CreateGroup("group")
+	require.NoError(t, err)
+
+	require.NoError(t, s.CreateViewEvent(slot.ID, banner.ID, group.ID, 1))
+	err = s.CreateViewEvent(slot.ID, banner.ID, group.ID+100, 2)
+	require.ErrorIs(t, err, storage.ErrViewEventNotCreated)
+	err = s.CreateClickEvent(slot.ID+100, banner.ID, group.ID, 2)
+	require.ErrorIs(t, err, storage.ErrClickEventNotCreated)
+	require.NoError(t, s.CreateViewEvent(slot.ID, banner.ID, group.ID, 3))
+	require.NoError(t, s.Flush())
+	require.Zero(t, s.DroppedEvents())
+
+	for i := 0; i < 20; i++ {
+		require.NoError(t, s.CreateViewEvent(slot.ID, banner.ID, group.ID, int64(10+i)))
+	}
+	require.NoError(t, s.Flush())
+
+	views, err := s.SlotViews(slot.ID)
+	require.NoError(t, err)
+	require.Len(t, *views, 22)
+}
+
+func TestSQLiteStorage_MaintainPartitions(t *testing.T) {
+	s := newSQLiteStorage(t)
+
+	err := s.MaintainPartitions(context.Background(), time.Now(), PartitionPolicy{Interval: PartitionDaily})
+	require.ErrorIs(t, err, ErrPartitionsNotSupported)
+}
+
+func TestSQLiteStorage_Events(t *testing.T) {
+	s := newSQLiteStorage(t)
+
+	slot, err := s.CreateSlot("slot")
+	require.NoError(t, err)
+	other, err := s.CreateSlot("other")
+	require.NoError(t, err)
+	banner, err := s.CreateBanner("banner")
+	require.NoError(t, err)
+	group, err := s.CreateGroup("group")
+	require.NoError(t, err)
+
+	require.NoError(t, s.CreateClickEvent(slot.ID, banner.ID, group.ID, 20))
+	require.NoError(t, s.CreateViewEvent(slot.ID, banner.ID, group.ID, 20))
+	require.NoError(t, s.CreateViewEvent(slot.ID, banner.ID, group.ID, 10))
+	require.NoError(t, s.CreateViewEvent(other.ID, banner.ID, group.ID, 15))
+	require.NoError(t, s.CreateViewEvent(slot.ID, banner.ID, group.ID, 30))
+
+	var events []storage.Event
+	collect := func(e storage.Event) error {
+		events = append(events, e)
+		return nil
+	}
+
+	require.NoError(t, s.Events(context.Background(), slot.ID, 10, 30, collect))
+	require.Equal(t, []storage.Event{
+		{Type: storage.EventView, SlotID: slot.ID, BannerID: banner.ID, GroupID: group.ID, Date: 10},
+		{Type: storage.EventView, SlotID: slot.ID, BannerID: banner.ID, GroupID: group.ID, Date: 20},
+		{Type: storage.EventClick, SlotID: slot.ID, BannerID: banner.ID, GroupID: group.ID, Date: 20},
+	}, events)
+
+	events = nil
+	require.NoError(t, s.Events(context.Background(), 0, 0, 100, collect))
+	require.Len(t, events, 5)
+	require.Equal(t, other.ID, events[1].SlotID)
+}
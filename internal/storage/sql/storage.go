@@ -4,7 +4,9 @@ import (
 	"banners-rotator/internal/storage"
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 
@@ -13,16 +15,131 @@ import (
 )
 
 type Storage struct {
-	store *sqlx.DB
+	store   *sqlx.DB
+	replica *replica
+	batch   *batchFlusher
+	options options
 }
 
-func NewStorage(ctx context.Context, connectionString string) (*Storage, error) {
+type options struct {
+	batchSize            int
+	batchInterval        time.Duration
+	pool                 PoolConfig
+	replicaDSN           string
+	replicaCheckInterval time.Duration
+	observer             QueryObserver
+}
+
+// PoolConfig holds the connection pool settings, zero values keep the
+// database/sql defaults.
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+func (c PoolConfig) apply(db *sqlx.DB) {
+	if c.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(c.MaxOpenConns)
+	}
+	if c.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(c.MaxIdleConns)
+	}
+	if c.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(c.ConnMaxLifetime)
+	}
+	if c.ConnMaxIdleTime > 0 {
+		db.SetConnMaxIdleTime(c.ConnMaxIdleTime)
+	}
+}
+
+type Option func(o *options)
+
+// WithBatch makes view and click events to be buffered and written with
+// multi-row inserts once size rows are collected or the interval elapses.
+// Pending rows are written on Close. The buffered rows are not seen by
+// SlotViews and SlotClicks until they are written.
+func WithBatch(size int, interval time.Duration) Option {
+	return func(o *options) {
+		o.batchSize = size
+		o.batchInterval = interval
+	}
+}
+
+// WithPool applies the connection pool settings to the primary and the
+// replica databases.
+func WithPool(pool PoolConfig) Option {
+	return func(o *options) {
+		o.pool = pool
+	}
+}
+
+// WithReplica routes the read-only queries of slot banners, views and clicks
+// to the replica. The replica is pinged every checkInterval and the primary
+// serves the reads while the replica is unavailable.
+func WithReplica(connectionString string, checkInterval time.Duration) Option {
+	return func(o *options) {
+		o.replicaDSN = connectionString
+		o.replicaCheckInterval = checkInterval
+	}
+}
+
+// QueryObserver records the duration of the queries of the storage methods.
+type QueryObserver interface {
+	ObserveQuery(method string, duration time.Duration)
+}
+
+// WithQueryObserver reports the duration of every storage method to the
+// observer, a buffered event is reported when it is added to the batch.
+func WithQueryObserver(observer QueryObserver) Option {
+	return func(o *options) {
+		o.observer = observer
+	}
+}
+
+func NewStorage(ctx context.Context, connectionString string, opts ...Option) (*Storage, error) {
 	store, err := sqlx.ConnectContext(ctx, "postgres", connectionString)
 	if err != nil {
 		return nil, fmt.Errorf("new storage -> %w", err)
 	}
 
-	return &Storage{store}, nil
+	s := newStorage(store, opts...)
+	s.options.pool.apply(store)
+
+	if s.options.replicaDSN != "" {
+		// the replica is not required to be available on start
+		replicaStore, err := sqlx.Open("postgres", s.options.replicaDSN)
+		if err != nil {
+			_ = s.Close()
+			return nil, fmt.Errorf("new storage -> replica -> %w", err)
+		}
+		s.options.pool.apply(replicaStore)
+
+		if s.options.replicaCheckInterval <= 0 {
+			s.options.replicaCheckInterval = 5 * time.Second
+		}
+		s.replica = newReplica(replicaStore, s.options.replicaCheckInterval)
+	}
+
+	return s, nil
+}
+
+// newStorage applies the options to the storage over the opened database.
+func newStorage(store *sqlx.DB, opts ...Option) *Storage {
+	s := &Storage{store: store}
+	for _, opt := range opts {
+		opt(&s.options)
+	}
+
+	if s.options.batchSize > 0 {
+		if s.options.batchInterval <= 0 {
+			s.options.batchInterval = time.Second
+		}
+		s.batch = newBatchFlusher(store, s.options.batchSize, s.options.batchInterval)
+	}
+
+	return s
 }
 
 func (s *Storage) Connect(ctx context.Context) error {
@@ -34,10 +151,46 @@ func (s *Storage) Connect(ctx context.Context) error {
 }
 
 func (s *Storage) Close() error {
+	if s.replica != nil {
+		_ = s.replica.close()
+	}
+
+	if s.batch != nil {
+		if err := s.batch.stop(); err != nil {
+			_ = s.store.Close()
+			return fmt.Errorf("close storage -> %w", err)
+		}
+	}
+
 	return s.store.Close()
 }
 
+// DroppedEvents counts the buffered view and click events which were dropped
+// for failing to be written on their own, e.g. with an unknown group.
+func (s *Storage) DroppedEvents() uint64 {
+	if s.batch == nil {
+		return 0
+	}
+
+	return s.batch.dropped()
+}
+
+// Flush writes the buffered view and click events.
+func (s *Storage) Flush() error {
+	if s.batch == nil {
+		return nil
+	}
+
+	if err := s.batch.flush(); err != nil {
+		return fmt.Errorf("storage -> flush -> %w", err)
+	}
+
+	return nil
+}
+
 func (s *Storage) CreateSlot(description string) (*storage.Slot, error) {
+	defer s.observe("CreateSlot", time.Now())
+
 	r := s.store.QueryRowx(
 		"INSERT INTO slots (description) VALUES ($1) RETURNING id;",
 		description,
@@ -55,6 +208,8 @@ func (s *Storage) CreateSlot(description string) (*storage.Slot, error) {
 }
 
 func (s *Storage) CreateBanner(description string) (*storage.Banner, error) {
+	defer s.observe("CreateBanner", time.Now())
+
 	r := s.store.QueryRowx(
 		"INSERT INTO banners (description) VALUES ($1) RETURNING id;",
 		description,
@@ -72,6 +227,8 @@ func (s *Storage) CreateBanner(description string) (*storage.Banner, error) {
 }
 
 func (s *Storage) CreateGroup(description string) (*storage.Group, error) {
+	defer s.observe("CreateGroup", time.Now())
+
 	r := s.store.QueryRowx(
 		"INSERT INTO groups (description) VALUES ($1) RETURNING id;",
 		description,
@@ -88,10 +245,98 @@ func (s *Storage) CreateGroup(description string) (*storage.Group, error) {
 	return &storage.Group{ID: id, Description: description}, nil
 }
 
+// CreateGroupWithParent creates the group under the parent in one statement,
+// nothing is inserted when the parent does not exist.
+func (s *Storage) CreateGroupWithParent(description string, parentID int64) (*storage.Group, error) {
+	defer s.observe("CreateGroupWithParent", time.Now())
+
+	r := s.store.QueryRowx(
+		`INSERT INTO groups (description, parent_id) SELECT CAST($1 AS text), CAST($2 AS bigint)
+				WHERE $2 = 0 OR EXISTS (SELECT 1 FROM groups WHERE id = $2) RETURNING id;`,
+		description, parentID,
+	)
+	var id int64
+	err := r.Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("storage -> create group with parent -> %w (parent not found)", storage.ErrGroupParentNotSet)
+	}
+	if err != nil {
+		return nil, fmt.Errorf(
+			"storage -> create group with parent -> %w (%s)",
+			storage.ErrGroupNotCreated,
+			err,
+		)
+	}
+
+	return &storage.Group{ID: id, Description: description, ParentID: parentID}, nil
+}
+
+func (s *Storage) Group(id int64) (*storage.Group, error) {
+	defer s.observe("Group", time.Now())
+
+	var g storage.Group
+	err := s.store.Get(&g, "SELECT id, description, parent_id FROM groups WHERE id = $1;", id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("storage -> group -> %w (%d)", storage.ErrGroupNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage -> group -> %w", err)
+	}
+
+	return &g, nil
+}
+
+func (s *Storage) Groups() (*[]storage.Group, error) {
+	defer s.observe("Groups", time.Now())
+
+	var g []storage.Group
+	err := s.store.Select(&g, "SELECT id, description, parent_id FROM groups ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("storage -> groups -> %w", err)
+	}
+
+	return &g, nil
+}
+
+// SetGroupParent replaces the parent of the group, zero parentID makes the
+// group a top-level one. The parent has to exist.
+func (s *Storage) SetGroupParent(groupID, parentID int64) error {
+	defer s.observe("SetGroupParent", time.Now())
+
+	r, err := s.store.Exec(
+		`UPDATE groups SET parent_id = $1
+				WHERE id = $2 AND ($1 = 0 OR EXISTS (SELECT 1 FROM groups WHERE id = $1));`,
+		parentID, groupID,
+	)
+	if err != nil {
+		return fmt.Errorf("storage -> set group parent -> %w (%s)", storage.ErrGroupParentNotSet, err)
+	}
+
+	if count, err := r.RowsAffected(); err != nil || count == 0 {
+		return fmt.Errorf("storage -> set group parent -> %w (group or parent not found)", storage.ErrGroupParentNotSet)
+	}
+
+	return nil
+}
+
 func (s *Storage) CreateRotation(slotID, bannerID int64) error {
-	_, err := s.store.Exec(
-		"INSERT INTO rotations (slot_id, banner_id) VALUES ($1, $2);",
-		slotID, bannerID,
+	defer s.observe("CreateRotation", time.Now())
+
+	return s.createRotation(slotID, bannerID, storage.Prior{})
+}
+
+// CreateRotationWithPrior creates the rotation and its prior in one statement.
+func (s *Storage) CreateRotationWithPrior(slotID, bannerID int64, prior storage.Prior) error {
+	defer s.observe("CreateRotationWithPrior", time.Now())
+
+	return s.createRotation(slotID, bannerID, prior)
+}
+
+func (s *Storage) createRotation(slotID, bannerID int64, prior storage.Prior) error {
+	r, err := s.store.Exec(
+		`INSERT INTO rotations (slot_id, banner_id, prior_views, prior_rewards) VALUES ($1, $2, $3, $4)
+				ON CONFLICT (slot_id, banner_id) DO NOTHING;`,
+		slotID, bannerID, prior.Views, prior.Rewards,
 	)
 	if err != nil {
 		return fmt.Errorf(
@@ -101,10 +346,34 @@ func (s *Storage) CreateRotation(slotID, bannerID int64) error {
 		)
 	}
 
+	if count, err := r.RowsAffected(); err != nil || count == 0 {
+		return fmt.Errorf("storage -> create rotation -> %w (%d, %d)", storage.ErrRotationExists, slotID, bannerID)
+	}
+
+	return nil
+}
+
+func (s *Storage) SetRotationPrior(slotID, bannerID int64, prior storage.Prior) error {
+	defer s.observe("SetRotationPrior", time.Now())
+
+	r, err := s.store.Exec(
+		"UPDATE rotations SET prior_views = $1, prior_rewards = $2 WHERE slot_id = $3 AND banner_id = $4;",
+		prior.Views, prior.Rewards, slotID, bannerID,
+	)
+	if err != nil {
+		return fmt.Errorf("storage -> set rotation prior -> %w (%s)", storage.ErrPriorNotSet, err)
+	}
+
+	if count, err := r.RowsAffected(); err != nil || count == 0 {
+		return fmt.Errorf("storage -> set rotation prior -> %w (rotation not found)", storage.ErrPriorNotSet)
+	}
+
 	return nil
 }
 
 func (s *Storage) DeleteRotation(slotID, bannerID int64) error {
+	defer s.observe("DeleteRotation", time.Now())
+
 	r, err := s.store.Exec(
 		"DELETE FROM rotations WHERE slot_id=$1 AND banner_id=$2;",
 		slotID, bannerID,
@@ -127,6 +396,28 @@ func (s *Storage) DeleteRotation(slotID, bannerID int64) error {
 }
 
 func (s *Storage) CreateViewEvent(slotID, bannerID, groupID, date int64) error {
+	defer s.observe("CreateViewEvent", time.Now())
+
+	if s.batch != nil {
+		row := eventRow{slotID, bannerID, groupID, date}
+		if err := s.checkEventRefs(row); err != nil {
+			return fmt.Errorf(
+				"storage -> create view event -> %w (%s)",
+				storage.ErrViewEventNotCreated,
+				err,
+			)
+		}
+		if err := s.batch.views.add(row); err != nil {
+			return fmt.Errorf(
+				"storage -> create view event -> %w (%s)",
+				storage.ErrViewEventNotCreated,
+				err,
+			)
+		}
+
+		return nil
+	}
+
 	_, err := s.store.Exec(
 		"INSERT INTO views (slot_id, banner_id, group_id, date) VALUES ($1, $2, $3, $4);",
 		slotID, bannerID, groupID, date,
@@ -143,6 +434,28 @@ func (s *Storage) CreateViewEvent(slotID, bannerID, groupID, date int64) error {
 }
 
 func (s *Storage) CreateClickEvent(slotID, bannerID, groupID, date int64) error {
+	defer s.observe("CreateClickEvent", time.Now())
+
+	if s.batch != nil {
+		row := eventRow{slotID, bannerID, groupID, date}
+		if err := s.checkEventRefs(row); err != nil {
+			return fmt.Errorf(
+				"storage -> create click event -> %w (%s)",
+				storage.ErrClickEventNotCreated,
+				err,
+			)
+		}
+		if err := s.batch.clicks.add(row); err != nil {
+			return fmt.Errorf(
+				"storage -> create click event -> %w (%s)",
+				storage.ErrClickEventNotCreated,
+				err,
+			)
+		}
+
+		return nil
+	}
+
 	_, err := s.store.Exec(
 		"INSERT INTO clicks (slot_id, banner_id, group_id, date) VALUES ($1, $2, $3, $4);",
 		slotID, bannerID, groupID, date,
@@ -158,38 +471,72 @@ func (s *Storage) CreateClickEvent(slotID, bannerID, groupID, date int64) error
 	return nil
 }
 
-func (s *Storage) NotViewedBanners(slotID int64) (*[]storage.Banner, error) {
-	var b []storage.Banner
-	err := s.store.Select(
-		&b,
-		`SELECT *
-				FROM banners
-				WHERE id IN (
-					SELECT banner_id
-					FROM rotations
-					WHERE slot_id = $1
-						EXCEPT (SELECT banner_id FROM views WHERE slot_id = $1)
-				)`,
-		slotID,
+// checkEventRefs rejects the event of an unknown slot, banner or group before
+// it is buffered, such a row would fail the batch insert after the call has
+// returned.
+func (s *Storage) checkEventRefs(row eventRow) error {
+	if s.batch.refs.known(row) {
+		return nil
+	}
+
+	var exists bool
+	err := s.store.Get(
+		&exists,
+		`SELECT EXISTS (SELECT 1 FROM slots WHERE id = $1)
+				AND EXISTS (SELECT 1 FROM banners WHERE id = $2)
+				AND EXISTS (SELECT 1 FROM groups WHERE id = $3);`,
+		row.slotID, row.bannerID, row.groupID,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("storage -> not viewed banners -> %w", err)
+		return err
+	}
+	if !exists {
+		return errUnknownRefs
 	}
+	s.batch.refs.remember(row)
 
-	return &b, nil
+	return nil
 }
 
+// CreateConversionEvent is written at once, the conversions are not batched.
+func (s *Storage) CreateConversionEvent(
+	slotID, bannerID, groupID int64,
+	conversionType string,
+	value float64,
+	date int64,
+) error {
+	defer s.observe("CreateConversionEvent", time.Now())
+
+	_, err := s.store.Exec(
+		"INSERT INTO conversions (slot_id, banner_id, group_id, type, value, date) VALUES ($1, $2, $3, $4, $5, $6);",
+		slotID, bannerID, groupID, conversionType, value, date,
+	)
+	if err != nil {
+		return fmt.Errorf(
+			"storage -> create conversion event -> %w (%s)",
+			storage.ErrConversionNotCreated,
+			err,
+		)
+	}
+
+	return nil
+}
+
+// SlotBanners lists the banners of the slot except the paused ones.
 func (s *Storage) SlotBanners(slotID int64) (*[]storage.Banner, error) {
+	defer s.observe("SlotBanners", time.Now())
+
 	var b []storage.Banner
-	err := s.store.Select(
+	err := s.selectRead(
 		&b,
 		`SELECT *
 				FROM banners
 				WHERE id IN (
 					SELECT banner_id
 					FROM rotations
-					WHERE slot_id = $1
-				)`,
+					WHERE slot_id = $1 AND paused_at = 0
+				)
+				ORDER BY id`,
 		slotID,
 	)
 	if err != nil {
@@ -199,9 +546,31 @@ func (s *Storage) SlotBanners(slotID int64) (*[]storage.Banner, error) {
 	return &b, nil
 }
 
+func (s *Storage) SlotPriors(slotID int64) (*[]storage.RotationPrior, error) {
+	defer s.observe("SlotPriors", time.Now())
+
+	var p []storage.RotationPrior
+	err := s.selectRead(
+		&p,
+		`SELECT banner_id, prior_views, prior_rewards
+				FROM rotations
+				WHERE slot_id = $1 AND prior_views > 0
+				ORDER BY banner_id`,
+		slotID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("storage -> slot priors -> %w", err)
+	}
+
+	return &p, nil
+}
+
+// SlotViews misses the views buffered by WithBatch until the flush.
 func (s *Storage) SlotViews(slotID int64) (*[]storage.ViewEvent, error) {
+	defer s.observe("SlotViews", time.Now())
+
 	var ve []storage.ViewEvent
-	err := s.store.Select(
+	err := s.selectRead(
 		&ve,
 		`SELECT * FROM views WHERE slot_id= $1`,
 		slotID,
@@ -214,8 +583,10 @@ func (s *Storage) SlotViews(slotID int64) (*[]storage.ViewEvent, error) {
 }
 
 func (s *Storage) SlotClicks(slotID int64) (*[]storage.ClickEvent, error) {
+	defer s.observe("SlotClicks", time.Now())
+
 	var ce []storage.ClickEvent
-	err := s.store.Select(
+	err := s.selectRead(
 		&ce,
 		`SELECT * FROM clicks WHERE slot_id= $1`,
 		slotID,
@@ -227,6 +598,546 @@ func (s *Storage) SlotClicks(slotID int64) (*[]storage.ClickEvent, error) {
 	return &ce, nil
 }
 
+func (s *Storage) SlotConversions(slotID int64) (*[]storage.ConversionEvent, error) {
+	defer s.observe("SlotConversions", time.Now())
+
+	var ce []storage.ConversionEvent
+	err := s.selectRead(
+		&ce,
+		`SELECT slot_id, banner_id, group_id, type, value, date FROM conversions WHERE slot_id = $1`,
+		slotID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("storage -> slot conversions -> %w", err)
+	}
+
+	return &ce, nil
+}
+
+func (s *Storage) SlotRewardModel(slotID int64) (string, error) {
+	defer s.observe("SlotRewardModel", time.Now())
+
+	var model string
+	err := s.store.Get(&model, "SELECT reward_model FROM slots WHERE id = $1;", slotID)
+	if err != nil {
+		return "", fmt.Errorf("storage -> slot reward model -> %w", err)
+	}
+
+	return model, nil
+}
+
+func (s *Storage) SetSlotRewardModel(slotID int64, model string) error {
+	defer s.observe("SetSlotRewardModel", time.Now())
+
+	r, err := s.store.Exec("UPDATE slots SET reward_model = $1 WHERE id = $2;", model, slotID)
+	if err != nil {
+		return fmt.Errorf("storage -> set slot reward model -> %w (%s)", storage.ErrRewardModelNotSet, err)
+	}
+
+	if count, err := r.RowsAffected(); err != nil || count == 0 {
+		return fmt.Errorf("storage -> set slot reward model -> %w (slot not found)", storage.ErrRewardModelNotSet)
+	}
+
+	return nil
+}
+
+func (s *Storage) SlotStats(slotID, from, to int64, byGroup bool) (*[]storage.BannerStats, error) {
+	defer s.observe("SlotStats", time.Now())
+
+	group, groupBy := "0", "banner_id"
+	if byGroup {
+		group, groupBy = "group_id", "banner_id, group_id"
+	}
+
+	var bs []storage.BannerStats
+	err := s.selectRead(
+		&bs,
+		`SELECT banner_id, `+group+` AS group_id, SUM(views) AS views, SUM(clicks) AS clicks
+				FROM (
+					SELECT banner_id, group_id, 1 AS views, 0 AS clicks
+					FROM views
+					WHERE slot_id = $1 AND date >= $2 AND date < $3
+					UNION ALL
+					SELECT banner_id, group_id, 0 AS views, 1 AS clicks
+					FROM clicks
+					WHERE slot_id = $1 AND date >= $2 AND date < $3
+				) AS events
+				GROUP BY `+groupBy+`
+				ORDER BY banner_id, group_id`,
+		slotID, from, to,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("storage -> slot stats -> %w", err)
+	}
+
+	return &bs, nil
+}
+
+func (s *Storage) BannerStats(bannerID, exceptSlotID int64) (*storage.BannerStats, error) {
+	defer s.observe("BannerStats", time.Now())
+
+	var bs []storage.BannerStats
+	err := s.selectRead(
+		&bs,
+		`SELECT banner_id, 0 AS group_id, SUM(views) AS views, SUM(clicks) AS clicks
+				FROM (
+					SELECT banner_id, 1 AS views, 0 AS clicks
+					FROM views
+					WHERE banner_id = $1 AND slot_id <> $2
+					UNION ALL
+					SELECT banner_id, 0 AS views, 1 AS clicks
+					FROM clicks
+					WHERE banner_id = $1 AND slot_id <> $2
+				) AS events
+				GROUP BY banner_id`,
+		bannerID, exceptSlotID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("storage -> banner stats -> %w", err)
+	}
+
+	if len(bs) == 0 {
+		return &storage.BannerStats{BannerID: bannerID}, nil
+	}
+
+	return &bs[0], nil
+}
+
+func (s *Storage) SlotSeries(filter storage.SeriesFilter) (*[]storage.SeriesPoint, error) {
+	defer s.observe("SlotSeries", time.Now())
+
+	args := []interface{}{filter.SlotID, filter.From, filter.To}
+	where := "slot_id = $1 AND date >= $2 AND date < $3"
+	if filter.BannerID > 0 {
+		args = append(args, filter.BannerID)
+		where += fmt.Sprintf(" AND banner_id = $%d", len(args))
+	}
+	if filter.GroupID > 0 {
+		args = append(args, filter.GroupID)
+		where += fmt.Sprintf(" AND group_id = $%d", len(args))
+	}
+	args = append(args, filter.BucketOffset, filter.BucketSize)
+	bucket := fmt.Sprintf("(date - $%[1]d) / $%[2]d * $%[2]d + $%[1]d", len(args)-1, len(args))
+
+	var sp []storage.SeriesPoint
+	err := s.selectRead(
+		&sp,
+		`SELECT `+bucket+` AS bucket, SUM(views) AS views, SUM(clicks) AS clicks
+				FROM (
+					SELECT date, 1 AS views, 0 AS clicks FROM views WHERE `+where+`
+					UNION ALL
+					SELECT date, 0 AS views, 1 AS clicks FROM clicks WHERE `+where+`
+				) AS events
+				GROUP BY bucket
+				ORDER BY bucket`,
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("storage -> slot series -> %w", err)
+	}
+
+	return &sp, nil
+}
+
+// Events streams the view and click events with from <= date < to to fn in
+// the order of dates, views go before clicks of the same second. A zero slotID
+// selects all slots. The events are read from the replica when it is healthy.
+func (s *Storage) Events(ctx context.Context, slotID, from, to int64, fn func(storage.Event) error) error {
+	defer s.observe("Events", time.Now())
+
+	where := "date >= $1 AND date < $2"
+	args := []interface{}{from, to}
+	if slotID > 0 {
+		args = append(args, slotID)
+		where += " AND slot_id = $3"
+	}
+
+	rows, err := s.readDB().QueryxContext(
+		ctx,
+		`SELECT 'view' AS type, slot_id, banner_id, group_id, date FROM views WHERE `+where+`
+				UNION ALL
+				SELECT 'click' AS type, slot_id, banner_id, group_id, date FROM clicks WHERE `+where+`
+				ORDER BY date, type DESC`,
+		args...,
+	)
+	if err != nil {
+		return fmt.Errorf("storage -> events -> %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e storage.Event
+		if err = rows.StructScan(&e); err != nil {
+			return fmt.Errorf("storage -> events -> %w", err)
+		}
+		if err = fn(e); err != nil {
+			return err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("storage -> events -> %w", err)
+	}
+
+	return nil
+}
+
+// AddHourlyStats adds the view and click counters of the event to its hourly
+// bucket. The message ID is recorded in the same transaction, so a redelivered
+// message is detected and reported with storage.ErrMessageProcessed.
+func (s *Storage) AddHourlyStats(messageID string, stats storage.HourlyStats) error {
+	defer s.observe("AddHourlyStats", time.Now())
+
+	tx, err := s.store.Beginx()
+	if err != nil {
+		return fmt.Errorf("storage -> add hourly stats -> %w (%s)", storage.ErrStatsNotSaved, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	r, err := tx.Exec(
+		"INSERT INTO processed_messages (id) VALUES ($1) ON CONFLICT DO NOTHING;",
+		messageID,
+	)
+	if err != nil {
+		return fmt.Errorf("storage -> add hourly stats -> %w (%s)", storage.ErrStatsNotSaved, err)
+	}
+	if count, err := r.RowsAffected(); err != nil || count == 0 {
+		return fmt.Errorf("storage -> add hourly stats -> %w (%s)", storage.ErrMessageProcessed, messageID)
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO hourly_stats (slot_id, banner_id, group_id, hour, views, clicks)
+				VALUES ($1, $2, $3, $4, $5, $6)
+				ON CONFLICT (slot_id, banner_id, group_id, hour) DO UPDATE
+				SET views = hourly_stats.views + EXCLUDED.views,
+					clicks = hourly_stats.clicks + EXCLUDED.clicks;`,
+		stats.SlotID, stats.BannerID, stats.GroupID, stats.Hour, stats.Views, stats.Clicks,
+	)
+	if err != nil {
+		return fmt.Errorf("storage -> add hourly stats -> %w (%s)", storage.ErrStatsNotSaved, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("storage -> add hourly stats -> %w (%s)", storage.ErrStatsNotSaved, err)
+	}
+
+	return nil
+}
+
+// PurgeProcessedMessages deletes the IDs of the messages processed before
+// the time and returns their number.
+func (s *Storage) PurgeProcessedMessages(before time.Time) (int64, error) {
+	defer s.observe("PurgeProcessedMessages", time.Now())
+
+	r, err := s.store.Exec("DELETE FROM processed_messages WHERE created_at < $1;", before.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("storage -> purge processed messages -> %w", err)
+	}
+
+	count, err := r.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("storage -> purge processed messages -> %w", err)
+	}
+
+	return count, nil
+}
+
+// SlotModel always reads the primary, the model is saved with the version
+// read here.
+func (s *Storage) SlotModel(slotID int64) (*storage.SlotModel, error) {
+	defer s.observe("SlotModel", time.Now())
+
+	var m storage.SlotModel
+	err := s.store.Get(&m, "SELECT slot_id, version, state FROM slot_models WHERE slot_id = $1;", slotID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &storage.SlotModel{SlotID: slotID}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage -> slot model -> %w", err)
+	}
+
+	return &m, nil
+}
+
+func (s *Storage) SaveSlotModel(model storage.SlotModel) error {
+	defer s.observe("SaveSlotModel", time.Now())
+
+	var (
+		r   sql.Result
+		err error
+	)
+	if model.Version == 0 {
+		r, err = s.store.Exec(
+			"INSERT INTO slot_models (slot_id, version, state) VALUES ($1, 1, $2) ON CONFLICT DO NOTHING;",
+			model.SlotID, model.State,
+		)
+	} else {
+		r, err = s.store.Exec(
+			"UPDATE slot_models SET version = version + 1, state = $1 WHERE slot_id = $2 AND version = $3;",
+			model.State, model.SlotID, model.Version,
+		)
+	}
+	if err != nil {
+		return fmt.Errorf("storage -> save slot model -> %w (%s)", storage.ErrModelNotSaved, err)
+	}
+
+	if count, err := r.RowsAffected(); err != nil || count == 0 {
+		return fmt.Errorf("storage -> save slot model -> %w (version %d)", storage.ErrModelConflict, model.Version)
+	}
+
+	return nil
+}
+
+func (s *Storage) SlotPruningPolicy(slotID int64) (*storage.PruningPolicy, error) {
+	defer s.observe("SlotPruningPolicy", time.Now())
+
+	var p storage.PruningPolicy
+	err := s.store.Get(
+		&p,
+		"SELECT slot_id, enabled, confidence, min_views FROM pruning_policies WHERE slot_id = $1;",
+		slotID,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &storage.PruningPolicy{SlotID: slotID}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage -> slot pruning policy -> %w", err)
+	}
+
+	return &p, nil
+}
+
+func (s *Storage) SetSlotPruningPolicy(policy storage.PruningPolicy) error {
+	defer s.observe("SetSlotPruningPolicy", time.Now())
+
+	_, err := s.store.Exec(
+		`INSERT INTO pruning_policies (slot_id, enabled, confidence, min_views)
+				VALUES ($1, $2, $3, $4)
+				ON CONFLICT (slot_id) DO UPDATE
+				SET enabled = EXCLUDED.enabled,
+					confidence = EXCLUDED.confidence,
+					min_views = EXCLUDED.min_views;`,
+		policy.SlotID, policy.Enabled, policy.Confidence, policy.MinViews,
+	)
+	if err != nil {
+		return fmt.Errorf("storage -> set slot pruning policy -> %w (%s)", storage.ErrPolicyNotSet, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) PruningPolicies() (*[]storage.PruningPolicy, error) {
+	defer s.observe("PruningPolicies", time.Now())
+
+	var p []storage.PruningPolicy
+	err := s.store.Select(
+		&p,
+		`SELECT slot_id, enabled, confidence, min_views
+				FROM pruning_policies
+				WHERE enabled
+				ORDER BY slot_id`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("storage -> pruning policies -> %w", err)
+	}
+
+	return &p, nil
+}
+
+// PauseRotation pauses the rotation and records the pruning in the same
+// transaction. A rotation paused already is reported with
+// storage.ErrRotationNotPaused, so the pruning is recorded once.
+func (s *Storage) PauseRotation(pruning storage.Pruning) error {
+	defer s.observe("PauseRotation", time.Now())
+
+	tx, err := s.store.Beginx()
+	if err != nil {
+		return fmt.Errorf("storage -> pause rotation -> %w (%s)", storage.ErrRotationNotPaused, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	r, err := tx.Exec(
+		"UPDATE rotations SET paused_at = $1 WHERE slot_id = $2 AND banner_id = $3 AND paused_at = 0;",
+		pruning.Date, pruning.SlotID, pruning.BannerID,
+	)
+	if err != nil {
+		return fmt.Errorf("storage -> pause rotation -> %w (%s)", storage.ErrRotationNotPaused, err)
+	}
+	if count, err := r.RowsAffected(); err != nil || count == 0 {
+		return fmt.Errorf("storage -> pause rotation -> %w (not found or paused)", storage.ErrRotationNotPaused)
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO prunings (slot_id, banner_id, leader_id, views, rewards, leader_views, leader_rewards, probability, date)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);`,
+		pruning.SlotID, pruning.BannerID, pruning.LeaderID, pruning.Views, pruning.Rewards,
+		pruning.LeaderViews, pruning.LeaderRewards, pruning.Probability, pruning.Date,
+	)
+	if err != nil {
+		return fmt.Errorf("storage -> pause rotation -> %w (%s)", storage.ErrRotationNotPaused, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("storage -> pause rotation -> %w (%s)", storage.ErrRotationNotPaused, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) ResumeRotation(slotID, bannerID int64) error {
+	defer s.observe("ResumeRotation", time.Now())
+
+	r, err := s.store.Exec(
+		"UPDATE rotations SET paused_at = 0 WHERE slot_id = $1 AND banner_id = $2 AND paused_at <> 0;",
+		slotID, bannerID,
+	)
+	if err != nil {
+		return fmt.Errorf("storage -> resume rotation -> %w (%s)", storage.ErrRotationNotResumed, err)
+	}
+	if count, err := r.RowsAffected(); err != nil || count == 0 {
+		return fmt.Errorf("storage -> resume rotation -> %w (not found or not paused)", storage.ErrRotationNotResumed)
+	}
+
+	return nil
+}
+
+func (s *Storage) SlotPrunings(slotID int64) (*[]storage.Pruning, error) {
+	defer s.observe("SlotPrunings", time.Now())
+
+	var p []storage.Pruning
+	err := s.selectRead(
+		&p,
+		`SELECT slot_id, banner_id, leader_id, views, rewards, leader_views, leader_rewards, probability, date
+				FROM prunings
+				WHERE slot_id = $1
+				ORDER BY date, banner_id`,
+		slotID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("storage -> slot prunings -> %w", err)
+	}
+
+	return &p, nil
+}
+
+// CreateExperiment saves the experiment with its arms in the same
+// transaction. A slot has one active experiment at most, the unique index
+// rejects another one.
+func (s *Storage) CreateExperiment(experiment storage.Experiment) (*storage.Experiment, error) {
+	defer s.observe("CreateExperiment", time.Now())
+
+	tx, err := s.store.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("storage -> create experiment -> %w (%s)", storage.ErrExperimentNotCreated, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	r := tx.QueryRowx(
+		"INSERT INTO experiments (slot_id, started_at) VALUES ($1, $2) RETURNING id;",
+		experiment.SlotID, experiment.StartedAt,
+	)
+	if err = r.Scan(&experiment.ID); err != nil {
+		return nil, fmt.Errorf("storage -> create experiment -> %w (%s)", storage.ErrExperimentNotCreated, err)
+	}
+
+	for _, arm := range experiment.Arms {
+		_, err = tx.Exec(
+			"INSERT INTO experiment_arms (experiment_id, banner_id, share) VALUES ($1, $2, $3);",
+			experiment.ID, arm.BannerID, arm.Share,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("storage -> create experiment -> %w (%s)", storage.ErrExperimentNotCreated, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("storage -> create experiment -> %w (%s)", storage.ErrExperimentNotCreated, err)
+	}
+	experiment.EndedAt = 0
+
+	return &experiment, nil
+}
+
+func (s *Storage) ActiveExperiment(slotID int64) (*storage.Experiment, error) {
+	defer s.observe("ActiveExperiment", time.Now())
+
+	var e storage.Experiment
+	err := s.store.Get(
+		&e,
+		"SELECT id, slot_id, started_at, ended_at FROM experiments WHERE slot_id = $1 AND ended_at = 0;",
+		slotID,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("storage -> active experiment -> %w (slot %d)", storage.ErrExperimentNotFound, slotID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage -> active experiment -> %w", err)
+	}
+
+	if e.Arms, err = s.experimentArms(e.ID); err != nil {
+		return nil, fmt.Errorf("storage -> active experiment -> %w", err)
+	}
+
+	return &e, nil
+}
+
+func (s *Storage) Experiment(id int64) (*storage.Experiment, error) {
+	defer s.observe("Experiment", time.Now())
+
+	var e storage.Experiment
+	err := s.store.Get(&e, "SELECT id, slot_id, started_at, ended_at FROM experiments WHERE id = $1;", id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("storage -> experiment -> %w (%d)", storage.ErrExperimentNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage -> experiment -> %w", err)
+	}
+
+	if e.Arms, err = s.experimentArms(e.ID); err != nil {
+		return nil, fmt.Errorf("storage -> experiment -> %w", err)
+	}
+
+	return &e, nil
+}
+
+func (s *Storage) EndExperiment(id, date int64) error {
+	defer s.observe("EndExperiment", time.Now())
+
+	r, err := s.store.Exec("UPDATE experiments SET ended_at = $1 WHERE id = $2 AND ended_at = 0;", date, id)
+	if err != nil {
+		return fmt.Errorf("storage -> end experiment -> %w (%s)", storage.ErrExperimentNotEnded, err)
+	}
+
+	if count, err := r.RowsAffected(); err != nil || count == 0 {
+		return fmt.Errorf("storage -> end experiment -> %w (not found or ended)", storage.ErrExperimentNotEnded)
+	}
+
+	return nil
+}
+
+func (s *Storage) experimentArms(experimentID int64) ([]storage.ExperimentArm, error) {
+	var arms []storage.ExperimentArm
+	err := s.store.Select(
+		&arms,
+		"SELECT banner_id, share FROM experiment_arms WHERE experiment_id = $1 ORDER BY banner_id",
+		experimentID,
+	)
+
+	return arms, err
+}
+
+// observe reports the duration of the method started at start.
+func (s *Storage) observe(method string, start time.Time) {
+	if s.options.observer != nil {
+		s.options.observer.ObserveQuery(method, time.Since(start))
+	}
+}
+
 func (s *Storage) Exec(query string, args ...interface{}) (sql.Result, error) {
 	return s.store.Exec(query, args...)
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
@@ -111,16 +112,34 @@ func TestStorage_CreateRotation(t *testing.T) {
 	s := Storage{store: sqlxDB}
 
 	t.Run("create rotation", func(t *testing.T) {
+		query := regexp.QuoteMeta(
+			`INSERT INTO rotations (slot_id, banner_id, prior_views, prior_rewards) VALUES ($1, $2, $3, $4)
+				ON CONFLICT (slot_id, banner_id) DO NOTHING;`,
+		)
 		mock.
-			ExpectExec(regexp.QuoteMeta(`INSERT INTO rotations (slot_id, banner_id) VALUES ($1, $2);`)).
-			WithArgs(1, 1).
+			ExpectExec(query).
+			WithArgs(1, 1, 0, 0.0).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 		err = s.CreateRotation(1, 1)
 		require.NoError(t, err)
 
 		mock.
-			ExpectExec(regexp.QuoteMeta(`INSERT INTO rotations (slot_id, banner_id) VALUES ($1, $2);`)).
-			WithArgs(1, 1).
+			ExpectExec(query).
+			WithArgs(1, 2, 50, 2.5).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		err = s.CreateRotationWithPrior(1, 2, storage.Prior{Views: 50, Rewards: 2.5})
+		require.NoError(t, err)
+
+		mock.
+			ExpectExec(query).
+			WithArgs(1, 1, 0, 0.0).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		err = s.CreateRotation(1, 1)
+		require.ErrorIs(t, err, storage.ErrRotationExists)
+
+		mock.
+			ExpectExec(query).
+			WithArgs(1, 1, 0, 0.0).
 			WillReturnError(fmt.Errorf("test error"))
 		err = s.CreateRotation(1, 1)
 		require.ErrorIs(t, err, storage.ErrRotationNotCreated)
@@ -226,7 +245,7 @@ func TestStorage_CreateClickEvent(t *testing.T) {
 	}
 }
 
-func TestStorage_NotViewedBanners(t *testing.T) {
+func TestStorage_SlotBanners(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
 	defer db.Close()
@@ -234,7 +253,7 @@ func TestStorage_NotViewedBanners(t *testing.T) {
 	sqlxDB := sqlx.NewDb(db, "sqlmock")
 	s := Storage{store: sqlxDB}
 
-	t.Run("not viewed banners", func(t *testing.T) {
+	t.Run("slot banners", func(t *testing.T) {
 		mock.
 			ExpectQuery(regexp.QuoteMeta(
 				`SELECT *
@@ -242,13 +261,12 @@ func TestStorage_NotViewedBanners(t *testing.T) {
 				WHERE id IN (
 					SELECT banner_id
 					FROM rotations
-					WHERE slot_id = $1
-						EXCEPT (SELECT banner_id FROM views WHERE slot_id = $1)
+					WHERE slot_id = $1 AND paused_at = 0
 				)`,
 			)).
 			WithArgs(1).
 			WillReturnRows(sqlmock.NewRows([]string{"id", "description"}).AddRow(1, "test 1"))
-		banners, err := s.NotViewedBanners(1)
+		banners, err := s.SlotBanners(1)
 		require.NoError(t, err)
 		require.Len(t, *banners, 1)
 	})
@@ -258,7 +276,7 @@ func TestStorage_NotViewedBanners(t *testing.T) {
 	}
 }
 
-func TestStorage_SlotBanners(t *testing.T) {
+func TestStorage_SlotViews(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
 	defer db.Close()
@@ -266,22 +284,20 @@ func TestStorage_SlotBanners(t *testing.T) {
 	sqlxDB := sqlx.NewDb(db, "sqlmock")
 	s := Storage{store: sqlxDB}
 
-	t.Run("slot banners", func(t *testing.T) {
+	t.Run("slot views", func(t *testing.T) {
 		mock.
 			ExpectQuery(regexp.QuoteMeta(
-				`SELECT *
-				FROM banners
-				WHERE id IN (
-					SELECT banner_id
-					FROM rotations
-					WHERE slot_id = $1
-				)`,
+				`SELECT * FROM views WHERE slot_id= $1`,
 			)).
 			WithArgs(1).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "description"}).AddRow(1, "test 1"))
-		banners, err := s.SlotBanners(1)
+			WillReturnRows(
+				sqlmock.
+					NewRows([]string{"slot_id", "banner_id", "group_id", "date"}).
+					AddRow(1, 1, 1, 1),
+			)
+		views, err := s.SlotViews(1)
 		require.NoError(t, err)
-		require.Len(t, *banners, 1)
+		require.Len(t, *views, 1)
 	})
 
 	if err = mock.ExpectationsWereMet(); err != nil {
@@ -289,7 +305,7 @@ func TestStorage_SlotBanners(t *testing.T) {
 	}
 }
 
-func TestStorage_SlotViews(t *testing.T) {
+func TestStorage_SlotClicks(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
 	defer db.Close()
@@ -297,10 +313,10 @@ func TestStorage_SlotViews(t *testing.T) {
 	sqlxDB := sqlx.NewDb(db, "sqlmock")
 	s := Storage{store: sqlxDB}
 
-	t.Run("slot views", func(t *testing.T) {
+	t.Run("slot clicks", func(t *testing.T) {
 		mock.
 			ExpectQuery(regexp.QuoteMeta(
-				`SELECT * FROM views WHERE slot_id= $1`,
+				`SELECT * FROM clicks WHERE slot_id= $1`,
 			)).
 			WithArgs(1).
 			WillReturnRows(
@@ -308,7 +324,7 @@ func TestStorage_SlotViews(t *testing.T) {
 					NewRows([]string{"slot_id", "banner_id", "group_id", "date"}).
 					AddRow(1, 1, 1, 1),
 			)
-		views, err := s.SlotViews(1)
+		views, err := s.SlotClicks(1)
 		require.NoError(t, err)
 		require.Len(t, *views, 1)
 	})
@@ -318,7 +334,7 @@ func TestStorage_SlotViews(t *testing.T) {
 	}
 }
 
-func TestStorage_SlotClicks(t *testing.T) {
+func TestStorage_SlotStats(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
 	defer db.Close()
@@ -326,23 +342,450 @@ func TestStorage_SlotClicks(t *testing.T) {
 	sqlxDB := sqlx.NewDb(db, "sqlmock")
 	s := Storage{store: sqlxDB}
 
-	t.Run("slot clicks", func(t *testing.T) {
+	t.Run("slot stats by group", func(t *testing.T) {
+		mock.
+			ExpectQuery(`SELECT banner_id, group_id AS group_id, .+ GROUP BY banner_id, group_id`).
+			WithArgs(1, 10, 20).
+			WillReturnRows(
+				sqlmock.
+					NewRows([]string{"banner_id", "group_id", "views", "clicks"}).
+					AddRow(1, 2, 10, 1),
+			)
+		stats, err := s.SlotStats(1, 10, 20, true)
+		require.NoError(t, err)
+		require.Equal(t, []storage.BannerStats{{BannerID: 1, GroupID: 2, Views: 10, Clicks: 1}}, *stats)
+	})
+
+	t.Run("slot stats error", func(t *testing.T) {
+		mock.
+			ExpectQuery(`SELECT banner_id, 0 AS group_id, .+ GROUP BY banner_id\s+ORDER BY`).
+			WithArgs(1, 10, 20).
+			WillReturnError(fmt.Errorf("test error"))
+		_, err := s.SlotStats(1, 10, 20, false)
+		require.Error(t, err)
+	})
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestStorage_SlotSeries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	s := Storage{store: sqlxDB}
+
+	t.Run("slot series of banner", func(t *testing.T) {
 		mock.
 			ExpectQuery(regexp.QuoteMeta(
-				`SELECT * FROM clicks WHERE slot_id= $1`,
+				`SELECT (date - $5) / $6 * $6 + $5 AS bucket, SUM(views) AS views, SUM(clicks) AS clicks`,
 			)).
-			WithArgs(1).
+			WithArgs(1, 100, 200, 2, 0, 3600).
 			WillReturnRows(
 				sqlmock.
-					NewRows([]string{"slot_id", "banner_id", "group_id", "date"}).
-					AddRow(1, 1, 1, 1),
+					NewRows([]string{"bucket", "views", "clicks"}).
+					AddRow(0, 10, 1),
 			)
-		views, err := s.SlotClicks(1)
+		points, err := s.SlotSeries(storage.SeriesFilter{SlotID: 1, BannerID: 2, From: 100, To: 200, BucketSize: 3600})
 		require.NoError(t, err)
-		require.Len(t, *views, 1)
+		require.Equal(t, []storage.SeriesPoint{{Bucket: 0, Views: 10, Clicks: 1}}, *points)
 	})
 
 	if err = mock.ExpectationsWereMet(); err != nil {
 		t.Errorf("there were unfulfilled expectations: %s", err)
 	}
 }
+
+func TestStorage_AddHourlyStats(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	s := Storage{store: sqlxDB}
+
+	stats := storage.HourlyStats{SlotID: 1, BannerID: 1, GroupID: 1, Hour: 3600, Views: 1}
+	processedQuery := regexp.QuoteMeta(`INSERT INTO processed_messages (id) VALUES ($1) ON CONFLICT DO NOTHING;`)
+	statsQuery := regexp.QuoteMeta(`INSERT INTO hourly_stats (slot_id, banner_id, group_id, hour, views, clicks)`)
+
+	t.Run("add hourly stats", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(processedQuery).WithArgs("id").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(statsQuery).WithArgs(1, 1, 1, 3600, 1, 0).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+		err = s.AddHourlyStats("id", stats)
+		require.NoError(t, err)
+	})
+
+	t.Run("add hourly stats for processed message", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(processedQuery).WithArgs("id").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+		err = s.AddHourlyStats("id", stats)
+		require.ErrorIs(t, err, storage.ErrMessageProcessed)
+	})
+
+	t.Run("add hourly stats error", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(processedQuery).WithArgs("id").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(statsQuery).WithArgs(1, 1, 1, 3600, 1, 0).WillReturnError(fmt.Errorf("test error"))
+		mock.ExpectRollback()
+		err = s.AddHourlyStats("id", stats)
+		require.ErrorIs(t, err, storage.ErrStatsNotSaved)
+	})
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestStorage_PurgeProcessedMessages(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	s := Storage{store: sqlxDB}
+
+	before := time.Date(2022, 1, 19, 12, 0, 0, 0, time.UTC)
+	query := regexp.QuoteMeta(`DELETE FROM processed_messages WHERE created_at < $1;`)
+
+	t.Run("purge processed messages", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(before).WillReturnResult(sqlmock.NewResult(0, 3))
+		count, err := s.PurgeProcessedMessages(before)
+		require.NoError(t, err)
+		require.Equal(t, int64(3), count)
+	})
+
+	t.Run("purge error", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(before).WillReturnError(fmt.Errorf("test error"))
+		_, err := s.PurgeProcessedMessages(before)
+		require.Error(t, err)
+	})
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestStorage_SaveSlotModel(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	s := Storage{store: sqlxDB}
+
+	insertQuery := regexp.QuoteMeta(`INSERT INTO slot_models (slot_id, version, state) VALUES ($1, 1, $2) ON CONFLICT DO NOTHING;`)
+	updateQuery := regexp.QuoteMeta(`UPDATE slot_models SET version = version + 1, state = $1 WHERE slot_id = $2 AND version = $3;`)
+	state := []byte("state")
+
+	t.Run("save new slot model", func(t *testing.T) {
+		mock.ExpectExec(insertQuery).WithArgs(1, state).WillReturnResult(sqlmock.NewResult(1, 1))
+		err = s.SaveSlotModel(storage.SlotModel{SlotID: 1, State: state})
+		require.NoError(t, err)
+	})
+
+	t.Run("save slot model", func(t *testing.T) {
+		mock.ExpectExec(updateQuery).WithArgs(state, 1, 3).WillReturnResult(sqlmock.NewResult(0, 1))
+		err = s.SaveSlotModel(storage.SlotModel{SlotID: 1, Version: 3, State: state})
+		require.NoError(t, err)
+	})
+
+	t.Run("save changed slot model", func(t *testing.T) {
+		mock.ExpectExec(updateQuery).WithArgs(state, 1, 3).WillReturnResult(sqlmock.NewResult(0, 0))
+		err = s.SaveSlotModel(storage.SlotModel{SlotID: 1, Version: 3, State: state})
+		require.ErrorIs(t, err, storage.ErrModelConflict)
+	})
+
+	t.Run("save slot model error", func(t *testing.T) {
+		mock.ExpectExec(insertQuery).WithArgs(1, state).WillReturnError(fmt.Errorf("test error"))
+		err = s.SaveSlotModel(storage.SlotModel{SlotID: 1, State: state})
+		require.ErrorIs(t, err, storage.ErrModelNotSaved)
+	})
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestStorage_CreateConversionEvent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	s := Storage{store: sqlxDB}
+
+	query := regexp.QuoteMeta(`INSERT INTO conversions (slot_id, banner_id, group_id, type, value, date) VALUES ($1, $2, $3, $4, $5, $6);`)
+
+	t.Run("create conversion event", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(1, 2, 3, "purchase", 9.5, 100).WillReturnResult(sqlmock.NewResult(1, 1))
+		err = s.CreateConversionEvent(1, 2, 3, "purchase", 9.5, 100)
+		require.NoError(t, err)
+
+		mock.ExpectExec(query).WithArgs(1, 2, 3, "purchase", 9.5, 100).WillReturnError(fmt.Errorf("test error"))
+		err = s.CreateConversionEvent(1, 2, 3, "purchase", 9.5, 100)
+		require.ErrorIs(t, err, storage.ErrConversionNotCreated)
+	})
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestStorage_SetSlotRewardModel(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	s := Storage{store: sqlxDB}
+
+	query := regexp.QuoteMeta(`UPDATE slots SET reward_model = $1 WHERE id = $2;`)
+
+	t.Run("set slot reward model", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs("revenue", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+		err = s.SetSlotRewardModel(1, "revenue")
+		require.NoError(t, err)
+	})
+
+	t.Run("set reward model of unknown slot", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs("revenue", 2).WillReturnResult(sqlmock.NewResult(0, 0))
+		err = s.SetSlotRewardModel(2, "revenue")
+		require.ErrorIs(t, err, storage.ErrRewardModelNotSet)
+	})
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestStorage_SetRotationPrior(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	s := Storage{store: sqlxDB}
+
+	query := regexp.QuoteMeta(
+		`UPDATE rotations SET prior_views = $1, prior_rewards = $2 WHERE slot_id = $3 AND banner_id = $4;`,
+	)
+	prior := storage.Prior{Views: 100, Rewards: 3}
+
+	t.Run("set rotation prior", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(100, 3.0, 1, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+		err = s.SetRotationPrior(1, 2, prior)
+		require.NoError(t, err)
+	})
+
+	t.Run("set prior of unknown rotation", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(100, 3.0, 1, 3).WillReturnResult(sqlmock.NewResult(0, 0))
+		err = s.SetRotationPrior(1, 3, prior)
+		require.ErrorIs(t, err, storage.ErrPriorNotSet)
+	})
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestStorage_PauseRotation(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	s := Storage{store: sqlxDB}
+
+	pauseQuery := regexp.QuoteMeta(
+		`UPDATE rotations SET paused_at = $1 WHERE slot_id = $2 AND banner_id = $3 AND paused_at = 0;`,
+	)
+	pruningQuery := regexp.QuoteMeta(`INSERT INTO prunings (slot_id, banner_id, leader_id, views, rewards,`)
+	pruning := storage.Pruning{
+		SlotID:        1,
+		BannerID:      2,
+		LeaderID:      3,
+		Views:         100,
+		Rewards:       1,
+		LeaderViews:   100,
+		LeaderRewards: 10,
+		Probability:   0.99,
+		Date:          3600,
+	}
+
+	t.Run("pause rotation", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(pauseQuery).WithArgs(3600, 1, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(pruningQuery).
+			WithArgs(1, 2, 3, 100, 1.0, 100, 10.0, 0.99, 3600).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+		err = s.PauseRotation(pruning)
+		require.NoError(t, err)
+	})
+
+	t.Run("pause paused rotation", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(pauseQuery).WithArgs(3600, 1, 2).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+		err = s.PauseRotation(pruning)
+		require.ErrorIs(t, err, storage.ErrRotationNotPaused)
+	})
+
+	t.Run("resume rotation", func(t *testing.T) {
+		resumeQuery := regexp.QuoteMeta(
+			`UPDATE rotations SET paused_at = 0 WHERE slot_id = $1 AND banner_id = $2 AND paused_at <> 0;`,
+		)
+		mock.ExpectExec(resumeQuery).WithArgs(1, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+		require.NoError(t, s.ResumeRotation(1, 2))
+
+		mock.ExpectExec(resumeQuery).WithArgs(1, 2).WillReturnResult(sqlmock.NewResult(0, 0))
+		err = s.ResumeRotation(1, 2)
+		require.ErrorIs(t, err, storage.ErrRotationNotResumed)
+	})
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestStorage_CreateExperiment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	s := Storage{store: sqlxDB}
+
+	experimentQuery := regexp.QuoteMeta(`INSERT INTO experiments (slot_id, started_at) VALUES ($1, $2) RETURNING id;`)
+	armQuery := regexp.QuoteMeta(`INSERT INTO experiment_arms (experiment_id, banner_id, share) VALUES ($1, $2, $3);`)
+	experiment := storage.Experiment{
+		SlotID:    1,
+		StartedAt: 3600,
+		Arms:      []storage.ExperimentArm{{BannerID: 1, Share: 50}, {BannerID: 2, Share: 50}},
+	}
+
+	t.Run("create experiment", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(experimentQuery).WithArgs(1, 3600).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+		mock.ExpectExec(armQuery).WithArgs(5, 1, 50).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(armQuery).WithArgs(5, 2, 50).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		result, err := s.CreateExperiment(experiment)
+		require.NoError(t, err)
+		require.Equal(t, int64(5), result.ID)
+		require.Equal(t, experiment.Arms, result.Arms)
+	})
+
+	t.Run("create experiment of slot with active one", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(experimentQuery).WithArgs(1, 3600).WillReturnError(fmt.Errorf("unique violation"))
+		mock.ExpectRollback()
+		_, err := s.CreateExperiment(experiment)
+		require.ErrorIs(t, err, storage.ErrExperimentNotCreated)
+	})
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestStorage_CreateGroupWithParent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	s := Storage{store: sqlxDB}
+
+	query := regexp.QuoteMeta(`INSERT INTO groups (description, parent_id) SELECT CAST($1 AS text), CAST($2 AS bigint)`)
+
+	t.Run("create group with parent", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs("child", 1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+		group, err := s.CreateGroupWithParent("child", 1)
+		require.NoError(t, err)
+		require.Equal(t, storage.Group{ID: 2, Description: "child", ParentID: 1}, *group)
+	})
+
+	t.Run("create group with unknown parent", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs("child", 3).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		_, err := s.CreateGroupWithParent("child", 3)
+		require.ErrorIs(t, err, storage.ErrGroupParentNotSet)
+	})
+
+	t.Run("failed insert", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs("child", 1).WillReturnError(fmt.Errorf("failed"))
+		_, err := s.CreateGroupWithParent("child", 1)
+		require.ErrorIs(t, err, storage.ErrGroupNotCreated)
+	})
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestStorage_SetGroupParent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	s := Storage{store: sqlxDB}
+
+	query := regexp.QuoteMeta(`UPDATE groups SET parent_id = $1`)
+
+	t.Run("set group parent", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(1, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+		err = s.SetGroupParent(2, 1)
+		require.NoError(t, err)
+	})
+
+	t.Run("set unknown group parent", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(3, 2).WillReturnResult(sqlmock.NewResult(0, 0))
+		err = s.SetGroupParent(2, 3)
+		require.ErrorIs(t, err, storage.ErrGroupParentNotSet)
+	})
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+type recordingQueries struct {
+	methods []string
+}
+
+func (o *recordingQueries) ObserveQuery(method string, duration time.Duration) {
+	o.methods = append(o.methods, method)
+}
+
+func TestStorage_QueryObserver(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	o := &recordingQueries{}
+	s := newStorage(sqlx.NewDb(db, "sqlmock"), WithQueryObserver(o))
+
+	mock.
+		ExpectQuery(regexp.QuoteMeta(`INSERT INTO slots (description) VALUES ($1) RETURNING id;`)).
+		WithArgs("slot").
+		WillReturnRows(mock.NewRows([]string{"id"}).AddRow(1))
+	_, err = s.CreateSlot("slot")
+	require.NoError(t, err)
+
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE groups SET parent_id = $1`)).WillReturnError(fmt.Errorf("failed"))
+	err = s.SetGroupParent(2, 1)
+	require.ErrorIs(t, err, storage.ErrGroupParentNotSet)
+
+	require.Equal(t, []string{"CreateSlot", "SetGroupParent"}, o.methods)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
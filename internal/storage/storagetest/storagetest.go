@@ -0,0 +1,672 @@
+// Package storagetest implements the conformance suite of rotator.Storage.
+// Every storage backend runs the same cases, so all of them behave the same
+// way for the rotator.
+package storagetest
+
+import (
+	"banners-rotator/internal/rotator"
+	"banners-rotator/internal/storage"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// unknownID is an identifier which is never assigned by a storage.
+const unknownID = int64(-1)
+
+// Factory returns an empty storage. It is called once per case, the storage
+// is expected to be released with t.Cleanup.
+type Factory func(t *testing.T) rotator.Storage
+
+// Run runs every case of the suite against the storages of the factory.
+func Run(t *testing.T, newStorage Factory) {
+	t.Helper()
+
+	cases := []struct {
+		name string
+		test func(t *testing.T, s rotator.Storage)
+	}{
+		{"create slot", testCreateSlot},
+		{"create banner", testCreateBanner},
+		{"create group", testCreateGroup},
+		{"create rotation", testCreateRotation},
+		{"delete rotation", testDeleteRotation},
+		{"create view event", testCreateViewEvent},
+		{"create click event", testCreateClickEvent},
+		{"slot banners", testSlotBanners},
+		{"slot views", testSlotViews},
+		{"slot clicks", testSlotClicks},
+		{"slot stats", testSlotStats},
+		{"slot series", testSlotSeries},
+		{"slot model", testSlotModel},
+		{"conversions", testConversions},
+		{"reward model", testRewardModel},
+		{"rotation priors", testRotationPriors},
+		{"banner stats", testBannerStats},
+		{"pruning policy", testPruningPolicy},
+		{"pause rotation", testPauseRotation},
+		{"experiments", testExperiments},
+		{"group parents", testGroupParents},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			c.test(t, newStorage(t))
+		})
+	}
+}
+
+// fixture is a slot with two rotated banners and a group.
+type fixture struct {
+	slot    *storage.Slot
+	banner  *storage.Banner
+	banner2 *storage.Banner
+	group   *storage.Group
+}
+
+func newFixture(t *testing.T, s rotator.Storage) fixture {
+	t.Helper()
+
+	var (
+		f   fixture
+		err error
+	)
+	f.slot, err = s.CreateSlot(uuid.NewString())
+	require.NoError(t, err)
+	f.banner, err = s.CreateBanner(uuid.NewString())
+	require.NoError(t, err)
+	f.banner2, err = s.CreateBanner(uuid.NewString())
+	require.NoError(t, err)
+	f.group, err = s.CreateGroup(uuid.NewString())
+	require.NoError(t, err)
+
+	require.NoError(t, s.CreateRotation(f.slot.ID, f.banner.ID))
+	require.NoError(t, s.CreateRotation(f.slot.ID, f.banner2.ID))
+
+	return f
+}
+
+func testCreateSlot(t *testing.T, s rotator.Storage) {
+	desc := uuid.NewString()
+
+	slot, err := s.CreateSlot(desc)
+	require.NoError(t, err)
+	require.Equal(t, desc, slot.Description)
+	require.Greater(t, slot.ID, int64(0))
+
+	slot2, err := s.CreateSlot(desc)
+	require.NoError(t, err)
+	require.NotEqual(t, slot.ID, slot2.ID)
+}
+
+func testCreateBanner(t *testing.T, s rotator.Storage) {
+	desc := uuid.NewString()
+
+	banner, err := s.CreateBanner(desc)
+	require.NoError(t, err)
+	require.Equal(t, desc, banner.Description)
+	require.Greater(t, banner.ID, int64(0))
+
+	banner2, err := s.CreateBanner(desc)
+	require.NoError(t, err)
+	require.NotEqual(t, banner.ID, banner2.ID)
+}
+
+func testCreateGroup(t *testing.T, s rotator.Storage) {
+	desc := uuid.NewString()
+
+	group, err := s.CreateGroup(desc)
+	require.NoError(t, err)
+	require.Equal(t, desc, group.Description)
+	require.Greater(t, group.ID, int64(0))
+
+	group2, err := s.CreateGroup(desc)
+	require.NoError(t, err)
+	require.NotEqual(t, group.ID, group2.ID)
+}
+
+func testCreateRotation(t *testing.T, s rotator.Storage) {
+	slot, err := s.CreateSlot(uuid.NewString())
+	require.NoError(t, err)
+	banner, err := s.CreateBanner(uuid.NewString())
+	require.NoError(t, err)
+
+	err = s.CreateRotation(slot.ID, banner.ID)
+	require.NoError(t, err)
+
+	banners, err := s.SlotBanners(slot.ID)
+	require.NoError(t, err)
+	require.Equal(t, []storage.Banner{*banner}, *banners)
+
+	err = s.CreateRotation(slot.ID, banner.ID)
+	require.ErrorIs(t, err, storage.ErrRotationExists)
+
+	banners, err = s.SlotBanners(slot.ID)
+	require.NoError(t, err)
+	require.Len(t, *banners, 1)
+
+	err = s.CreateRotation(unknownID, banner.ID)
+	require.ErrorIs(t, err, storage.ErrRotationNotCreated)
+
+	err = s.CreateRotation(slot.ID, unknownID)
+	require.ErrorIs(t, err, storage.ErrRotationNotCreated)
+}
+
+func testDeleteRotation(t *testing.T, s rotator.Storage) {
+	f := newFixture(t, s)
+
+	err := s.DeleteRotation(f.slot.ID, f.banner.ID)
+	require.NoError(t, err)
+
+	banners, err := s.SlotBanners(f.slot.ID)
+	require.NoError(t, err)
+	require.Equal(t, []storage.Banner{*f.banner2}, *banners)
+
+	err = s.DeleteRotation(f.slot.ID, f.banner.ID)
+	require.ErrorIs(t, err, storage.ErrRotationNotDeleted)
+
+	err = s.DeleteRotation(unknownID, f.banner2.ID)
+	require.ErrorIs(t, err, storage.ErrRotationNotDeleted)
+}
+
+func testCreateViewEvent(t *testing.T, s rotator.Storage) {
+	f := newFixture(t, s)
+
+	err := s.CreateViewEvent(f.slot.ID, f.banner.ID, f.group.ID, 1)
+	require.NoError(t, err)
+
+	views, err := s.SlotViews(f.slot.ID)
+	require.NoError(t, err)
+	require.Equal(t, []storage.ViewEvent{
+		{SlotID: f.slot.ID, BannerID: f.banner.ID, GroupID: f.group.ID, Date: 1},
+	}, *views)
+
+	err = s.CreateViewEvent(unknownID, f.banner.ID, f.group.ID, 1)
+	require.ErrorIs(t, err, storage.ErrViewEventNotCreated)
+
+	err = s.CreateViewEvent(f.slot.ID, unknownID, f.group.ID, 1)
+	require.ErrorIs(t, err, storage.ErrViewEventNotCreated)
+
+	err = s.CreateViewEvent(f.slot.ID, f.banner.ID, unknownID, 1)
+	require.ErrorIs(t, err, storage.ErrViewEventNotCreated)
+}
+
+func testCreateClickEvent(t *testing.T, s rotator.Storage) {
+	f := newFixture(t, s)
+
+	err := s.CreateClickEvent(f.slot.ID, f.banner.ID, f.group.ID, 1)
+	require.NoError(t, err)
+
+	clicks, err := s.SlotClicks(f.slot.ID)
+	require.NoError(t, err)
+	require.Equal(t, []storage.ClickEvent{
+		{SlotID: f.slot.ID, BannerID: f.banner.ID, GroupID: f.group.ID, Date: 1},
+	}, *clicks)
+
+	err = s.CreateClickEvent(unknownID, f.banner.ID, f.group.ID, 1)
+	require.ErrorIs(t, err, storage.ErrClickEventNotCreated)
+
+	err = s.CreateClickEvent(f.slot.ID, unknownID, f.group.ID, 1)
+	require.ErrorIs(t, err, storage.ErrClickEventNotCreated)
+
+	err = s.CreateClickEvent(f.slot.ID, f.banner.ID, unknownID, 1)
+	require.ErrorIs(t, err, storage.ErrClickEventNotCreated)
+}
+
+func testSlotBanners(t *testing.T, s rotator.Storage) {
+	f := newFixture(t, s)
+
+	slot2, err := s.CreateSlot(uuid.NewString())
+	require.NoError(t, err)
+	require.NoError(t, s.CreateRotation(slot2.ID, f.banner2.ID))
+
+	banners, err := s.SlotBanners(f.slot.ID)
+	require.NoError(t, err)
+	require.Equal(t, []storage.Banner{*f.banner, *f.banner2}, *banners, "banners are ordered by id")
+
+	banners, err = s.SlotBanners(slot2.ID)
+	require.NoError(t, err)
+	require.Equal(t, []storage.Banner{*f.banner2}, *banners)
+
+	banners, err = s.SlotBanners(unknownID)
+	require.NoError(t, err)
+	require.Empty(t, *banners)
+}
+
+func testSlotViews(t *testing.T, s rotator.Storage) {
+	f := newFixture(t, s)
+
+	slot2, err := s.CreateSlot(uuid.NewString())
+	require.NoError(t, err)
+	group2, err := s.CreateGroup(uuid.NewString())
+	require.NoError(t, err)
+
+	require.NoError(t, s.CreateViewEvent(f.slot.ID, f.banner.ID, f.group.ID, 1))
+	require.NoError(t, s.CreateViewEvent(f.slot.ID, f.banner2.ID, group2.ID, 2))
+	require.NoError(t, s.CreateViewEvent(slot2.ID, f.banner.ID, f.group.ID, 3))
+
+	views, err := s.SlotViews(f.slot.ID)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []storage.ViewEvent{
+		{SlotID: f.slot.ID, BannerID: f.banner.ID, GroupID: f.group.ID, Date: 1},
+		{SlotID: f.slot.ID, BannerID: f.banner2.ID, GroupID: group2.ID, Date: 2},
+	}, *views)
+
+	views, err = s.SlotViews(slot2.ID)
+	require.NoError(t, err)
+	require.Len(t, *views, 1)
+
+	views, err = s.SlotViews(unknownID)
+	require.NoError(t, err)
+	require.Empty(t, *views)
+}
+
+func testSlotClicks(t *testing.T, s rotator.Storage) {
+	f := newFixture(t, s)
+
+	slot2, err := s.CreateSlot(uuid.NewString())
+	require.NoError(t, err)
+	group2, err := s.CreateGroup(uuid.NewString())
+	require.NoError(t, err)
+
+	require.NoError(t, s.CreateClickEvent(f.slot.ID, f.banner.ID, f.group.ID, 1))
+	require.NoError(t, s.CreateClickEvent(f.slot.ID, f.banner2.ID, group2.ID, 2))
+	require.NoError(t, s.CreateClickEvent(slot2.ID, f.banner.ID, f.group.ID, 3))
+
+	clicks, err := s.SlotClicks(f.slot.ID)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []storage.ClickEvent{
+		{SlotID: f.slot.ID, BannerID: f.banner.ID, GroupID: f.group.ID, Date: 1},
+		{SlotID: f.slot.ID, BannerID: f.banner2.ID, GroupID: group2.ID, Date: 2},
+	}, *clicks)
+
+	clicks, err = s.SlotClicks(slot2.ID)
+	require.NoError(t, err)
+	require.Len(t, *clicks, 1)
+
+	clicks, err = s.SlotClicks(unknownID)
+	require.NoError(t, err)
+	require.Empty(t, *clicks)
+}
+
+func testSlotStats(t *testing.T, s rotator.Storage) {
+	f := newFixture(t, s)
+
+	group2, err := s.CreateGroup(uuid.NewString())
+	require.NoError(t, err)
+	slot2, err := s.CreateSlot(uuid.NewString())
+	require.NoError(t, err)
+
+	require.NoError(t, s.CreateViewEvent(f.slot.ID, f.banner.ID, f.group.ID, 10))
+	require.NoError(t, s.CreateViewEvent(f.slot.ID, f.banner.ID, group2.ID, 20))
+	require.NoError(t, s.CreateViewEvent(f.slot.ID, f.banner2.ID, f.group.ID, 30))
+	require.NoError(t, s.CreateViewEvent(f.slot.ID, f.banner2.ID, f.group.ID, 40))
+	require.NoError(t, s.CreateClickEvent(f.slot.ID, f.banner.ID, group2.ID, 20))
+	require.NoError(t, s.CreateViewEvent(slot2.ID, f.banner.ID, f.group.ID, 10))
+
+	stats, err := s.SlotStats(f.slot.ID, 0, 100, false)
+	require.NoError(t, err)
+	require.Equal(t, []storage.BannerStats{
+		{BannerID: f.banner.ID, Views: 2, Clicks: 1},
+		{BannerID: f.banner2.ID, Views: 2},
+	}, *stats)
+
+	stats, err = s.SlotStats(f.slot.ID, 0, 100, true)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []storage.BannerStats{
+		{BannerID: f.banner.ID, GroupID: f.group.ID, Views: 1},
+		{BannerID: f.banner.ID, GroupID: group2.ID, Views: 1, Clicks: 1},
+		{BannerID: f.banner2.ID, GroupID: f.group.ID, Views: 2},
+	}, *stats)
+
+	// from is inclusive, to is exclusive.
+	stats, err = s.SlotStats(f.slot.ID, 20, 40, false)
+	require.NoError(t, err)
+	require.Equal(t, []storage.BannerStats{
+		{BannerID: f.banner.ID, Views: 1, Clicks: 1},
+		{BannerID: f.banner2.ID, Views: 1},
+	}, *stats)
+
+	stats, err = s.SlotStats(unknownID, 0, 100, false)
+	require.NoError(t, err)
+	require.Empty(t, *stats)
+}
+
+func testSlotSeries(t *testing.T, s rotator.Storage) {
+	f := newFixture(t, s)
+
+	group2, err := s.CreateGroup(uuid.NewString())
+	require.NoError(t, err)
+
+	require.NoError(t, s.CreateViewEvent(f.slot.ID, f.banner.ID, f.group.ID, 105))
+	require.NoError(t, s.CreateViewEvent(f.slot.ID, f.banner.ID, f.group.ID, 109))
+	require.NoError(t, s.CreateClickEvent(f.slot.ID, f.banner.ID, f.group.ID, 110))
+	require.NoError(t, s.CreateViewEvent(f.slot.ID, f.banner2.ID, group2.ID, 112))
+	require.NoError(t, s.CreateViewEvent(f.slot.ID, f.banner.ID, f.group.ID, 135))
+
+	filter := storage.SeriesFilter{SlotID: f.slot.ID, From: 100, To: 200, BucketSize: 10, BucketOffset: 5}
+	points, err := s.SlotSeries(filter)
+	require.NoError(t, err)
+	require.Equal(t, []storage.SeriesPoint{
+		{Bucket: 105, Views: 3, Clicks: 1},
+		{Bucket: 135, Views: 1},
+	}, *points)
+
+	filter.BucketOffset = 0
+	filter.BannerID = f.banner.ID
+	points, err = s.SlotSeries(filter)
+	require.NoError(t, err)
+	require.Equal(t, []storage.SeriesPoint{
+		{Bucket: 100, Views: 2},
+		{Bucket: 110, Clicks: 1},
+		{Bucket: 130, Views: 1},
+	}, *points)
+
+	filter.BannerID = 0
+	filter.GroupID = group2.ID
+	filter.To = 112
+	points, err = s.SlotSeries(filter)
+	require.NoError(t, err)
+	require.Empty(t, *points)
+}
+
+func testSlotModel(t *testing.T, s rotator.Storage) {
+	f := newFixture(t, s)
+
+	model, err := s.SlotModel(f.slot.ID)
+	require.NoError(t, err)
+	require.Equal(t, storage.SlotModel{SlotID: f.slot.ID}, *model)
+
+	model.State = []byte("first")
+	require.NoError(t, s.SaveSlotModel(*model))
+
+	saved, err := s.SlotModel(f.slot.ID)
+	require.NoError(t, err)
+	require.Equal(t, storage.SlotModel{SlotID: f.slot.ID, Version: 1, State: []byte("first")}, *saved)
+
+	t.Run("conflict", func(t *testing.T) {
+		err := s.SaveSlotModel(*model)
+		require.ErrorIs(t, err, storage.ErrModelConflict)
+	})
+
+	saved.State = []byte("second")
+	require.NoError(t, s.SaveSlotModel(*saved))
+	err = s.SaveSlotModel(*saved)
+	require.ErrorIs(t, err, storage.ErrModelConflict)
+
+	saved, err = s.SlotModel(f.slot.ID)
+	require.NoError(t, err)
+	require.Equal(t, storage.SlotModel{SlotID: f.slot.ID, Version: 2, State: []byte("second")}, *saved)
+
+	err = s.SaveSlotModel(storage.SlotModel{SlotID: unknownID, State: []byte("state")})
+	require.Error(t, err)
+}
+
+func testConversions(t *testing.T, s rotator.Storage) {
+	f := newFixture(t, s)
+
+	require.NoError(t, s.CreateConversionEvent(f.slot.ID, f.banner.ID, f.group.ID, storage.ConversionTypeConversion, 0, 10))
+	require.NoError(t, s.CreateConversionEvent(f.slot.ID, f.banner2.ID, f.group.ID, storage.ConversionTypePurchase, 9.5, 20))
+
+	err := s.CreateConversionEvent(f.slot.ID, unknownID, f.group.ID, storage.ConversionTypeConversion, 0, 30)
+	require.ErrorIs(t, err, storage.ErrConversionNotCreated)
+
+	conversions, err := s.SlotConversions(f.slot.ID)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []storage.ConversionEvent{
+		{SlotID: f.slot.ID, BannerID: f.banner.ID, GroupID: f.group.ID, Type: storage.ConversionTypeConversion, Date: 10},
+		{SlotID: f.slot.ID, BannerID: f.banner2.ID, GroupID: f.group.ID, Type: storage.ConversionTypePurchase, Value: 9.5, Date: 20},
+	}, *conversions)
+}
+
+func testRewardModel(t *testing.T, s rotator.Storage) {
+	f := newFixture(t, s)
+
+	model, err := s.SlotRewardModel(f.slot.ID)
+	require.NoError(t, err)
+	require.Equal(t, storage.RewardClicks, model)
+
+	require.NoError(t, s.SetSlotRewardModel(f.slot.ID, storage.RewardRevenue))
+	model, err = s.SlotRewardModel(f.slot.ID)
+	require.NoError(t, err)
+	require.Equal(t, storage.RewardRevenue, model)
+
+	err = s.SetSlotRewardModel(unknownID, storage.RewardRevenue)
+	require.ErrorIs(t, err, storage.ErrRewardModelNotSet)
+
+	_, err = s.SlotRewardModel(unknownID)
+	require.Error(t, err)
+}
+
+func testRotationPriors(t *testing.T, s rotator.Storage) {
+	f := newFixture(t, s)
+
+	priors, err := s.SlotPriors(f.slot.ID)
+	require.NoError(t, err)
+	require.Empty(t, *priors)
+
+	prior := storage.Prior{Views: 50, Rewards: 2.5}
+	require.NoError(t, s.SetRotationPrior(f.slot.ID, f.banner2.ID, prior))
+	priors, err = s.SlotPriors(f.slot.ID)
+	require.NoError(t, err)
+	require.Equal(t, []storage.RotationPrior{{BannerID: f.banner2.ID, Prior: prior}}, *priors)
+
+	err = s.SetRotationPrior(f.slot.ID, unknownID, prior)
+	require.ErrorIs(t, err, storage.ErrPriorNotSet)
+
+	require.NoError(t, s.DeleteRotation(f.slot.ID, f.banner2.ID))
+	priors, err = s.SlotPriors(f.slot.ID)
+	require.NoError(t, err)
+	require.Empty(t, *priors)
+
+	require.NoError(t, s.CreateRotationWithPrior(f.slot.ID, f.banner2.ID, prior))
+	priors, err = s.SlotPriors(f.slot.ID)
+	require.NoError(t, err)
+	require.Equal(t, []storage.RotationPrior{{BannerID: f.banner2.ID, Prior: prior}}, *priors)
+
+	err = s.CreateRotationWithPrior(f.slot.ID, f.banner2.ID, storage.Prior{Views: 10})
+	require.ErrorIs(t, err, storage.ErrRotationExists)
+	err = s.CreateRotationWithPrior(f.slot.ID, unknownID, prior)
+	require.ErrorIs(t, err, storage.ErrRotationNotCreated)
+
+	priors, err = s.SlotPriors(f.slot.ID)
+	require.NoError(t, err)
+	require.Equal(t, []storage.RotationPrior{{BannerID: f.banner2.ID, Prior: prior}}, *priors)
+}
+
+func testBannerStats(t *testing.T, s rotator.Storage) {
+	f := newFixture(t, s)
+	slot2, err := s.CreateSlot(uuid.NewString())
+	require.NoError(t, err)
+	require.NoError(t, s.CreateRotation(slot2.ID, f.banner.ID))
+
+	require.NoError(t, s.CreateViewEvent(f.slot.ID, f.banner.ID, f.group.ID, 1))
+	require.NoError(t, s.CreateClickEvent(f.slot.ID, f.banner.ID, f.group.ID, 2))
+	require.NoError(t, s.CreateViewEvent(slot2.ID, f.banner.ID, f.group.ID, 3))
+	require.NoError(t, s.CreateViewEvent(slot2.ID, f.banner.ID, f.group.ID, 4))
+	require.NoError(t, s.CreateClickEvent(slot2.ID, f.banner.ID, f.group.ID, 5))
+
+	stats, err := s.BannerStats(f.banner.ID, f.slot.ID)
+	require.NoError(t, err)
+	require.Equal(t, storage.BannerStats{BannerID: f.banner.ID, Views: 2, Clicks: 1}, *stats)
+
+	stats, err = s.BannerStats(f.banner2.ID, f.slot.ID)
+	require.NoError(t, err)
+	require.Equal(t, storage.BannerStats{BannerID: f.banner2.ID}, *stats)
+}
+
+func testPruningPolicy(t *testing.T, s rotator.Storage) {
+	f := newFixture(t, s)
+
+	policy, err := s.SlotPruningPolicy(f.slot.ID)
+	require.NoError(t, err)
+	require.Equal(t, storage.PruningPolicy{SlotID: f.slot.ID}, *policy)
+
+	expected := storage.PruningPolicy{SlotID: f.slot.ID, Enabled: true, Confidence: 0.95, MinViews: 100}
+	require.NoError(t, s.SetSlotPruningPolicy(expected))
+	policy, err = s.SlotPruningPolicy(f.slot.ID)
+	require.NoError(t, err)
+	require.Equal(t, expected, *policy)
+
+	policies, err := s.PruningPolicies()
+	require.NoError(t, err)
+	require.Contains(t, *policies, expected)
+
+	expected.Enabled = false
+	require.NoError(t, s.SetSlotPruningPolicy(expected))
+	policies, err = s.PruningPolicies()
+	require.NoError(t, err)
+	require.NotContains(t, *policies, expected)
+
+	err = s.SetSlotPruningPolicy(storage.PruningPolicy{SlotID: unknownID, Enabled: true})
+	require.ErrorIs(t, err, storage.ErrPolicyNotSet)
+}
+
+func testPauseRotation(t *testing.T, s rotator.Storage) {
+	f := newFixture(t, s)
+
+	pruning := storage.Pruning{
+		SlotID:        f.slot.ID,
+		BannerID:      f.banner2.ID,
+		LeaderID:      f.banner.ID,
+		Views:         100,
+		Rewards:       1,
+		LeaderViews:   100,
+		LeaderRewards: 20,
+		Probability:   0.99,
+		Date:          10,
+	}
+	require.NoError(t, s.PauseRotation(pruning))
+
+	banners, err := s.SlotBanners(f.slot.ID)
+	require.NoError(t, err)
+	require.Equal(t, []storage.Banner{*f.banner}, *banners)
+
+	err = s.PauseRotation(pruning)
+	require.ErrorIs(t, err, storage.ErrRotationNotPaused)
+
+	prunings, err := s.SlotPrunings(f.slot.ID)
+	require.NoError(t, err)
+	require.Equal(t, []storage.Pruning{pruning}, *prunings)
+
+	require.NoError(t, s.ResumeRotation(f.slot.ID, f.banner2.ID))
+	banners, err = s.SlotBanners(f.slot.ID)
+	require.NoError(t, err)
+	require.Len(t, *banners, 2)
+
+	err = s.ResumeRotation(f.slot.ID, f.banner2.ID)
+	require.ErrorIs(t, err, storage.ErrRotationNotResumed)
+	err = s.ResumeRotation(unknownID, f.banner2.ID)
+	require.ErrorIs(t, err, storage.ErrRotationNotResumed)
+
+	prunings, err = s.SlotPrunings(f.slot.ID)
+	require.NoError(t, err)
+	require.Equal(t, []storage.Pruning{pruning}, *prunings)
+
+	require.NoError(t, s.PauseRotation(pruning))
+	require.NoError(t, s.DeleteRotation(f.slot.ID, f.banner2.ID))
+	require.NoError(t, s.CreateRotation(f.slot.ID, f.banner2.ID))
+	banners, err = s.SlotBanners(f.slot.ID)
+	require.NoError(t, err)
+	require.Len(t, *banners, 2)
+}
+
+func testExperiments(t *testing.T, s rotator.Storage) {
+	f := newFixture(t, s)
+
+	_, err := s.ActiveExperiment(f.slot.ID)
+	require.ErrorIs(t, err, storage.ErrExperimentNotFound)
+
+	expected := storage.Experiment{
+		SlotID:    f.slot.ID,
+		StartedAt: 10,
+		Arms: []storage.ExperimentArm{
+			{BannerID: f.banner.ID, Share: 70},
+			{BannerID: f.banner2.ID, Share: 30},
+		},
+	}
+	experiment, err := s.CreateExperiment(expected)
+	require.NoError(t, err)
+	require.NotZero(t, experiment.ID)
+	expected.ID = experiment.ID
+	require.Equal(t, expected, *experiment)
+
+	active, err := s.ActiveExperiment(f.slot.ID)
+	require.NoError(t, err)
+	require.Equal(t, expected, *active)
+
+	_, err = s.CreateExperiment(expected)
+	require.ErrorIs(t, err, storage.ErrExperimentNotCreated)
+
+	require.NoError(t, s.EndExperiment(experiment.ID, 20))
+	err = s.EndExperiment(experiment.ID, 30)
+	require.ErrorIs(t, err, storage.ErrExperimentNotEnded)
+
+	_, err = s.ActiveExperiment(f.slot.ID)
+	require.ErrorIs(t, err, storage.ErrExperimentNotFound)
+
+	ended, err := s.Experiment(experiment.ID)
+	require.NoError(t, err)
+	expected.EndedAt = 20
+	require.Equal(t, expected, *ended)
+
+	next, err := s.CreateExperiment(storage.Experiment{SlotID: f.slot.ID, StartedAt: 30, Arms: expected.Arms})
+	require.NoError(t, err)
+	require.NotEqual(t, experiment.ID, next.ID)
+
+	_, err = s.Experiment(unknownID)
+	require.ErrorIs(t, err, storage.ErrExperimentNotFound)
+}
+
+func testGroupParents(t *testing.T, s rotator.Storage) {
+	parent, err := s.CreateGroup(uuid.NewString())
+	require.NoError(t, err)
+	child, err := s.CreateGroup(uuid.NewString())
+	require.NoError(t, err)
+	require.Zero(t, child.ParentID)
+
+	require.NoError(t, s.SetGroupParent(child.ID, parent.ID))
+	group, err := s.Group(child.ID)
+	require.NoError(t, err)
+	require.Equal(t, storage.Group{ID: child.ID, Description: child.Description, ParentID: parent.ID}, *group)
+
+	groups, err := s.Groups()
+	require.NoError(t, err)
+	require.Contains(t, *groups, *parent)
+	require.Contains(t, *groups, *group)
+
+	err = s.SetGroupParent(child.ID, unknownID)
+	require.ErrorIs(t, err, storage.ErrGroupParentNotSet)
+	err = s.SetGroupParent(unknownID, parent.ID)
+	require.ErrorIs(t, err, storage.ErrGroupParentNotSet)
+
+	require.NoError(t, s.SetGroupParent(child.ID, 0))
+	group, err = s.Group(child.ID)
+	require.NoError(t, err)
+	require.Zero(t, group.ParentID)
+
+	_, err = s.Group(unknownID)
+	require.ErrorIs(t, err, storage.ErrGroupNotFound)
+
+	created, err := s.CreateGroupWithParent(uuid.NewString(), parent.ID)
+	require.NoError(t, err)
+	group, err = s.Group(created.ID)
+	require.NoError(t, err)
+	require.Equal(t, *created, *group)
+	require.Equal(t, parent.ID, group.ParentID)
+
+	created, err = s.CreateGroupWithParent(uuid.NewString(), 0)
+	require.NoError(t, err)
+	require.Zero(t, created.ParentID)
+
+	groups, err = s.Groups()
+	require.NoError(t, err)
+	count := len(*groups)
+	_, err = s.CreateGroupWithParent(uuid.NewString(), unknownID)
+	require.ErrorIs(t, err, storage.ErrGroupParentNotSet)
+	groups, err = s.Groups()
+	require.NoError(t, err)
+	require.Len(t, *groups, count)
+}
@@ -0,0 +1,11 @@
+DROP TABLE clicks;
+
+DROP TABLE views;
+
+DROP TABLE rotations;
+
+DROP TABLE groups;
+
+DROP TABLE banners;
+
+DROP TABLE slots;
@@ -1,5 +1,3 @@
-BEGIN;
-
 CREATE TABLE slots
 (
     id          serial NOT NULL,
@@ -84,5 +82,3 @@ ALTER TABLE clicks
 ALTER TABLE clicks
     ADD CONSTRAINT fk_clicks_groups FOREIGN KEY (group_id)
         REFERENCES groups (id);
-
-END;
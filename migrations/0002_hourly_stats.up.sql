@@ -0,0 +1,17 @@
+CREATE TABLE hourly_stats
+(
+    slot_id   bigint NOT NULL,
+    banner_id bigint NOT NULL,
+    group_id  bigint NOT NULL,
+    hour      bigint NOT NULL,
+    views     bigint NOT NULL DEFAULT 0,
+    clicks    bigint NOT NULL DEFAULT 0,
+    CONSTRAINT "hourly_stats_pk" PRIMARY KEY (slot_id, banner_id, group_id, hour)
+);
+
+CREATE TABLE processed_messages
+(
+    id         text        NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    CONSTRAINT "processed_messages_pk" PRIMARY KEY (id)
+);
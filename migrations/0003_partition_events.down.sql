@@ -0,0 +1,62 @@
+ALTER TABLE views
+    RENAME TO views_partitioned;
+
+ALTER TABLE clicks
+    RENAME TO clicks_partitioned;
+
+CREATE TABLE views
+(
+    slot_id   bigint NOT NULL,
+    banner_id bigint NOT NULL,
+    group_id  bigint NOT NULL,
+    date      bigint NOT NULL
+);
+
+CREATE TABLE clicks
+(
+    slot_id   bigint NOT NULL,
+    banner_id bigint NOT NULL,
+    group_id  bigint NOT NULL,
+    date      bigint NOT NULL
+);
+
+INSERT INTO views (slot_id, banner_id, group_id, date)
+SELECT slot_id, banner_id, group_id, date
+FROM views_partitioned;
+
+INSERT INTO clicks (slot_id, banner_id, group_id, date)
+SELECT slot_id, banner_id, group_id, date
+FROM clicks_partitioned;
+
+DROP TABLE views_partitioned;
+
+DROP TABLE clicks_partitioned;
+
+ALTER TABLE views
+    ADD CONSTRAINT fk_views_slots FOREIGN KEY (slot_id)
+        REFERENCES slots (id);
+
+
+ALTER TABLE views
+    ADD CONSTRAINT fk_views_banners FOREIGN KEY (banner_id)
+        REFERENCES banners (id);
+
+
+ALTER TABLE views
+    ADD CONSTRAINT fk_views_groups FOREIGN KEY (group_id)
+        REFERENCES groups (id);
+
+
+ALTER TABLE clicks
+    ADD CONSTRAINT fk_clicks_slots FOREIGN KEY (slot_id)
+        REFERENCES slots (id);
+
+
+ALTER TABLE clicks
+    ADD CONSTRAINT fk_clicks_banners FOREIGN KEY (banner_id)
+        REFERENCES banners (id);
+
+
+ALTER TABLE clicks
+    ADD CONSTRAINT fk_clicks_groups FOREIGN KEY (group_id)
+        REFERENCES groups (id);
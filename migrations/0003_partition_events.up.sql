@@ -0,0 +1,96 @@
+-- views and clicks are partitioned by date, the partitions are created and
+-- dropped by the rotator according to the storage.partitions config.
+-- Existing events are moved into monthly partitions.
+ALTER TABLE views
+    RENAME TO views_old;
+
+ALTER TABLE clicks
+    RENAME TO clicks_old;
+
+CREATE TABLE views
+(
+    slot_id   bigint NOT NULL,
+    banner_id bigint NOT NULL,
+    group_id  bigint NOT NULL,
+    date      bigint NOT NULL
+) PARTITION BY RANGE (date);
+
+CREATE TABLE views_default PARTITION OF views DEFAULT;
+
+CREATE TABLE clicks
+(
+    slot_id   bigint NOT NULL,
+    banner_id bigint NOT NULL,
+    group_id  bigint NOT NULL,
+    date      bigint NOT NULL
+) PARTITION BY RANGE (date);
+
+CREATE TABLE clicks_default PARTITION OF clicks DEFAULT;
+
+DO
+$$
+    DECLARE
+        t text;
+        m timestamp;
+    BEGIN
+        FOREACH t IN ARRAY ARRAY ['views', 'clicks']
+            LOOP
+                FOR m IN EXECUTE format(
+                        'SELECT DISTINCT date_trunc(''month'', to_timestamp(date) AT TIME ZONE ''UTC'') FROM %I',
+                        t || '_old')
+                    LOOP
+                        EXECUTE format(
+                                'CREATE TABLE %I PARTITION OF %I FOR VALUES FROM (%s) TO (%s)',
+                                t || '_p' || to_char(m, 'YYYYMM'),
+                                t,
+                                extract(EPOCH FROM m)::bigint,
+                                extract(EPOCH FROM m + INTERVAL '1 month')::bigint);
+                    END LOOP;
+            END LOOP;
+    END
+$$;
+
+INSERT INTO views (slot_id, banner_id, group_id, date)
+SELECT slot_id, banner_id, group_id, date
+FROM views_old;
+
+INSERT INTO clicks (slot_id, banner_id, group_id, date)
+SELECT slot_id, banner_id, group_id, date
+FROM clicks_old;
+
+DROP TABLE views_old;
+
+DROP TABLE clicks_old;
+
+CREATE INDEX views_slot_group_banner_idx ON views (slot_id, group_id, banner_id);
+
+CREATE INDEX clicks_slot_group_banner_idx ON clicks (slot_id, group_id, banner_id);
+
+ALTER TABLE views
+    ADD CONSTRAINT fk_views_slots FOREIGN KEY (slot_id)
+        REFERENCES slots (id);
+
+
+ALTER TABLE views
+    ADD CONSTRAINT fk_views_banners FOREIGN KEY (banner_id)
+        REFERENCES banners (id);
+
+
+ALTER TABLE views
+    ADD CONSTRAINT fk_views_groups FOREIGN KEY (group_id)
+        REFERENCES groups (id);
+
+
+ALTER TABLE clicks
+    ADD CONSTRAINT fk_clicks_slots FOREIGN KEY (slot_id)
+        REFERENCES slots (id);
+
+
+ALTER TABLE clicks
+    ADD CONSTRAINT fk_clicks_banners FOREIGN KEY (banner_id)
+        REFERENCES banners (id);
+
+
+ALTER TABLE clicks
+    ADD CONSTRAINT fk_clicks_groups FOREIGN KEY (group_id)
+        REFERENCES groups (id);
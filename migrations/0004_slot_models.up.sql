@@ -0,0 +1,7 @@
+CREATE TABLE slot_models
+(
+    slot_id bigint NOT NULL REFERENCES slots (id),
+    version bigint NOT NULL,
+    state   bytea  NOT NULL,
+    CONSTRAINT "slot_models_pk" PRIMARY KEY (slot_id)
+);
@@ -0,0 +1,13 @@
+ALTER TABLE slots ADD COLUMN reward_model text NOT NULL DEFAULT 'clicks';
+
+CREATE TABLE conversions
+(
+    slot_id   bigint           NOT NULL REFERENCES slots (id),
+    banner_id bigint           NOT NULL REFERENCES banners (id),
+    group_id  bigint           NOT NULL REFERENCES groups (id),
+    type      text             NOT NULL,
+    value     double precision NOT NULL DEFAULT 0,
+    date      bigint           NOT NULL
+);
+
+CREATE INDEX conversions_slot_banner_idx ON conversions (slot_id, banner_id);
@@ -0,0 +1,2 @@
+ALTER TABLE rotations ADD COLUMN prior_views bigint NOT NULL DEFAULT 0;
+ALTER TABLE rotations ADD COLUMN prior_rewards double precision NOT NULL DEFAULT 0;
@@ -0,0 +1,5 @@
+DROP TABLE prunings;
+
+DROP TABLE pruning_policies;
+
+ALTER TABLE rotations DROP COLUMN paused_at;
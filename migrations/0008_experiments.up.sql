@@ -0,0 +1,18 @@
+CREATE TABLE experiments
+(
+    id         bigserial NOT NULL,
+    slot_id    bigint    NOT NULL REFERENCES slots (id),
+    started_at bigint    NOT NULL,
+    ended_at   bigint    NOT NULL DEFAULT 0,
+    CONSTRAINT "experiments_pk" PRIMARY KEY (id)
+);
+
+CREATE UNIQUE INDEX experiments_active_slot_idx ON experiments (slot_id) WHERE ended_at = 0;
+
+CREATE TABLE experiment_arms
+(
+    experiment_id bigint NOT NULL REFERENCES experiments (id),
+    banner_id     bigint NOT NULL REFERENCES banners (id),
+    share         bigint NOT NULL,
+    CONSTRAINT "experiment_arms_pk" PRIMARY KEY (experiment_id, banner_id)
+);
@@ -0,0 +1 @@
+ALTER TABLE groups ADD COLUMN parent_id bigint NOT NULL DEFAULT 0;
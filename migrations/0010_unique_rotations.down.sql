@@ -0,0 +1 @@
+ALTER TABLE rotations DROP CONSTRAINT "rotations_pk";
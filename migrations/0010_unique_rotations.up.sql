@@ -0,0 +1,11 @@
+-- a banner is rotated in a slot once, the duplicates inserted before the
+-- constraint are removed.
+DELETE
+FROM rotations a
+    USING rotations b
+WHERE a.slot_id = b.slot_id
+  AND a.banner_id = b.banner_id
+  AND a.ctid < b.ctid;
+
+ALTER TABLE rotations
+    ADD CONSTRAINT "rotations_pk" PRIMARY KEY (slot_id, banner_id);
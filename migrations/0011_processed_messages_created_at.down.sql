@@ -0,0 +1 @@
+DROP INDEX processed_messages_created_at_idx;
@@ -0,0 +1,27 @@
+// Package migrations embeds the numbered schema migrations of the rotator.
+// Every version has a <version>_<name>.up.sql and a <version>_<name>.down.sql file.
+// The migrations of the SQLite storage live in the sqlite directory.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed *.sql
+var FS embed.FS
+
+//go:embed sqlite/*.sql
+var sqliteFS embed.FS
+
+// SQLiteFS holds the SQLite migrations at its root, in the layout of FS.
+var SQLiteFS = mustSub(sqliteFS, "sqlite")
+
+func mustSub(fsys fs.FS, dir string) fs.FS {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+
+	return sub
+}
@@ -0,0 +1,8 @@
+DROP TABLE processed_messages;
+DROP TABLE hourly_stats;
+DROP TABLE clicks;
+DROP TABLE views;
+DROP TABLE rotations;
+DROP TABLE groups;
+DROP TABLE banners;
+DROP TABLE slots;
@@ -0,0 +1,60 @@
+CREATE TABLE slots
+(
+    id          integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+    description text    NOT NULL DEFAULT '""'
+);
+
+CREATE TABLE banners
+(
+    id          integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+    description text    NOT NULL DEFAULT '""'
+);
+
+CREATE TABLE groups
+(
+    id          integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+    description text    NOT NULL DEFAULT '""'
+);
+
+CREATE TABLE rotations
+(
+    slot_id   bigint NOT NULL REFERENCES slots (id),
+    banner_id bigint NOT NULL REFERENCES banners (id)
+);
+
+CREATE TABLE views
+(
+    slot_id   bigint NOT NULL REFERENCES slots (id),
+    banner_id bigint NOT NULL REFERENCES banners (id),
+    group_id  bigint NOT NULL REFERENCES groups (id),
+    date      bigint NOT NULL
+);
+
+CREATE INDEX views_slot_group_banner_idx ON views (slot_id, group_id, banner_id);
+
+CREATE TABLE clicks
+(
+    slot_id   bigint NOT NULL REFERENCES slots (id),
+    banner_id bigint NOT NULL REFERENCES banners (id),
+    group_id  bigint NOT NULL REFERENCES groups (id),
+    date      bigint NOT NULL
+);
+
+CREATE INDEX clicks_slot_group_banner_idx ON clicks (slot_id, group_id, banner_id);
+
+CREATE TABLE hourly_stats
+(
+    slot_id   bigint NOT NULL,
+    banner_id bigint NOT NULL,
+    group_id  bigint NOT NULL,
+    hour      bigint NOT NULL,
+    views     bigint NOT NULL DEFAULT 0,
+    clicks    bigint NOT NULL DEFAULT 0,
+    PRIMARY KEY (slot_id, banner_id, group_id, hour)
+);
+
+CREATE TABLE processed_messages
+(
+    id         text      NOT NULL PRIMARY KEY,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
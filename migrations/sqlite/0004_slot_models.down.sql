@@ -0,0 +1 @@
+DROP TABLE slot_models;
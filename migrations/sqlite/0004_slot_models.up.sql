@@ -0,0 +1,6 @@
+CREATE TABLE slot_models
+(
+    slot_id bigint NOT NULL PRIMARY KEY REFERENCES slots (id),
+    version bigint NOT NULL,
+    state   blob   NOT NULL
+);
@@ -0,0 +1,3 @@
+DROP TABLE conversions;
+
+ALTER TABLE slots DROP COLUMN reward_model;
@@ -0,0 +1,2 @@
+ALTER TABLE rotations DROP COLUMN prior_rewards;
+ALTER TABLE rotations DROP COLUMN prior_views;
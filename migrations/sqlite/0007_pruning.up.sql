@@ -0,0 +1,25 @@
+ALTER TABLE rotations ADD COLUMN paused_at bigint NOT NULL DEFAULT 0;
+
+CREATE TABLE pruning_policies
+(
+    slot_id    bigint           NOT NULL REFERENCES slots (id),
+    enabled    boolean          NOT NULL DEFAULT false,
+    confidence real             NOT NULL,
+    min_views  bigint           NOT NULL,
+    CONSTRAINT "pruning_policies_pk" PRIMARY KEY (slot_id)
+);
+
+CREATE TABLE prunings
+(
+    slot_id        bigint           NOT NULL REFERENCES slots (id),
+    banner_id      bigint           NOT NULL REFERENCES banners (id),
+    leader_id      bigint           NOT NULL REFERENCES banners (id),
+    views          bigint           NOT NULL,
+    rewards        real             NOT NULL,
+    leader_views   bigint           NOT NULL,
+    leader_rewards real             NOT NULL,
+    probability    real             NOT NULL,
+    date           bigint           NOT NULL
+);
+
+CREATE INDEX prunings_slot_date_idx ON prunings (slot_id, date);
@@ -0,0 +1,3 @@
+DROP TABLE experiment_arms;
+
+DROP TABLE experiments;
@@ -0,0 +1,17 @@
+CREATE TABLE experiments
+(
+    id         integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+    slot_id    bigint  NOT NULL REFERENCES slots (id),
+    started_at bigint  NOT NULL,
+    ended_at   bigint  NOT NULL DEFAULT 0
+);
+
+CREATE UNIQUE INDEX experiments_active_slot_idx ON experiments (slot_id) WHERE ended_at = 0;
+
+CREATE TABLE experiment_arms
+(
+    experiment_id bigint NOT NULL REFERENCES experiments (id),
+    banner_id     bigint NOT NULL REFERENCES banners (id),
+    share         bigint NOT NULL,
+    PRIMARY KEY (experiment_id, banner_id)
+);
@@ -0,0 +1 @@
+DROP INDEX rotations_slot_banner_idx;
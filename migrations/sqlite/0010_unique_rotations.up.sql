@@ -0,0 +1,7 @@
+-- a banner is rotated in a slot once, the duplicates inserted before the
+-- index are removed.
+DELETE
+FROM rotations
+WHERE rowid NOT IN (SELECT max(rowid) FROM rotations GROUP BY slot_id, banner_id);
+
+CREATE UNIQUE INDEX rotations_slot_banner_idx ON rotations (slot_id, banner_id);
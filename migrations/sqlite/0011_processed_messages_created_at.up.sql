@@ -0,0 +1 @@
+CREATE INDEX processed_messages_created_at_idx ON processed_messages (created_at);
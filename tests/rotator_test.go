@@ -19,18 +19,23 @@ func TestRotator_RMQ(t *testing.T) {
 		conn, err := amqp.Dial(getRMQConnectionString())
 		require.NoError(t, err)
 
-		p := rmq.NewRMQProducer("rotator-test", conn)
-		err = p.Connect()
-		require.NoError(t, err)
-
-		err = p.Publish(rmq.QMessage{
-			Type:     "view",
-			SlotID:   1,
-			BannerID: 1,
-			GroupID:  1,
-			Date:     time.Now().Unix(),
-		})
-		require.NoError(t, err)
+		for _, name := range []string{rmq.EncodingJSON, rmq.EncodingProtobuf} {
+			encoding, err := rmq.NewEncoding(name)
+			require.NoError(t, err)
+
+			p := rmq.NewRMQProducer("rotator-test", "rotator-test", conn, encoding)
+			err = p.Connect()
+			require.NoError(t, err)
+
+			err = p.Publish(rmq.QMessage{
+				Type:      rmq.EventView,
+				SlotID:    1,
+				BannerID:  1,
+				GroupID:   1,
+				Timestamp: time.Now().UnixMilli(),
+			})
+			require.NoError(t, err)
+		}
 	})
 }
 